@@ -1,42 +1,356 @@
 package main
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
 
+	"go-clean-code/internal/auth"
 	"go-clean-code/internal/handler"
+	"go-clean-code/internal/health"
+	"go-clean-code/internal/metrics"
+	"go-clean-code/internal/outbox"
+	"go-clean-code/internal/ratelimit"
 	"go-clean-code/internal/repository"
 	"go-clean-code/internal/usecase"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
 )
 
+// tracerShutdownTimeout bounds how long Close waits for buffered spans to be exported
+const tracerShutdownTimeout = 5 * time.Second
+
 type Container struct {
+	DB             *sql.DB
 	UserRepository repository.UserRepositoryInterface
 	UserUsecase    usecase.UserUsecaseInterface
 	UserHandler    *handler.UserHandler
+	// AuthHandler is nil when no signing keys are configured, APIKeyHandler
+	// when API keys are disabled, and Authenticate when both are
+	AuthHandler   *handler.AuthHandler
+	APIKeyHandler *handler.APIKeyHandler
+	Authenticate  mux.MiddlewareFunc
+	// RateLimit is nil when no rate limits are configured
+	RateLimit mux.MiddlewareFunc
+	// LimitAuthFailures is nil when failed authentications are not limited
+	LimitAuthFailures mux.MiddlewareFunc
+	// Metrics is nil when metrics are disabled
+	Metrics *metrics.Metrics
+	// Health runs the readiness checks; subsystems may register their own
+	Health        *health.Checker
+	HealthHandler *handler.HealthHandler
+	// OutboxRelay is nil when no outbox publisher is configured
+	OutboxRelay *outbox.Relay
+
+	publisherCloser io.Closer
+	tracerProvider  *sdktrace.TracerProvider
 }
 
-func NewContainer() *Container {
-	config := NewConfig()
+// userStore is implemented by every repository the container can build
+type userStore interface {
+	repository.UserRepositoryInterface
+	repository.OutboxRepositoryInterface
+	repository.RefreshTokenRepositoryInterface
+	repository.APIKeyRepositoryInterface
+}
 
-	// Initialize PostgreSQL connection
-	db, err := ConnectDatabase(&config.Database)
-	if err != nil {
-		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+func NewContainer(config *Config) *Container {
+	var (
+		db        *sql.DB
+		userRepo  userStore
+		txManager repository.TransactionManagerInterface
+		collector *metrics.Metrics
+		checker   = health.New(health.WithCheckTimeout(config.Health.CheckTimeout))
+	)
+	if config.Metrics.Enabled {
+		var err error
+		if collector, err = newMetrics(&config.Metrics); err != nil {
+			fatal("Invalid metrics configuration", "error", err)
+		}
 	}
 
-	// Run migrations
-	if err := RunMigrations(db, "./migrations"); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Events nothing relays would only pile up in the outbox
+	var repoOpts []repository.RepositoryOption
+	if config.Outbox.Publisher == "" {
+		repoOpts = append(repoOpts, repository.WithoutEvents())
 	}
 
-	userRepo := repository.NewUserRepository(db)
-	log.Println("Using PostgreSQL database")
+	switch config.Database.Driver {
+	case DriverPostgres, DriverSQLite:
+		// Initialize database connection
+		var err error
+		db, err = ConnectDatabase(&config.Database)
+		if err != nil {
+			fatal("Failed to connect to database", "driver", config.Database.Driver, "error", err)
+		}
+
+		// Run migrations
+		if err := RunMigrations(db, &config.Database, "./migrations"); err != nil {
+			fatal("Failed to run migrations", "error", err)
+		}
+
+		isolation, err := config.Database.TxIsolation()
+		if err != nil {
+			fatal("Invalid database configuration", "error", err)
+		}
+		txManager = repository.NewSQLTransactionManager(db,
+			repository.WithIsolationLevel(isolation),
+			repository.WithMaxRetries(config.Database.TxMaxRetries),
+		)
+
+		checker.Register("database", health.PingCheck(db))
+		checker.Register("migrations", health.MigrationCheck(db, config.Database.MigrationsTable()))
 
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	userHandler := handler.NewUserHandler(userUsecase)
+		if collector != nil {
+			if err := collector.RegisterDB(db, config.Database.Driver); err != nil {
+				fatal("Failed to register database metrics", "error", err)
+			}
+		}
 
-	return &Container{
+		if config.Database.Driver == DriverSQLite {
+			userRepo = repository.NewUserSQLiteRepository(db, repoOpts...)
+			slog.Info("Using SQLite database", "path", config.Database.Path)
+		} else {
+			userRepo = repository.NewUserRepository(db, repoOpts...)
+			slog.Info("Using PostgreSQL database", "host", config.Database.Host, "database", config.Database.DBName)
+		}
+	case DriverMemory:
+		userRepo = repository.NewUserMemoryRepository(repoOpts...)
+		txManager = repository.NewMemoryTransactionManager()
+		slog.Info("Using in-memory database")
+	default:
+		fatal("Unsupported database driver", "driver", config.Database.Driver)
+	}
+
+	// Traced even without an exporter, so logs carry the caller's trace ID
+	var userUsecase usecase.UserUsecaseInterface = usecase.NewTracedUserUsecase(usecase.NewUserUsecase(userRepo,
+		usecase.WithMaxListLimit(config.Pagination.MaxLimit),
+		usecase.WithMaxImportRows(config.Import.MaxRows),
+		usecase.WithTransactionManager(txManager),
+	))
+	if collector != nil {
+		userUsecase = collector.InstrumentUserUsecase(userUsecase)
+	}
+	userHandler := handler.NewUserHandler(userUsecase, handler.WithRequireIfMatch(config.Concurrency.RequireIfMatch))
+
+	container := &Container{
+		DB:             db,
 		UserRepository: userRepo,
 		UserUsecase:    userUsecase,
 		UserHandler:    userHandler,
+		Metrics:        collector,
+		Health:         checker,
+		HealthHandler:  handler.NewHealthHandler(checker),
+	}
+
+	if config.Tracing.Exporter != ExporterNone {
+		provider, err := newTracerProvider(&config.Tracing)
+		if err != nil {
+			fatal("Invalid tracing configuration", "error", err)
+		}
+		otel.SetTracerProvider(provider)
+		container.tracerProvider = provider
+		slog.Info("Tracing enabled", "exporter", config.Tracing.Exporter)
+	}
+
+	var (
+		verifier handler.AccessTokenVerifier
+		apiKeys  handler.APIKeyAuthenticator
+	)
+	if config.Auth.SigningKeys != "" {
+		tokens, err := newTokenService(&config.Auth)
+		if err != nil {
+			fatal("Invalid auth configuration", "error", err)
+		}
+		authUsecase := usecase.NewAuthUsecase(userRepo, userRepo, tokens,
+			usecase.WithRefreshTokenTTL(config.Auth.RefreshTokenTTL),
+			usecase.WithAuthTransactionManager(txManager),
+		)
+		container.AuthHandler = handler.NewAuthHandler(authUsecase)
+		verifier = tokens
+		slog.Info("Authentication enabled", "active_key_id", tokens.ActiveKeyID())
+	}
+	if config.Auth.APIKeys {
+		apiKeyUsecase := usecase.NewAPIKeyUsecase(userRepo)
+		container.APIKeyHandler = handler.NewAPIKeyHandler(apiKeyUsecase)
+		apiKeys = apiKeyUsecase
+		slog.Info("API key authentication enabled")
+	}
+	if verifier != nil || apiKeys != nil {
+		container.Authenticate = handler.Authenticate(verifier, apiKeys)
+	}
+
+	if config.RateLimit.Default != "" || config.RateLimit.Routes != "" {
+		limiter, err := newRateLimiter(&config.RateLimit)
+		if err != nil {
+			fatal("Invalid rate limit configuration", "error", err)
+		}
+		container.RateLimit = handler.RateLimit(limiter, handler.WithTrustForwardedFor(config.RateLimit.TrustForwardedFor))
+		slog.Info("Rate limiting enabled")
+	}
+	if config.RateLimit.AuthFailures != "" {
+		limit, err := ratelimit.ParseLimit(config.RateLimit.AuthFailures)
+		if err != nil {
+			fatal("Invalid rate limit configuration", "error", err)
+		}
+		limiter := ratelimit.NewLimiter(
+			ratelimit.WithStore(ratelimit.NewMemoryStore(ratelimit.WithSweepInterval(config.RateLimit.SweepInterval))),
+			ratelimit.WithDefaultLimit(limit),
+		)
+		container.LimitAuthFailures = handler.LimitAuthFailures(limiter, handler.WithTrustForwardedFor(config.RateLimit.TrustForwardedFor))
+	}
+
+	if config.Outbox.Publisher != "" {
+		publisher, closer, err := newPublisher(&config.Outbox)
+		if err != nil {
+			fatal("Failed to create outbox publisher", "error", err)
+		}
+		container.publisherCloser = closer
+		container.OutboxRelay = outbox.NewRelay(userRepo, publisher,
+			outbox.WithPollInterval(config.Outbox.PollInterval),
+			outbox.WithBatchSize(config.Outbox.BatchSize),
+			outbox.WithBackoff(config.Outbox.BaseBackoff, config.Outbox.MaxBackoff),
+			outbox.WithLease(config.Outbox.Lease),
+			outbox.WithRetention(config.Outbox.Retention),
+		)
+		slog.Info("Relaying user events", "publisher", config.Outbox.Publisher)
+	}
+
+	return container
+}
+
+// newPublisher builds the configured publisher and, for files, its closer
+func newPublisher(config *OutboxConfig) (outbox.Publisher, io.Closer, error) {
+	switch config.Publisher {
+	case PublisherStdout:
+		return outbox.NewStdoutPublisher(), nil, nil
+	case PublisherFile:
+		publisher, err := outbox.NewFilePublisher(config.FilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return publisher, publisher, nil
+	case PublisherHTTP:
+		if config.HTTPURL == "" {
+			return nil, nil, fmt.Errorf("OUTBOX_HTTP_URL is required for the http publisher")
+		}
+		return outbox.NewHTTPPublisher(config.HTTPURL, nil), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported outbox publisher: %s", config.Publisher)
+	}
+}
+
+// newTracerProvider builds a provider sending spans to the configured exporter
+func newTracerProvider(config *TracingConfig) (*sdktrace.TracerProvider, error) {
+	if config.SampleRatio < 0 || config.SampleRatio > 1 {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", config.SampleRatio)
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		err      error
+	)
+	switch config.Exporter {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New()
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(context.Background())
+	default:
+		return nil, fmt.Errorf("unsupported span exporter: %s", config.Exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(config.ServiceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	), nil
+}
+
+// newMetrics builds the metrics collector from the configured names and buckets
+func newMetrics(config *MetricsConfig) (*metrics.Metrics, error) {
+	buckets, err := metrics.ParseBuckets(config.HTTPBuckets)
+	if err != nil {
+		return nil, err
+	}
+	return metrics.New(
+		metrics.WithNamespace(config.Namespace),
+		metrics.WithHTTPBuckets(buckets),
+	), nil
+}
+
+// newRateLimiter builds an in-memory limiter from the configured limits
+func newRateLimiter(config *RateLimitConfig) (*ratelimit.Limiter, error) {
+	var defaultLimit ratelimit.Limit
+	if config.Default != "" {
+		var err error
+		if defaultLimit, err = ratelimit.ParseLimit(config.Default); err != nil {
+			return nil, err
+		}
+	}
+	routes, err := ratelimit.ParseRouteLimits(config.Routes)
+	if err != nil {
+		return nil, err
+	}
+
+	return ratelimit.NewLimiter(
+		ratelimit.WithStore(ratelimit.NewMemoryStore(ratelimit.WithSweepInterval(config.SweepInterval))),
+		ratelimit.WithDefaultLimit(defaultLimit),
+		ratelimit.WithRouteLimits(routes),
+	), nil
+}
+
+// newTokenService builds the access token service from the configured keys
+func newTokenService(config *AuthConfig) (*auth.TokenService, error) {
+	keys, err := auth.ParseKeys(config.SigningKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	activeKID := config.ActiveKeyID
+	if activeKID == "" && len(keys) == 1 {
+		for kid := range keys {
+			activeKID = kid
+		}
+	}
+	keySet, err := auth.NewKeySet(activeKID, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewTokenService(keySet,
+		auth.WithIssuer(config.Issuer),
+		auth.WithAccessTokenTTL(config.AccessTokenTTL),
+	), nil
+}
+
+// Close releases resources held by the container
+func (c *Container) Close() error {
+	if c.tracerProvider != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), tracerShutdownTimeout)
+		defer cancel()
+		if err := c.tracerProvider.Shutdown(ctx); err != nil {
+			slog.Error("Failed to flush spans", "error", err)
+		}
+	}
+	if c.publisherCloser != nil {
+		if err := c.publisherCloser.Close(); err != nil {
+			slog.Error("Failed to close outbox publisher", "error", err)
+		}
+	}
+	if c.DB != nil {
+		return c.DB.Close()
 	}
+	return nil
 }
@@ -1,18 +1,112 @@
 package main
 
 import (
-	"log"
-	"net/http"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go-clean-code/internal/logging"
 )
 
 func main() {
-	config := NewConfig()
-	container := NewContainer()
+	opts, err := ParseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		// The flag package has already printed the error and usage
+		os.Exit(2)
+	}
+
+	// An invalid configuration is still printed, so it can be debugged
+	config, err := LoadConfig(opts, os.LookupEnv)
+	if opts.PrintConfig {
+		if err := config.Print(os.Stdout); err != nil {
+			fatal("Failed to print configuration", "error", err)
+		}
+	}
+	if err != nil {
+		fatalConfig(err)
+	}
+	if opts.PrintConfig {
+		return
+	}
+
+	// Logs go to stderr so they never mix with events relayed to stdout
+	level, err := logging.ParseLevel(config.Log.Level)
+	if err != nil {
+		fatal("Invalid log configuration", "error", err)
+	}
+	slog.SetDefault(logging.New(os.Stderr, level))
+
+	container := NewContainer(config)
+
+	r := SetupRouter(container, config)
+	server := NewHTTPServer(&config.Server, r)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	r := SetupRouter(container.UserHandler)
+	// A metrics listener that fails takes the API down with it
+	metricsErr := make(chan error, 1)
+	go func() {
+		var err error
+		if container.Metrics != nil && config.Metrics.Addr != "" {
+			err = RunServer(ctx, NewMetricsServer(&config.Metrics, container.Metrics.Handler()), config.Server.ShutdownTimeout)
+			if err != nil {
+				stop()
+			}
+		}
+		metricsErr <- err
+	}()
 
-	log.Printf("Server starting on :%s", config.Server.Port)
-	if err := http.ListenAndServe(":"+config.Server.Port, r); err != nil {
-		log.Fatal("Server failed to start:", err)
+	// The relay stops with the server; undelivered events wait in the outbox
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		if container.OutboxRelay != nil {
+			container.OutboxRelay.Run(ctx)
+		}
+	}()
+
+	serverCtx, stopServer := DrainContext(ctx, container.Health, config.Server.DrainDelay)
+	defer stopServer()
+
+	serverErr := RunServer(serverCtx, server, config.Server.ShutdownTimeout)
+	stop()
+	<-relayDone
+	if err := <-metricsErr; err != nil && serverErr == nil {
+		serverErr = err
+	}
+
+	if err := container.Close(); err != nil {
+		slog.Error("Failed to close database", "error", err)
+	}
+
+	if serverErr != nil {
+		fatal("Server failed", "error", serverErr)
+	}
+	slog.Info("Server stopped")
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// fatalConfig logs each configuration error on its own line and exits
+func fatalConfig(err error) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, err := range errs {
+		slog.Error("Invalid configuration", "error", err)
 	}
+	os.Exit(1)
 }
@@ -1,29 +1,139 @@
 package main
 
 import (
+	"log/slog"
 	"net/http"
 
+	"go-clean-code/internal/entities"
 	"go-clean-code/internal/handler"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRouter(userHandler *handler.UserHandler) *mux.Router {
+// SetupRouter wires the container's handlers to routes. When authentication
+// is enabled, every API route except login, token refresh and sign-up
+// requires an access token or API key, and API keys are limited to the
+// scope each route requires; admin routes keep their own token. What each
+// caller may do to which user is decided by its role in the usecase. Rate
+// limits apply after authentication, so authenticated clients are counted
+// by identity rather than address; failed authentications are counted by
+// address in front of it, so credentials cannot be guessed unthrottled.
+// Every request, routed or not, is given a
+// request ID, traced, written to the access log and counted in the metrics,
+// which are served here unless they have a listener of their own.
+func SetupRouter(container *Container, config *Config) http.Handler {
+	userHandler := container.UserHandler
+	adminToken := config.Admin.Token
+	rateLimit := func(r *mux.Router) {
+		if container.RateLimit != nil {
+			r.Use(container.RateLimit)
+		}
+	}
+
 	router := mux.NewRouter()
 
 	// API routes
 	api := router.PathPrefix("/api/v1").Subrouter()
-	api.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
-	api.HandleFunc("/users/{id}", userHandler.GetUser).Methods("GET")
-	api.HandleFunc("/users/{id}", userHandler.UpdateUser).Methods("PUT")
-	api.HandleFunc("/users/{id}", userHandler.DeleteUser).Methods("DELETE")
-	api.HandleFunc("/users", userHandler.ListUsers).Methods("GET")
+	if container.LimitAuthFailures != nil {
+		api.Use(container.LimitAuthFailures)
+	}
+	public := api.NewRoute().Subrouter()
+	rateLimit(public)
+	public.HandleFunc("/users", userHandler.CreateUser).Methods("POST")
+	if container.AuthHandler != nil {
+		public.HandleFunc("/auth/login", container.AuthHandler.Login).Methods("POST")
+		public.HandleFunc("/auth/refresh", container.AuthHandler.Refresh).Methods("POST")
+	}
+
+	// Admin routes exist only when a token is configured
+	if adminToken != "" {
+		admin := api.PathPrefix("/admin").Subrouter()
+		admin.Use(handler.RequireBearerToken(adminToken))
+		rateLimit(admin)
+		admin.HandleFunc("/users/{id}", userHandler.PurgeUser).Methods("DELETE")
+		admin.HandleFunc("/users/{id}/role", userHandler.ChangeUserRole).Methods("PUT")
+		admin.HandleFunc("/users/{id}/password", userHandler.ChangePassword).Methods("PUT")
+		if apiKeyHandler := container.APIKeyHandler; apiKeyHandler != nil {
+			admin.HandleFunc("/api-keys", apiKeyHandler.CreateAPIKey).Methods("POST")
+			admin.HandleFunc("/api-keys", apiKeyHandler.ListAPIKeys).Methods("GET")
+			admin.HandleFunc("/api-keys/{id}", apiKeyHandler.RevokeAPIKey).Methods("DELETE")
+		}
+	}
+
+	protected := api.NewRoute().Subrouter()
+	if container.Authenticate != nil {
+		protected.Use(container.Authenticate)
+	}
+	rateLimit(protected)
+
+	readers := protected.NewRoute().Subrouter()
+	readers.Use(handler.RequireScope(entities.ScopeUsersRead))
+	// Registered before /users/{id} so "search" and "export" are not taken for an ID
+	readers.HandleFunc("/users/search", userHandler.SearchUsers).Methods("GET")
+	readers.HandleFunc("/users/export", userHandler.ExportUsers).Methods("GET")
+	readers.HandleFunc("/users/{id}", userHandler.GetUser).Methods("GET")
+	readers.HandleFunc("/users", userHandler.ListUsers).Methods("GET")
+
+	writers := protected.NewRoute().Subrouter()
+	writers.Use(handler.RequireScope(entities.ScopeUsersWrite))
+	writers.HandleFunc("/users/import", userHandler.ImportUsers).Methods("POST")
+	writers.HandleFunc("/users/{id}", userHandler.UpdateUser).Methods("PUT")
+	writers.HandleFunc("/users/{id}", userHandler.PatchUser).Methods("PATCH")
+	writers.HandleFunc("/users/{id}/role", userHandler.ChangeUserRole).Methods("PUT")
+	writers.HandleFunc("/users/{id}/password", userHandler.ChangePassword).Methods("PUT")
+
+	deleters := protected.NewRoute().Subrouter()
+	deleters.Use(handler.RequireScope(entities.ScopeUsersDelete))
+	deleters.HandleFunc("/users/{id}", userHandler.DeleteUser).Methods("DELETE")
+	deleters.HandleFunc("/users/{id}/restore", userHandler.RestoreUser).Methods("POST")
+
+	auditors := protected.NewRoute().Subrouter()
+	auditors.Use(handler.RequireScope(entities.ScopeAuditRead))
+	auditors.HandleFunc("/users/{id}/audit", userHandler.ListUserAudit).Methods("GET")
+	auditors.HandleFunc("/audit", userHandler.ListAudit).Methods("GET")
 
 	// Health check
-	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte("OK"))
-	}).Methods("GET")
+	router.HandleFunc("/livez", container.HealthHandler.Livez).Methods("GET")
+	router.HandleFunc("/readyz", container.HealthHandler.Readyz).Methods("GET")
+
+	observers := []handler.RequestObserver{handler.AccessLogger(slog.Default())}
+	if container.Metrics != nil {
+		observers = append(observers, container.Metrics)
+		if config.Metrics.Addr == "" {
+			router.Handle(config.Metrics.Path, container.Metrics.Handler()).Methods("GET")
+		}
+	}
+
+	allowMethods(router)
+
+	return handler.RequestID(handler.Trace(router)(handler.Instrument(router, observers...)(router)))
+}
+
+// allowMethods answers requests to a known path with a method it does not
+// serve with a 405 problem. Mux reports a method mismatch inside one
+// subrouter as not found once a later sibling fails to match, so every path
+// is registered again, after the other routes, without a method.
+func allowMethods(router *mux.Router) {
+	var paths []string
+	methods := make(map[string][]string)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		// Subrouters match a prefix under any method
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		if _, seen := methods[path]; !seen {
+			paths = append(paths, path)
+		}
+		methods[path] = append(methods[path], routeMethods...)
+		return nil
+	})
 
-	return router
+	for _, path := range paths {
+		router.Handle(path, handler.MethodNotAllowed(methods[path]...))
+	}
 }
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-code/internal/dto"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter sets up the router over an in-memory container
+func newTestRouter(t *testing.T, configure func(*Config)) http.Handler {
+	t.Helper()
+	config := DefaultConfig()
+	config.Database.Driver = DriverMemory
+	config.Metrics.Enabled = false
+	if configure != nil {
+		configure(config)
+	}
+	container := NewContainer(config)
+	t.Cleanup(func() { container.Close() })
+	return SetupRouter(container, config)
+}
+
+// serve sends a request with an optional JSON body and one header per pair
+// of headers
+func serve(router http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestSetupRouter(t *testing.T) {
+	const adminToken = "admin-secret"
+	router := newTestRouter(t, func(config *Config) {
+		config.Admin.Token = adminToken
+		config.Auth.SigningKeys = "k1:MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTIzNDU2Nzg5MDE="
+		config.Auth.APIKeys = true
+	})
+	createKey := func(t *testing.T, scopes string) string {
+		t.Helper()
+		recorder := serve(router, http.MethodPost, "/api/v1/admin/api-keys",
+			`{"name": "test", "scopes": [`+scopes+`]}`, "Authorization", "Bearer "+adminToken)
+		require.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+		var created dto.CreateAPIKeyResponse
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&created))
+		return created.Key
+	}
+
+	t.Run("should serve the public routes without credentials", func(t *testing.T) {
+		recorder := serve(router, http.MethodPost, "/api/v1/users", `{"name": "John Doe", "email": "john@example.com"}`)
+		assert.Equal(t, http.StatusCreated, recorder.Code, recorder.Body.String())
+
+		recorder = serve(router, http.MethodPost, "/api/v1/auth/login", `{"email": "john@example.com", "password": "wrong password"}`)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/livez", "").Code)
+	})
+
+	t.Run("should keep the admin routes behind the admin token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/api/v1/admin/api-keys", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/api/v1/admin/api-keys", "",
+			"Authorization", "Bearer wrong").Code)
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/api/v1/admin/api-keys", "",
+			"Authorization", "Bearer "+adminToken).Code)
+	})
+
+	t.Run("should authenticate the protected routes and check each route's scope", func(t *testing.T) {
+		reader := createKey(t, `"users:read"`)
+		writer := createKey(t, `"users:write"`)
+
+		assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/api/v1/users", "").Code)
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/api/v1/users", "", "X-API-Key", reader).Code)
+
+		recorder := serve(router, http.MethodGet, "/api/v1/audit", "", "X-API-Key", reader)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "INSUFFICIENT_SCOPE")
+		recorder = serve(router, http.MethodDelete, "/api/v1/users/"+uuid.NewString(), "", "X-API-Key", writer)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "INSUFFICIENT_SCOPE")
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/api/v1/users/search?q=john", "", "X-API-Key", reader).Code)
+	})
+
+	t.Run("should let an imported user log in once given a password", func(t *testing.T) {
+		writer := createKey(t, `"users:write"`)
+		recorder := serve(router, http.MethodPost, "/api/v1/users/import", `{"name": "Jim Doe", "email": "jim@example.com"}`,
+			"Content-Type", dto.NDJSONContentType, "X-API-Key", writer)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var report dto.ImportUsersResponse
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&report))
+		require.Equal(t, 1, report.Created)
+		passwordPath := "/api/v1/users/" + report.Rows[0].ID.String() + "/password"
+		login := func(password string) *httptest.ResponseRecorder {
+			return serve(router, http.MethodPost, "/api/v1/auth/login", `{"email": "jim@example.com", "password": "`+password+`"}`)
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, login("correct horse battery").Code)
+		assert.Equal(t, http.StatusForbidden, serve(router, http.MethodPut, passwordPath,
+			`{"password": "correct horse battery"}`, "X-API-Key", writer).Code)
+		recorder = serve(router, http.MethodPut, "/api/v1/admin/users/"+report.Rows[0].ID.String()+"/password",
+			`{"password": "correct horse battery"}`, "Authorization", "Bearer "+adminToken)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+		recorder = login("correct horse battery")
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		var tokens dto.TokenResponse
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&tokens))
+
+		bearer := "Bearer " + tokens.AccessToken
+		recorder = serve(router, http.MethodPut, passwordPath, `{"password": "staple battery horse"}`, "Authorization", bearer)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "INVALID_CREDENTIALS")
+		assert.Equal(t, http.StatusOK, serve(router, http.MethodPut, passwordPath,
+			`{"password": "staple battery horse", "current_password": "correct horse battery"}`, "Authorization", bearer).Code)
+		assert.Equal(t, http.StatusOK, login("staple battery horse").Code)
+	})
+
+	t.Run("should stop an address that keeps failing to authenticate", func(t *testing.T) {
+		limited := newTestRouter(t, func(config *Config) {
+			config.Admin.Token = adminToken
+			config.RateLimit.AuthFailures = "2/m"
+		})
+
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, http.StatusUnauthorized, serve(limited, http.MethodDelete, "/api/v1/admin/users/"+uuid.NewString(), "",
+				"Authorization", "Bearer guess").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, serve(limited, http.MethodDelete, "/api/v1/admin/users/"+uuid.NewString(), "",
+			"Authorization", "Bearer "+adminToken).Code)
+	})
+}
+
+func TestSetupRouter_MethodNotAllowed(t *testing.T) {
+	router := newTestRouter(t, func(config *Config) {
+		config.Admin.Token = "secret"
+	})
+
+	tests := []struct {
+		method, path, allow string
+	}{
+		{http.MethodPatch, "/api/v1/users", "POST, GET"},
+		{http.MethodPost, "/api/v1/users/search", "GET"},
+		{http.MethodPost, "/api/v1/users/1", "GET, PUT, PATCH, DELETE"},
+		{http.MethodGet, "/api/v1/admin/users/1", "DELETE"},
+		{http.MethodPost, "/livez", "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			recorder := serve(router, tt.method, tt.path, "")
+
+			assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+			assert.Equal(t, tt.allow, recorder.Header().Get("Allow"))
+			var problem dto.ProblemDetails
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&problem))
+			assert.Equal(t, "METHOD_NOT_ALLOWED", problem.Code)
+		})
+	}
+
+	t.Run("should still answer unknown paths with 404", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/api/v1/nope", "").Code)
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewHTTPServer creates an http.Server with the timeouts from ServerConfig
+func NewHTTPServer(config *ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + config.Port,
+		Handler:           handler,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+	}
+}
+
+// NewMetricsServer creates an http.Server serving metrics at config.Path on
+// their own listener
+func NewMetricsServer(config *MetricsConfig, metrics http.Handler) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+config.Path, metrics)
+	return &http.Server{
+		Addr:              config.Addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// RunServer serves until ctx is cancelled, then drains in-flight requests
+// within shutdownTimeout
+func RunServer(ctx context.Context, server *http.Server, shutdownTimeout time.Duration) error {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("server failed to start: %w", err)
+	}
+	return Serve(ctx, server, listener, shutdownTimeout)
+}
+
+// Serve is RunServer on a listener that is already open
+func Serve(ctx context.Context, server *http.Server, listener net.Listener, shutdownTimeout time.Duration) error {
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("Server starting", "addr", listener.Addr().String())
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	select {
+	case err, ok := <-serverErr:
+		// Closed without an error means the server was closed elsewhere
+		if !ok {
+			return nil
+		}
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("server shutdown failed: %w", err)
+	}
+
+	return <-serverErr
+}
+
+// Drainer fails readiness so load balancers stop routing to the instance
+type Drainer interface {
+	Drain()
+}
+
+// DrainContext returns a context for the server that is cancelled delay
+// after ctx: readiness fails as soon as ctx is done, and the server keeps
+// serving for the delay so load balancers stop routing to it first
+func DrainContext(ctx context.Context, drainer Drainer, delay time.Duration) (context.Context, context.CancelFunc) {
+	serverCtx, stopServer := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-serverCtx.Done():
+			return
+		}
+		drainer.Drain()
+		select {
+		case <-time.After(delay):
+		case <-serverCtx.Done():
+		}
+		stopServer()
+	}()
+	return serverCtx, stopServer
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe_GracefulShutdown(t *testing.T) {
+	config := DefaultConfig()
+	config.Database.Driver = DriverMemory
+	config.Metrics.Enabled = false
+	container := NewContainer(config)
+	t.Cleanup(func() { container.Close() })
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", container.HealthHandler.Readyz)
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "done")
+	})
+
+	// httptest opens the listener; Serve runs the server on it
+	ts := httptest.NewUnstartedServer(mux)
+	baseURL := "http://" + ts.Listener.Addr().String()
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	readiness := func() int {
+		resp, err := client.Get(baseURL + "/readyz")
+		if err != nil {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	signalCtx, sendSignal := context.WithCancel(context.Background())
+	serverCtx, stopServer := DrainContext(signalCtx, container.Health, 200*time.Millisecond)
+	defer stopServer()
+	served := make(chan error, 1)
+	go func() { served <- Serve(serverCtx, ts.Config, ts.Listener, time.Second) }()
+
+	require.Eventually(t, func() bool { return readiness() == http.StatusOK }, time.Second, 10*time.Millisecond)
+
+	type result struct {
+		body string
+		err  error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := client.Get(baseURL + "/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	sendSignal()
+
+	// Readiness fails while the listener still accepts connections
+	assert.Eventually(t, func() bool { return readiness() == http.StatusServiceUnavailable }, time.Second, 10*time.Millisecond)
+
+	// Once the drain delay is over the listener closes, but the in-flight
+	// request is waited for
+	assert.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", ts.Listener.Addr().String())
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-served:
+		t.Fatalf("server stopped before the in-flight request finished: %v", err)
+	default:
+	}
+
+	close(release)
+	got := <-slow
+	require.NoError(t, got.err)
+	assert.Equal(t, "done", got.body)
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop after draining")
+	}
+}
+
+func TestServe_ShutdownTimeout(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- Serve(ctx, ts.Config, ts.Listener, 50*time.Millisecond) }()
+
+	go http.Get("http://" + ts.Listener.Addr().String())
+	<-started
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("server did not give up on the stuck request")
+	}
+}
+
+func TestServe_ClosedElsewhere(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.NotFoundHandler())
+
+	served := make(chan error, 1)
+	go func() { served <- Serve(context.Background(), ts.Config, ts.Listener, time.Second) }()
+	require.NoError(t, ts.Config.Close())
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server did not return after being closed")
+	}
+}
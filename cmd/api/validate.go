@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/logging"
+	"go-clean-code/internal/metrics"
+	"go-clean-code/internal/ratelimit"
+)
+
+// sslModes lists the DB_SSLMODE values lib/pq accepts
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// validate reports every invalid setting, each named by its environment
+// variable whichever layer it was set in
+func (c *Config) validate() []error {
+	v := &validator{}
+	c.Server.validate(v)
+	c.Database.validate(v)
+	v.check(c.Pagination.MaxLimit > 0, "PAGINATION_MAX_LIMIT must be positive")
+	v.check(c.Import.MaxRows > 0, "IMPORT_MAX_ROWS must be positive")
+	v.check(c.Health.CheckTimeout > 0, "HEALTH_CHECK_TIMEOUT must be positive")
+	c.Outbox.validate(v)
+	c.Auth.validate(v)
+	c.RateLimit.validate(v)
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		v.add("LOG_LEVEL", err)
+	}
+	c.Metrics.validate(v)
+	c.Tracing.validate(v)
+	return v.errs
+}
+
+// validator collects validation errors
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validator) add(name string, err error) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %w", name, err))
+}
+
+func (v *validator) port(name, value string) {
+	port, err := strconv.Atoi(value)
+	v.check(err == nil && port > 0 && port <= 65535, "%s must be a port number, got %q", name, value)
+}
+
+func (v *validator) nonNegative(name string, d time.Duration) {
+	v.check(d >= 0, "%s must not be negative", name)
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	v.check(slices.Contains(allowed, value), "%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+}
+
+func (c *ServerConfig) validate(v *validator) {
+	v.port("SERVER_PORT", c.Port)
+	v.nonNegative("SERVER_READ_TIMEOUT", c.ReadTimeout)
+	v.nonNegative("SERVER_READ_HEADER_TIMEOUT", c.ReadHeaderTimeout)
+	v.nonNegative("SERVER_WRITE_TIMEOUT", c.WriteTimeout)
+	v.nonNegative("SERVER_IDLE_TIMEOUT", c.IdleTimeout)
+	v.check(c.ShutdownTimeout > 0, "SERVER_SHUTDOWN_TIMEOUT must be positive")
+	v.nonNegative("SERVER_DRAIN_DELAY", c.DrainDelay)
+}
+
+func (c *DatabaseConfig) validate(v *validator) {
+	switch c.Driver {
+	case DriverPostgres:
+		v.check(c.Host != "", "DB_HOST is required for the postgres driver")
+		v.port("DB_PORT", c.Port)
+		v.check(c.User != "", "DB_USER is required for the postgres driver")
+		v.check(c.DBName != "", "DB_NAME is required for the postgres driver")
+		v.oneOf("DB_SSLMODE", c.SSLMode, sslModes...)
+	case DriverSQLite:
+		v.check(c.Path != "", "DB_PATH is required for the sqlite3 driver")
+	case DriverMemory:
+		return
+	default:
+		v.oneOf("DB_DRIVER", c.Driver, DriverPostgres, DriverSQLite, DriverMemory)
+		return
+	}
+
+	if _, err := c.TxIsolation(); err != nil {
+		v.add("DB_ISOLATION_LEVEL", err)
+	}
+	v.check(c.TxMaxRetries >= 0, "DB_TX_MAX_RETRIES must not be negative")
+	v.check(c.MaxOpenConns >= 0, "DB_MAX_OPEN_CONNS must not be negative")
+	v.check(c.MaxIdleConns >= 0, "DB_MAX_IDLE_CONNS must not be negative")
+	v.check(c.MaxOpenConns == 0 || c.MaxIdleConns <= c.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS must not exceed DB_MAX_OPEN_CONNS (%d)", c.MaxOpenConns)
+	v.nonNegative("DB_CONN_MAX_LIFETIME", c.ConnMaxLifetime)
+	v.nonNegative("DB_CONN_MAX_IDLE_TIME", c.ConnMaxIdleTime)
+	v.nonNegative("DB_CONNECT_TIMEOUT", c.ConnectTimeout)
+}
+
+func (c *OutboxConfig) validate(v *validator) {
+	if c.Publisher == "" {
+		return
+	}
+	switch c.Publisher {
+	case PublisherFile:
+		v.check(c.FilePath != "", "OUTBOX_FILE_PATH is required for the file publisher")
+	case PublisherHTTP:
+		target, err := url.Parse(c.HTTPURL)
+		v.check(err == nil && (target.Scheme == "http" || target.Scheme == "https") && target.Host != "",
+			"OUTBOX_HTTP_URL must be an http or https URL for the http publisher, got %q", c.HTTPURL)
+	case PublisherStdout:
+	default:
+		v.oneOf("OUTBOX_PUBLISHER", c.Publisher, PublisherStdout, PublisherFile, PublisherHTTP)
+	}
+	v.check(c.PollInterval > 0, "OUTBOX_POLL_INTERVAL must be positive")
+	v.check(c.BatchSize > 0, "OUTBOX_BATCH_SIZE must be positive")
+	v.check(c.BaseBackoff > 0, "OUTBOX_BASE_BACKOFF must be positive")
+	v.check(c.MaxBackoff >= c.BaseBackoff, "OUTBOX_MAX_BACKOFF must not be less than OUTBOX_BASE_BACKOFF")
+	v.check(c.Lease > 0, "OUTBOX_LEASE must be positive")
+	v.nonNegative("OUTBOX_RETENTION", c.Retention)
+}
+
+func (c *AuthConfig) validate(v *validator) {
+	if c.SigningKeys != "" {
+		if _, err := newTokenService(c); err != nil {
+			v.add("AUTH_SIGNING_KEYS", err)
+		}
+	}
+	v.check(c.Issuer != "", "AUTH_ISSUER is required")
+	v.check(c.AccessTokenTTL > 0, "AUTH_ACCESS_TOKEN_TTL must be positive")
+	v.check(c.RefreshTokenTTL > 0, "AUTH_REFRESH_TOKEN_TTL must be positive")
+}
+
+func (c *RateLimitConfig) validate(v *validator) {
+	if c.Default != "" {
+		if _, err := ratelimit.ParseLimit(c.Default); err != nil {
+			v.add("RATE_LIMIT_DEFAULT", err)
+		}
+	}
+	if _, err := ratelimit.ParseRouteLimits(c.Routes); err != nil {
+		v.add("RATE_LIMIT_ROUTES", err)
+	}
+	if c.AuthFailures != "" {
+		if _, err := ratelimit.ParseLimit(c.AuthFailures); err != nil {
+			v.add("RATE_LIMIT_AUTH_FAILURES", err)
+		}
+	}
+	v.check(c.SweepInterval > 0, "RATE_LIMIT_SWEEP_INTERVAL must be positive")
+}
+
+func (c *MetricsConfig) validate(v *validator) {
+	if !c.Enabled {
+		return
+	}
+	v.check(strings.HasPrefix(c.Path, "/"), "METRICS_PATH must start with /, got %q", c.Path)
+	if _, err := metrics.ParseBuckets(c.HTTPBuckets); err != nil {
+		v.add("METRICS_HTTP_BUCKETS", err)
+	}
+}
+
+func (c *TracingConfig) validate(v *validator) {
+	v.oneOf("TRACING_EXPORTER", c.Exporter, ExporterNone, ExporterStdout, ExporterOTLP)
+	v.check(c.SampleRatio >= 0 && c.SampleRatio <= 1, "TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.SampleRatio)
+	v.check(c.ServiceName != "", "TRACING_SERVICE_NAME is required")
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MinKeyLength is the shortest HMAC key accepted for signing tokens
+const MinKeyLength = 32
+
+// KeySet holds the HMAC keys tokens are signed with, by key ID. Tokens are
+// signed with the active key and verified with whichever key their kid
+// header names, so a new key can be made active while tokens signed with the
+// previous one remain valid until they expire.
+type KeySet struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewKeySet returns a key set signing with the key named activeKID
+func NewKeySet(activeKID string, keys map[string][]byte) (*KeySet, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one signing key is required")
+	}
+	for kid, key := range keys {
+		if kid == "" {
+			return nil, errors.New("signing key IDs must not be empty")
+		}
+		if len(key) < MinKeyLength {
+			return nil, fmt.Errorf("signing key %q must be at least %d bytes", kid, MinKeyLength)
+		}
+	}
+	if _, ok := keys[activeKID]; !ok {
+		return nil, fmt.Errorf("active signing key %q is not configured", activeKID)
+	}
+
+	copied := make(map[string][]byte, len(keys))
+	for kid, key := range keys {
+		copied[kid] = append([]byte(nil), key...)
+	}
+	return &KeySet{active: activeKID, keys: copied}, nil
+}
+
+// ParseKeys reads keys written as comma-separated kid:base64 pairs, such as
+// "2024-06:c2VjcmV0...,2024-01:b2xkZXI..."
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kid, encoded, ok := strings.Cut(pair, ":")
+		if !ok {
+			return nil, fmt.Errorf("signing key %q must be written as kid:base64", pair)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %q is not valid base64: %w", kid, err)
+		}
+		if _, duplicate := keys[kid]; duplicate {
+			return nil, fmt.Errorf("signing key %q is configured twice", kid)
+		}
+		keys[kid] = key
+	}
+	return keys, nil
+}
+
+func (k *KeySet) activeKey() (string, []byte) {
+	return k.active, k.keys[k.active]
+}
+
+func (k *KeySet) key(kid string) ([]byte, bool) {
+	key, ok := k.keys[kid]
+	return key, ok
+}
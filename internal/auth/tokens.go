@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Defaults for TokenService
+const (
+	DefaultIssuer         = "go-clean-code"
+	DefaultAccessTokenTTL = 15 * time.Minute
+)
+
+// TokenService issues and verifies HS256-signed JWT access tokens whose
+// subject is the user ID
+type TokenService struct {
+	keys      *KeySet
+	issuer    string
+	accessTTL time.Duration
+	now       func() time.Time
+}
+
+// TokenOption configures optional TokenService behaviour
+type TokenOption func(*TokenService)
+
+// WithIssuer sets the iss claim written and required
+func WithIssuer(issuer string) TokenOption {
+	return func(s *TokenService) {
+		if issuer != "" {
+			s.issuer = issuer
+		}
+	}
+}
+
+// WithAccessTokenTTL sets how long access tokens are valid
+func WithAccessTokenTTL(ttl time.Duration) TokenOption {
+	return func(s *TokenService) {
+		if ttl > 0 {
+			s.accessTTL = ttl
+		}
+	}
+}
+
+func NewTokenService(keys *KeySet, opts ...TokenOption) *TokenService {
+	s := &TokenService{
+		keys:      keys,
+		issuer:    DefaultIssuer,
+		accessTTL: DefaultAccessTokenTTL,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// ActiveKeyID names the key new tokens are signed with
+func (s *TokenService) ActiveKeyID() string {
+	kid, _ := s.keys.activeKey()
+	return kid
+}
+
+// IssueAccessToken signs an access token for userID with the active key
+func (s *TokenService) IssueAccessToken(userID uuid.UUID) (string, time.Time, error) {
+	now := s.now()
+	expiresAt := now.Add(s.accessTTL)
+	claims := jwt.RegisteredClaims{
+		Issuer:    s.issuer,
+		Subject:   userID.String(),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		ID:        uuid.NewString(),
+	}
+
+	kid, key := s.keys.activeKey()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return "", time.Time{}, entities.NewInternalError("failed to sign access token", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// VerifyAccessToken checks the signature, issuer and expiry of token and
+// returns the user it was issued to
+func (s *TokenService) VerifyAccessToken(token string) (uuid.UUID, error) {
+	claims := &jwt.RegisteredClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := s.keys.key(kid)
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+		return key, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(s.now),
+	)
+	if err != nil {
+		return uuid.Nil, entities.NewUnauthorizedError("invalid access token", entities.ErrInvalidToken)
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, entities.NewUnauthorizedError("invalid access token subject", entities.ErrInvalidToken)
+	}
+	return userID, nil
+}
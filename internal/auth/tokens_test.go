@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(fill byte) []byte {
+	return []byte(strings.Repeat(string(fill), MinKeyLength))
+}
+
+func TestParseKeys(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey('a'))
+
+	keys, err := ParseKeys("new:" + encoded + ", old:" + encoded)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"new": testKey('a'), "old": testKey('a')}, keys)
+
+	for _, spec := range []string{"new", "new:not base64!", "new:" + encoded + ",new:" + encoded} {
+		_, err := ParseKeys(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestNewKeySet(t *testing.T) {
+	_, err := NewKeySet("new", map[string][]byte{"new": testKey('a')})
+	assert.NoError(t, err)
+
+	_, err = NewKeySet("missing", map[string][]byte{"new": testKey('a')})
+	assert.ErrorContains(t, err, "not configured")
+
+	_, err = NewKeySet("new", map[string][]byte{"new": []byte("short")})
+	assert.ErrorContains(t, err, "at least")
+
+	_, err = NewKeySet("", nil)
+	assert.Error(t, err)
+}
+
+func TestTokenService(t *testing.T) {
+	oldKeys, err := NewKeySet("old", map[string][]byte{"old": testKey('o')})
+	require.NoError(t, err)
+	rotatedKeys, err := NewKeySet("new", map[string][]byte{"old": testKey('o'), "new": testKey('n')})
+	require.NoError(t, err)
+	userID := uuid.New()
+
+	t.Run("should verify tokens it issued", func(t *testing.T) {
+		service := NewTokenService(rotatedKeys, WithAccessTokenTTL(time.Minute))
+
+		token, expiresAt, err := service.IssueAccessToken(userID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+		require.NoError(t, err)
+		assert.Equal(t, "new", parsed.Header["kid"])
+
+		verified, err := service.VerifyAccessToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, verified)
+	})
+
+	t.Run("should accept tokens signed with a rotated out key", func(t *testing.T) {
+		token, _, err := NewTokenService(oldKeys).IssueAccessToken(userID)
+		require.NoError(t, err)
+
+		verified, err := NewTokenService(rotatedKeys).VerifyAccessToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userID, verified)
+	})
+
+	t.Run("should reject tokens signed with an unknown key", func(t *testing.T) {
+		token, _, err := NewTokenService(rotatedKeys).IssueAccessToken(userID)
+		require.NoError(t, err)
+
+		_, err = NewTokenService(oldKeys).VerifyAccessToken(token)
+		assert.True(t, entities.IsUnauthorizedError(err))
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		service := NewTokenService(oldKeys)
+		service.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		token, _, err := service.IssueAccessToken(userID)
+		require.NoError(t, err)
+
+		_, err = NewTokenService(oldKeys).VerifyAccessToken(token)
+		assert.Equal(t, entities.CodeInvalidToken, entities.ErrorCode(err))
+	})
+
+	t.Run("should reject tokens from another issuer", func(t *testing.T) {
+		token, _, err := NewTokenService(oldKeys, WithIssuer("someone-else")).IssueAccessToken(userID)
+		require.NoError(t, err)
+
+		_, err = NewTokenService(oldKeys).VerifyAccessToken(token)
+		assert.True(t, entities.IsUnauthorizedError(err))
+	})
+
+	t.Run("should reject unsigned tokens", func(t *testing.T) {
+		unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+			Issuer:    DefaultIssuer,
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		unsigned.Header["kid"] = "old"
+		token, err := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = NewTokenService(oldKeys).VerifyAccessToken(token)
+		assert.True(t, entities.IsUnauthorizedError(err))
+	})
+}
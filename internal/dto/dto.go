@@ -1,26 +1,280 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"time"
 
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// CreateUserRequest creates a user. Users created without a Password
+// cannot log in.
 type CreateUserRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password,omitempty"`
 }
 
+// UpdateUserRequest replaces the non-empty fields of a user.
+// ExpectedVersion comes from If-Match; zero means unconditional.
 type UpdateUserRequest struct {
-	Name  string `json:"name,omitempty"`
-	Email string `json:"email,omitempty"`
+	Name            string `json:"name,omitempty"`
+	Email           string `json:"email,omitempty"`
+	ExpectedVersion int64  `json:"-"`
+}
+
+// Media types accepted by PATCH /users/{id}
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JSONPatchContentType  = "application/json-patch+json"
+)
+
+// PatchOperation is a single RFC 6902 JSON Patch operation. Value is nil
+// when the member is absent and "null" when it is an explicit JSON null.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// PatchUserRequest holds either an RFC 7396 merge patch or an RFC 6902
+// JSON Patch; exactly one of MergePatch and Operations is non-nil.
+// ExpectedVersion comes from If-Match; zero means unconditional.
+type PatchUserRequest struct {
+	MergePatch      map[string]json.RawMessage
+	Operations      []PatchOperation
+	ExpectedVersion int64
 }
 
 type UserResponse struct {
-	ID    uuid.UUID `json:"id"`
-	Name  string    `json:"name"`
-	Email string    `json:"email"`
+	ID      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email"`
+	Role    string    `json:"role"`
+	Version int64     `json:"version"`
+}
+
+// ChangeUserRoleRequest gives a user a new role. A non-zero
+// ExpectedVersion must match the stored version, as in UpdateUserRequest.
+type ChangeUserRoleRequest struct {
+	Role            string `json:"role"`
+	ExpectedVersion int64  `json:"-"`
+}
+
+// ChangePasswordRequest sets a user's password. Users changing their own
+// password must also give the CurrentPassword, if they have one. A non-zero
+// ExpectedVersion must match the stored version, as in UpdateUserRequest.
+type ChangePasswordRequest struct {
+	Password        string `json:"password"`
+	CurrentPassword string `json:"current_password,omitempty"`
+	ExpectedVersion int64  `json:"-"`
+}
+
+// ListUsersRequest selects a page of users either by offset or by an
+// opaque cursor taken from a previous response's next_cursor. Timestamps
+// are RFC 3339 and Sort is a field name optionally prefixed with "-".
+type ListUsersRequest struct {
+	Limit         int    `json:"limit"`
+	Offset        int    `json:"offset"`
+	Cursor        string `json:"cursor,omitempty"`
+	Email         string `json:"email,omitempty"`
+	Name          string `json:"name,omitempty"`
+	NamePrefix    string `json:"name_prefix,omitempty"`
+	EmailDomain   string `json:"email_domain,omitempty"`
+	CreatedAfter  string `json:"created_after,omitempty"`
+	CreatedBefore string `json:"created_before,omitempty"`
+	UpdatedSince  string `json:"updated_since,omitempty"`
+	Sort          string `json:"sort,omitempty"`
 }
 
 type ListUsersResponse struct {
-	Users  []*UserResponse `json:"users"`
-	Total  int             `json:"total"`
-	Limit  int             `json:"limit"`
-	Offset int             `json:"offset"`
+	Users      []*UserResponse `json:"users"`
+	Total      int             `json:"total"`
+	Limit      int             `json:"limit"`
+	Offset     int             `json:"offset"`
+	NextCursor string          `json:"next_cursor,omitempty"`
+	Next       string          `json:"next,omitempty"`
+	Prev       string          `json:"prev,omitempty"`
+}
+
+// SearchUsersRequest is a free-text query matched against name and email
+type SearchUsersRequest struct {
+	Query string `json:"q"`
+	Limit int    `json:"limit"`
+}
+
+// UserSearchHit is a matched user with its relevance score; higher is better
+type UserSearchHit struct {
+	User  *UserResponse `json:"user"`
+	Score float64       `json:"score"`
+}
+
+type SearchUsersResponse struct {
+	Query string           `json:"query"`
+	Hits  []*UserSearchHit `json:"hits"`
+}
+
+// ListAuditRequest selects a newest-first page of audit records. A zero
+// UserID matches every user. From and To are RFC 3339; From is inclusive
+// and To exclusive.
+type ListAuditRequest struct {
+	UserID    uuid.UUID `json:"user_id,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	Action    string    `json:"action,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	Limit     int       `json:"limit"`
+	Offset    int       `json:"offset"`
+}
+
+// AuditRecordResponse is one recorded change with the user before and after it
+type AuditRecordResponse struct {
+	ID        uuid.UUID      `json:"id"`
+	UserID    uuid.UUID      `json:"user_id"`
+	Action    string         `json:"action"`
+	Actor     string         `json:"actor"`
+	RequestID string         `json:"request_id,omitempty"`
+	Before    *entities.User `json:"before"`
+	After     *entities.User `json:"after"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type ListAuditResponse struct {
+	Records []*AuditRecordResponse `json:"records"`
+	Total   int                    `json:"total"`
+	Limit   int                    `json:"limit"`
+	Offset  int                    `json:"offset"`
+}
+
+// Media types accepted by POST /users/import and produced by GET /users/export
+const (
+	CSVContentType    = "text/csv"
+	NDJSONContentType = "application/x-ndjson"
+)
+
+// ImportUsersRequest carries the parsed rows of an import. Mode is
+// "all_or_nothing" (the default) or "best_effort".
+type ImportUsersRequest struct {
+	Mode string
+	Rows []*ImportUserRow
+}
+
+// ImportUserRow is one user read from the import. Line is its line number
+// in the uploaded document; Error is set when the line could not be parsed.
+type ImportUserRow struct {
+	Line  int
+	Name  string
+	Email string
+	Error string
+}
+
+// ImportRowResult reports the outcome for one row. Status is "created",
+// "invalid", "conflict" or "skipped"; skipped rows were valid but not
+// created because an all-or-nothing import was rejected.
+type ImportRowResult struct {
+	Line   int        `json:"line"`
+	Status string     `json:"status"`
+	ID     *uuid.UUID `json:"id,omitempty"`
+	Email  string     `json:"email,omitempty"`
+	Code   string     `json:"code,omitempty"`
+	Error  string     `json:"error,omitempty"`
+}
+
+type ImportUsersResponse struct {
+	Mode      string             `json:"mode"`
+	Committed bool               `json:"committed"`
+	Total     int                `json:"total"`
+	Created   int                `json:"created"`
+	Invalid   int                `json:"invalid"`
+	Conflicts int                `json:"conflicts"`
+	Skipped   int                `json:"skipped"`
+	Rows      []*ImportRowResult `json:"rows"`
+}
+
+// ProblemDetails is an RFC 7807 error response body
+type ProblemDetails struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	ErrorType string `json:"error_type"`
+	Code      string `json:"code"`
+}
+
+// ExportUsersRequest selects the users to export with the filter and sort
+// fields of ListUsersRequest
+type ExportUsersRequest struct {
+	Email         string
+	Name          string
+	NamePrefix    string
+	EmailDomain   string
+	CreatedAfter  string
+	CreatedBefore string
+	UpdatedSince  string
+	Sort          string
+}
+
+// ExportedUser is one user in an export
+type ExportedUser struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Version   int64     `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenResponse carries a new access token and the refresh token to
+// exchange for the next one. ExpiresIn and RefreshExpiresIn are in seconds.
+type TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"`
+}
+
+// CreateAPIKeyRequest names a new API key and the scopes it grants. The key
+// never expires when ExpiresAt is omitted.
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyResponse describes an API key without revealing it
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+}
+
+// CreateAPIKeyResponse is the only response that carries the key itself
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys []*APIKeyResponse `json:"api_keys"`
+	Total   int               `json:"total"`
 }
@@ -0,0 +1,117 @@
+package entities
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scopes an API key can be granted
+const (
+	ScopeUsersRead   = "users:read"
+	ScopeUsersWrite  = "users:write"
+	ScopeUsersDelete = "users:delete"
+	ScopeAuditRead   = "audit:read"
+)
+
+// Scopes lists every scope an API key can be granted
+var Scopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeUsersDelete, ScopeAuditRead}
+
+// APIKeyPrefix starts every API key, so they are recognisable in logs and
+// secret scanners and can be told apart from access tokens
+const APIKeyPrefix = "gck_"
+
+const (
+	// apiKeyIDBytes is the entropy of the identifying part of a key, which
+	// is stored and shown in plain text
+	apiKeyIDBytes = 6
+	// apiKeySecretBytes is the entropy of the secret part of a key
+	apiKeySecretBytes = 32
+)
+
+// APIKey is a long-lived credential for machine-to-machine callers. Only the
+// SHA-256 hash of the key is stored; Prefix is its first, non-secret part and
+// identifies the key in listings.
+type APIKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// NewAPIKey creates a key named name granting scopes, which never expires
+// when expiresAt is nil, and returns it together with the key to hand to
+// the caller
+func NewAPIKey(name string, scopes []string, expiresAt *time.Time) (*APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", ErrInvalidName
+	}
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return nil, "", ErrInvalidScope
+		}
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", ErrInvalidExpiry
+	}
+
+	raw := make([]byte, apiKeyIDBytes+apiKeySecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	prefix := APIKeyPrefix + hex.EncodeToString(raw[:apiKeyIDBytes])
+	key := prefix + "_" + base64.RawURLEncoding.EncodeToString(raw[apiKeyIDBytes:])
+
+	granted := slices.Clone(scopes)
+	slices.Sort(granted)
+	return &APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   HashAPIKey(key),
+		Scopes:    slices.Compact(granted),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, key, nil
+}
+
+// HashAPIKey returns the form of an API key that is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether credential has the shape of an API key rather
+// than an access token
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// Active reports whether the key can be used at now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// HasScope reports whether the key was granted scope
+func (k *APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
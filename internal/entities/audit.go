@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditAction is the kind of change an AuditRecord describes
+type AuditAction string
+
+const (
+	AuditActionCreate  AuditAction = "create"
+	AuditActionUpdate  AuditAction = "update"
+	AuditActionDelete  AuditAction = "delete"
+	AuditActionRestore AuditAction = "restore"
+	AuditActionPurge   AuditAction = "purge"
+)
+
+// IsValid reports whether a is one of the known audit actions
+func (a AuditAction) IsValid() bool {
+	switch a {
+	case AuditActionCreate, AuditActionUpdate, AuditActionDelete, AuditActionRestore, AuditActionPurge:
+		return true
+	}
+	return false
+}
+
+// AuditRecord describes one change to a user. Before is the stored user
+// prior to the change and is nil for a create; After is the user as it
+// reads afterwards and is nil once the user is deleted or purged.
+type AuditRecord struct {
+	ID        uuid.UUID   `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Action    AuditAction `json:"action"`
+	Actor     string      `json:"actor"`
+	RequestID string      `json:"request_id,omitempty"`
+	Before    *User       `json:"before"`
+	After     *User       `json:"after"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NewAuditRecord records action on userID by the actor and request found in
+// ctx. The snapshots are copied so later changes to the users do not leak in.
+func NewAuditRecord(ctx context.Context, action AuditAction, userID uuid.UUID, before, after *User) *AuditRecord {
+	return &AuditRecord{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Action:    action,
+		Actor:     ActorFromContext(ctx),
+		RequestID: RequestIDFromContext(ctx),
+		Before:    snapshot(before),
+		After:     snapshot(after),
+		CreatedAt: time.Now(),
+	}
+}
+
+func snapshot(user *User) *User {
+	if user == nil {
+		return nil
+	}
+	copied := *user
+	return &copied
+}
@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+	userIDKey
+	scopesKey
+	principalKey
+)
+
+// AnonymousActor is recorded as the actor when no principal is attached to the context
+const AnonymousActor = "anonymous"
+
+// ContextWithActor returns a copy of ctx carrying the acting principal
+func ContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext returns the acting principal, or AnonymousActor
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID, or "" outside a request
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// ContextWithUserID returns a copy of ctx carrying the authenticated user
+func ContextWithUserID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+// UserIDFromContext returns the authenticated user, if any
+func UserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(userIDKey).(uuid.UUID)
+	return id, ok
+}
+
+// UserActor is the actor recorded for requests authenticated as user id
+func UserActor(id uuid.UUID) string {
+	return "user:" + id.String()
+}
+
+// ContextWithScopes returns a copy of ctx limited to scopes, for requests
+// authenticated with an API key
+func ContextWithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+// ScopesFromContext returns the scopes the request is limited to. ok is
+// false when the request is not limited by scopes.
+func ScopesFromContext(ctx context.Context) (scopes []string, ok bool) {
+	scopes, ok = ctx.Value(scopesKey).([]string)
+	return scopes, ok
+}
+
+// APIKeyActor is the actor recorded for requests authenticated with API key id
+func APIKeyActor(id uuid.UUID) string {
+	return "api_key:" + id.String()
+}
+
+// ContextWithPrincipal returns a copy of ctx acting as principal. Requests
+// authenticated as a user carry only their user ID, and their role is
+// looked up when it is needed.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext returns the principal attached to ctx, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
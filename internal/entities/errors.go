@@ -7,13 +7,96 @@ import (
 
 // Domain errors
 var (
-	ErrInvalidName       = errors.New("invalid name: name cannot be empty")
-	ErrInvalidEmail      = errors.New("invalid email: email must be valid format")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrEmailAlreadyUsed  = errors.New("email is already in use")
+	ErrInvalidName        = errors.New("invalid name: name cannot be empty")
+	ErrInvalidEmail       = errors.New("invalid email: email must be valid format")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrEmailAlreadyUsed   = errors.New("email is already in use")
+	ErrInvalidPagination  = errors.New("invalid pagination parameters")
+	ErrInvalidCursor      = errors.New("invalid pagination cursor")
+	ErrInvalidFilter      = errors.New("invalid filter parameter")
+	ErrInvalidSort        = errors.New("invalid sort parameter")
+	ErrInvalidSearch      = errors.New("invalid search query")
+	ErrInvalidPatch       = errors.New("invalid patch document")
+	ErrImmutableField     = errors.New("field is immutable")
+	ErrPatchTestFailed    = errors.New("patch test operation failed")
+	ErrVersionMismatch    = errors.New("user has been modified since it was read")
+	ErrUserNotDeleted     = errors.New("user is not deleted")
+	ErrInvalidImport      = errors.New("invalid import request")
+	ErrInvalidPassword    = errors.New("invalid password: password must be 12 to 72 characters and differ from the email")
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid or expired token")
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+	ErrInvalidScope       = errors.New("invalid scope: scopes must be non-empty and known")
+	ErrInvalidExpiry      = errors.New("invalid expiry: expiry must be in the future")
+	ErrInvalidAPIKey      = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrInvalidRole        = errors.New("invalid role: role must be admin, manager or member")
+	ErrPermissionDenied   = errors.New("permission denied")
 )
 
+// Stable machine-readable codes for the domain errors above
+const (
+	CodeInvalidName        = "INVALID_NAME"
+	CodeInvalidEmail       = "INVALID_EMAIL"
+	CodeUserNotFound       = "USER_NOT_FOUND"
+	CodeUserAlreadyExists  = "USER_ALREADY_EXISTS"
+	CodeEmailAlreadyUsed   = "EMAIL_ALREADY_USED"
+	CodeInvalidPagination  = "INVALID_PAGINATION"
+	CodeInvalidCursor      = "INVALID_CURSOR"
+	CodeInvalidFilter      = "INVALID_FILTER"
+	CodeInvalidSort        = "INVALID_SORT"
+	CodeInvalidSearch      = "INVALID_SEARCH"
+	CodeInvalidPatch       = "INVALID_PATCH"
+	CodeImmutableField     = "IMMUTABLE_FIELD"
+	CodePatchTestFailed    = "PATCH_TEST_FAILED"
+	CodeVersionMismatch    = "VERSION_MISMATCH"
+	CodeUserNotDeleted     = "USER_NOT_DELETED"
+	CodeInvalidImport      = "INVALID_IMPORT"
+	CodeInvalidPassword    = "INVALID_PASSWORD"
+	CodeInvalidCredentials = "INVALID_CREDENTIALS"
+	CodeInvalidToken       = "INVALID_TOKEN"
+	CodeInvalidScope       = "INVALID_SCOPE"
+	CodeInvalidExpiry      = "INVALID_EXPIRY"
+	CodeInvalidAPIKey      = "INVALID_API_KEY"
+	CodeAPIKeyNotFound     = "API_KEY_NOT_FOUND"
+	CodeInvalidRole        = "INVALID_ROLE"
+	CodePermissionDenied   = "PERMISSION_DENIED"
+)
+
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{ErrInvalidName, CodeInvalidName},
+	{ErrInvalidEmail, CodeInvalidEmail},
+	{ErrUserNotFound, CodeUserNotFound},
+	{ErrUserAlreadyExists, CodeUserAlreadyExists},
+	{ErrEmailAlreadyUsed, CodeEmailAlreadyUsed},
+	{ErrInvalidPagination, CodeInvalidPagination},
+	{ErrInvalidCursor, CodeInvalidCursor},
+	{ErrInvalidFilter, CodeInvalidFilter},
+	{ErrInvalidSort, CodeInvalidSort},
+	{ErrInvalidSearch, CodeInvalidSearch},
+	{ErrInvalidPatch, CodeInvalidPatch},
+	{ErrImmutableField, CodeImmutableField},
+	{ErrPatchTestFailed, CodePatchTestFailed},
+	{ErrVersionMismatch, CodeVersionMismatch},
+	{ErrUserNotDeleted, CodeUserNotDeleted},
+	{ErrInvalidImport, CodeInvalidImport},
+	{ErrInvalidPassword, CodeInvalidPassword},
+	{ErrInvalidCredentials, CodeInvalidCredentials},
+	// A reused refresh token is reported to clients like any other invalid one
+	{ErrRefreshTokenReused, CodeInvalidToken},
+	{ErrInvalidToken, CodeInvalidToken},
+	{ErrInvalidScope, CodeInvalidScope},
+	{ErrInvalidExpiry, CodeInvalidExpiry},
+	{ErrInvalidAPIKey, CodeInvalidAPIKey},
+	{ErrAPIKeyNotFound, CodeAPIKeyNotFound},
+	{ErrInvalidRole, CodeInvalidRole},
+	{ErrPermissionDenied, CodePermissionDenied},
+}
+
 // DomainError represents a domain-specific error with additional context
 type DomainError struct {
 	Type    ErrorType
@@ -36,10 +119,14 @@ func (e *DomainError) Unwrap() error {
 type ErrorType string
 
 const (
-	ValidationError ErrorType = "VALIDATION_ERROR"
-	NotFoundError   ErrorType = "NOT_FOUND_ERROR"
-	ConflictError   ErrorType = "CONFLICT_ERROR"
-	InternalError   ErrorType = "INTERNAL_ERROR"
+	ValidationError         ErrorType = "VALIDATION_ERROR"
+	NotFoundError           ErrorType = "NOT_FOUND_ERROR"
+	ConflictError           ErrorType = "CONFLICT_ERROR"
+	InternalError           ErrorType = "INTERNAL_ERROR"
+	PreconditionFailedError ErrorType = "PRECONDITION_FAILED_ERROR"
+	UnauthorizedError       ErrorType = "UNAUTHORIZED_ERROR"
+	ForbiddenError          ErrorType = "FORBIDDEN_ERROR"
+	RateLimitedError        ErrorType = "RATE_LIMITED_ERROR"
 )
 
 // NewValidationError creates a new validation error
@@ -69,6 +156,33 @@ func NewConflictError(message string, cause error) *DomainError {
 	}
 }
 
+// NewPreconditionFailedError creates a new precondition failed error
+func NewPreconditionFailedError(message string, cause error) *DomainError {
+	return &DomainError{
+		Type:    PreconditionFailedError,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// NewUnauthorizedError creates a new unauthorized error
+func NewUnauthorizedError(message string, cause error) *DomainError {
+	return &DomainError{
+		Type:    UnauthorizedError,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
+// NewForbiddenError creates a new forbidden error
+func NewForbiddenError(message string, cause error) *DomainError {
+	return &DomainError{
+		Type:    ForbiddenError,
+		Message: message,
+		Cause:   cause,
+	}
+}
+
 // NewInternalError creates a new internal error
 func NewInternalError(message string, cause error) *DomainError {
 	return &DomainError{
@@ -93,6 +207,21 @@ func IsConflictError(err error) bool {
 	return isErrorType(err, ConflictError)
 }
 
+// IsPreconditionFailedError checks if error is a precondition failed error
+func IsPreconditionFailedError(err error) bool {
+	return isErrorType(err, PreconditionFailedError)
+}
+
+// IsUnauthorizedError checks if error is an unauthorized error
+func IsUnauthorizedError(err error) bool {
+	return isErrorType(err, UnauthorizedError)
+}
+
+// IsForbiddenError checks if error is a forbidden error
+func IsForbiddenError(err error) bool {
+	return isErrorType(err, ForbiddenError)
+}
+
 // IsInternalError checks if error is an internal error
 func IsInternalError(err error) bool {
 	return isErrorType(err, InternalError)
@@ -105,3 +234,20 @@ func isErrorType(err error, errorType ErrorType) bool {
 	}
 	return false
 }
+
+// ErrorCode returns the stable code of the domain error wrapped by err.
+// Errors without a known sentinel fall back to their ErrorType, or
+// INTERNAL_ERROR when err is not a DomainError.
+func ErrorCode(err error) string {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	var domainErr *DomainError
+	if errors.As(err, &domainErr) {
+		return string(domainErr.Type)
+	}
+	return string(InternalError)
+}
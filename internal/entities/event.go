@@ -0,0 +1,54 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserEventType names a domain event published to downstream services
+type UserEventType string
+
+const (
+	UserCreated  UserEventType = "UserCreated"
+	UserUpdated  UserEventType = "UserUpdated"
+	UserDeleted  UserEventType = "UserDeleted"
+	UserRestored UserEventType = "UserRestored"
+	UserPurged   UserEventType = "UserPurged"
+)
+
+var auditEventTypes = map[AuditAction]UserEventType{
+	AuditActionCreate:  UserCreated,
+	AuditActionUpdate:  UserUpdated,
+	AuditActionDelete:  UserDeleted,
+	AuditActionRestore: UserRestored,
+	AuditActionPurge:   UserPurged,
+}
+
+// UserEvent announces a change to a user. User is the user after the
+// change, or its last state for UserDeleted and UserPurged. Consumers may
+// see an event more than once and should deduplicate by ID.
+type UserEvent struct {
+	ID         uuid.UUID     `json:"id"`
+	Type       UserEventType `json:"type"`
+	UserID     uuid.UUID     `json:"user_id"`
+	User       *User         `json:"user"`
+	RequestID  string        `json:"request_id,omitempty"`
+	OccurredAt time.Time     `json:"occurred_at"`
+}
+
+// NewUserEvent derives the event announcing the change recorded in record
+func NewUserEvent(record *AuditRecord) *UserEvent {
+	user := record.After
+	if user == nil {
+		user = record.Before
+	}
+	return &UserEvent{
+		ID:         uuid.New(),
+		Type:       auditEventTypes[record.Action],
+		UserID:     record.UserID,
+		User:       snapshot(user),
+		RequestID:  record.RequestID,
+		OccurredAt: record.CreatedAt,
+	}
+}
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// refreshTokenBytes is the entropy of a refresh token value
+const refreshTokenBytes = 32
+
+// RefreshToken is a single-use credential exchanged for a new access token.
+// Only the SHA-256 hash of its value is stored. Every token rotated from the
+// same login shares a FamilyID, so presenting a spent token can revoke the
+// whole family.
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// NewRefreshToken creates a token for userID in familyID that expires after
+// ttl, and returns it together with the value to hand to the client
+func NewRefreshToken(userID, familyID uuid.UUID, ttl time.Duration) (*RefreshToken, string, error) {
+	raw := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	return &RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: HashRefreshToken(value),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, value, nil
+}
+
+// HashRefreshToken returns the form of a refresh token value that is stored
+func HashRefreshToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// Expired reports whether the token can no longer be used at now
+func (t *RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
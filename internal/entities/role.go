@@ -0,0 +1,115 @@
+package entities
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Role determines what a user may do to other users
+type Role string
+
+const (
+	// RoleAdmin may do everything, including deleting users and changing roles
+	RoleAdmin Role = "admin"
+	// RoleManager may read and update every user and their audit history
+	RoleManager Role = "manager"
+	// RoleMember may only read and update their own user
+	RoleMember Role = "member"
+)
+
+// DefaultRole is given to new users
+const DefaultRole = RoleMember
+
+// ParseRole returns the role named s
+func ParseRole(s string) (Role, error) {
+	switch role := Role(s); role {
+	case RoleAdmin, RoleManager, RoleMember:
+		return role, nil
+	default:
+		return "", ErrInvalidRole
+	}
+}
+
+// Permission is an operation on users that roles are granted
+type Permission string
+
+const (
+	PermissionReadUser       Permission = "read_user"
+	PermissionListUsers      Permission = "list_users"
+	PermissionUpdateUser     Permission = "update_user"
+	PermissionImportUsers    Permission = "import_users"
+	PermissionDeleteUser     Permission = "delete_user"
+	PermissionChangeRole     Permission = "change_role"
+	PermissionChangePassword Permission = "change_password"
+	PermissionReadAudit      Permission = "read_audit"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionReadUser, PermissionListUsers, PermissionUpdateUser, PermissionImportUsers,
+		PermissionDeleteUser, PermissionChangeRole, PermissionChangePassword, PermissionReadAudit,
+	},
+	RoleManager: {
+		PermissionReadUser, PermissionListUsers, PermissionUpdateUser, PermissionImportUsers,
+		PermissionReadAudit,
+	},
+	RoleMember: {},
+}
+
+// scopePermissions are granted to API keys on every user for each scope
+var scopePermissions = map[string][]Permission{
+	ScopeUsersRead:   {PermissionReadUser, PermissionListUsers},
+	ScopeUsersWrite:  {PermissionUpdateUser, PermissionImportUsers},
+	ScopeUsersDelete: {PermissionDeleteUser},
+	ScopeAuditRead:   {PermissionReadAudit},
+}
+
+// selfPermissions are granted to every user on their own user
+var selfPermissions = []Permission{PermissionReadUser, PermissionUpdateUser, PermissionChangePassword}
+
+// Can reports whether the role is granted permission on every user
+func (r Role) Can(permission Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Principal is who a request acts as. UserID is uuid.Nil for principals that
+// are not users, such as the admin token and API keys.
+type Principal struct {
+	UserID uuid.UUID
+	Role   Role
+	// Permissions are granted on every user in addition to those of Role
+	Permissions []Permission
+}
+
+// APIKeyPrincipal returns who a request authenticated with an API key acts
+// as: no user and no role, but the permissions of the key's scopes
+func APIKeyPrincipal(scopes []string) Principal {
+	var permissions []Permission
+	for _, scope := range scopes {
+		permissions = append(permissions, scopePermissions[scope]...)
+	}
+	return Principal{Permissions: permissions}
+}
+
+// Can reports whether the principal is granted permission on the user
+// target, or on users in general when target is uuid.Nil
+func (p Principal) Can(permission Permission, target uuid.UUID) bool {
+	if p.Role.Can(permission) || slices.Contains(p.Permissions, permission) {
+		return true
+	}
+	if p.UserID == uuid.Nil || p.UserID != target {
+		return false
+	}
+	for _, granted := range selfPermissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
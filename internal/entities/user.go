@@ -1,17 +1,36 @@
 package entities
 
 import (
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// Password policy. bcrypt ignores everything past MaxPasswordLength bytes,
+// so longer passwords are rejected rather than silently truncated.
+const (
+	MinPasswordLength = 12
+	MaxPasswordLength = 72
+)
+
+// passwordHashCost is the bcrypt work factor for new password hashes
+const passwordHashCost = bcrypt.DefaultCost
+
+// User is the user aggregate. Version starts at 1 and is incremented by the
+// repository on every successful update, for optimistic concurrency control.
+// PasswordHash is empty for users who cannot log in and is never encoded.
 type User struct {
-	ID        uuid.UUID `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           uuid.UUID `json:"id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Role         Role      `json:"role"`
+	Version      int64     `json:"version"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // NewUser creates a new user with validation
@@ -25,6 +44,8 @@ func NewUser(name, email string) (*User, error) {
 		ID:        uuid.New(),
 		Name:      name,
 		Email:     email,
+		Role:      DefaultRole,
+		Version:   1,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -54,6 +75,56 @@ func (u *User) UpdateEmail(email string) error {
 	return nil
 }
 
+// ChangeRole gives the user role
+func (u *User) ChangeRole(role Role) {
+	u.Role = role
+	u.UpdatedAt = time.Now()
+}
+
+// SetPassword checks password against the password policy and stores its
+// bcrypt hash
+func (u *User) SetPassword(password string) error {
+	if err := ValidatePassword(password, u.Email); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashCost)
+	if err != nil {
+		return err
+	}
+	u.PasswordHash = string(hash)
+	return nil
+}
+
+// ChangePassword replaces the user's password, as SetPassword does
+func (u *User) ChangePassword(password string) error {
+	if err := u.SetPassword(password); err != nil {
+		return err
+	}
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// CheckPassword reports whether password matches the stored hash. Users
+// without a password never match.
+func (u *User) CheckPassword(password string) bool {
+	if u.PasswordHash == "" {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) == nil
+}
+
+// ValidatePassword enforces the password policy: between MinPasswordLength
+// characters and MaxPasswordLength bytes, not blank, and not the email address
+func ValidatePassword(password, email string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength || len(password) > MaxPasswordLength {
+		return ErrInvalidPassword
+	}
+	if strings.TrimSpace(password) == "" || (email != "" && strings.EqualFold(password, email)) {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
 // validateUserInput validates the input for creating a user
 func validateUserInput(name, email string) error {
 	if name == "" {
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAPIKeyUsecase is a mock implementation of APIKeyUsecaseInterface
+type MockAPIKeyUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyUsecase) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.CreateAPIKeyResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) ListAPIKeys(ctx context.Context) (*dto.ListAPIKeysResponse, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListAPIKeysResponse), args.Error(1)
+}
+
+func (m *MockAPIKeyUsecase) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyUsecase) AuthenticateAPIKey(ctx context.Context, key string) (*entities.APIKey, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entities.APIKey), args.Error(1)
+}
+
+func TestAPIKeyHandler_CreateAPIKey(t *testing.T) {
+	t.Run("should return the new key", func(t *testing.T) {
+		mockUsecase := new(MockAPIKeyUsecase)
+		handler := NewAPIKeyHandler(mockUsecase)
+		req := dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{entities.ScopeUsersRead}}
+		created := &dto.CreateAPIKeyResponse{
+			APIKeyResponse: dto.APIKeyResponse{ID: uuid.New(), Name: "billing", Prefix: "gck_0123456789ab", Scopes: req.Scopes},
+			Key:            "gck_0123456789ab_secret",
+		}
+		mockUsecase.On("CreateAPIKey", mock.Anything, req).Return(created, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", strings.NewReader(`{"name":"billing","scopes":["users:read"]}`))
+		recorder := httptest.NewRecorder()
+		handler.CreateAPIKey(recorder, request)
+
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+		var response map[string]interface{}
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+		assert.Equal(t, "gck_0123456789ab_secret", response["key"])
+		assert.Equal(t, "gck_0123456789ab", response["prefix"])
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid scopes", func(t *testing.T) {
+		mockUsecase := new(MockAPIKeyUsecase)
+		handler := NewAPIKeyHandler(mockUsecase)
+		mockUsecase.On("CreateAPIKey", mock.Anything, mock.Anything).
+			Return(nil, entities.NewValidationError("invalid API key", entities.ErrInvalidScope))
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", strings.NewReader(`{"name":"billing","scopes":["everything"]}`))
+		recorder := httptest.NewRecorder()
+		handler.CreateAPIKey(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidScope, decodeProblem(t, recorder).Code)
+	})
+}
+
+func TestAPIKeyHandler_ListAPIKeys(t *testing.T) {
+	mockUsecase := new(MockAPIKeyUsecase)
+	handler := NewAPIKeyHandler(mockUsecase)
+	keys := &dto.ListAPIKeysResponse{
+		APIKeys: []*dto.APIKeyResponse{{ID: uuid.New(), Name: "billing", Prefix: "gck_0123456789ab", Scopes: []string{entities.ScopeUsersRead}}},
+		Total:   1,
+	}
+	mockUsecase.On("ListAPIKeys", mock.Anything).Return(keys, nil)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-keys", nil)
+	recorder := httptest.NewRecorder()
+	handler.ListAPIKeys(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), `"key"`)
+	var response dto.ListAPIKeysResponse
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.Equal(t, 1, response.Total)
+	assert.Equal(t, "billing", response.APIKeys[0].Name)
+}
+
+func TestAPIKeyHandler_RevokeAPIKey(t *testing.T) {
+	t.Run("should revoke key", func(t *testing.T) {
+		mockUsecase := new(MockAPIKeyUsecase)
+		handler := NewAPIKeyHandler(mockUsecase)
+		id := uuid.New()
+		mockUsecase.On("RevokeAPIKey", mock.Anything, id).Return(nil)
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/"+id.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		recorder := httptest.NewRecorder()
+		handler.RevokeAPIKey(recorder, request)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return 404 for unknown key", func(t *testing.T) {
+		mockUsecase := new(MockAPIKeyUsecase)
+		handler := NewAPIKeyHandler(mockUsecase)
+		id := uuid.New()
+		mockUsecase.On("RevokeAPIKey", mock.Anything, id).
+			Return(entities.NewNotFoundError("API key not found", entities.ErrAPIKeyNotFound))
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/"+id.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		recorder := httptest.NewRecorder()
+		handler.RevokeAPIKey(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, entities.CodeAPIKeyNotFound, decodeProblem(t, recorder).Code)
+	})
+
+	t.Run("should reject invalid ID", func(t *testing.T) {
+		handler := NewAPIKeyHandler(new(MockAPIKeyUsecase))
+
+		request := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/nope", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": "nope"})
+		recorder := httptest.NewRecorder()
+		handler.RevokeAPIKey(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, CodeInvalidAPIKeyID, decodeProblem(t, recorder).Code)
+	})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/usecase"
+)
+
+type AuthHandler struct {
+	authUsecase usecase.AuthUsecaseInterface
+}
+
+func NewAuthHandler(authUsecase usecase.AuthUsecaseInterface) *AuthHandler {
+	return &AuthHandler{
+		authUsecase: authUsecase,
+	}
+}
+
+// Login exchanges an email and password for an access and refresh token
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var req dto.LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	tokens, err := h.authUsecase.Login(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req dto.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+
+	tokens, err := h.authUsecase.Refresh(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeTokens(w, tokens)
+}
+
+// writeTokens writes a token response that caches must not store
+func writeTokens(w http.ResponseWriter, tokens *dto.TokenResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAuthUsecase is a mock implementation of AuthUsecaseInterface
+type MockAuthUsecase struct {
+	mock.Mock
+}
+
+func (m *MockAuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.TokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.TokenResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.TokenResponse), args.Error(1)
+}
+
+func TestAuthHandler_Login(t *testing.T) {
+	tokens := &dto.TokenResponse{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "refresh", RefreshExpiresIn: 3600}
+
+	t.Run("should return tokens for valid credentials", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		handler := NewAuthHandler(mockUsecase)
+		req := dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"}
+		mockUsecase.On("Login", mock.Anything, req).Return(tokens, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"john@example.com","password":"correct horse battery"}`))
+		recorder := httptest.NewRecorder()
+		handler.Login(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+		var response dto.TokenResponse
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+		assert.Equal(t, *tokens, response)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 for invalid credentials", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		handler := NewAuthHandler(mockUsecase)
+		mockUsecase.On("Login", mock.Anything, mock.Anything).
+			Return(nil, entities.NewUnauthorizedError("login failed", entities.ErrInvalidCredentials))
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{"email":"john@example.com","password":"wrong"}`))
+		recorder := httptest.NewRecorder()
+		handler.Login(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidCredentials, decodeProblem(t, recorder).Code)
+	})
+
+	t.Run("should reject invalid JSON", func(t *testing.T) {
+		handler := NewAuthHandler(new(MockAuthUsecase))
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(`{`))
+		recorder := httptest.NewRecorder()
+		handler.Login(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, CodeInvalidJSON, decodeProblem(t, recorder).Code)
+	})
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	t.Run("should rotate refresh token", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		handler := NewAuthHandler(mockUsecase)
+		tokens := &dto.TokenResponse{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900, RefreshToken: "next", RefreshExpiresIn: 3600}
+		mockUsecase.On("Refresh", mock.Anything, dto.RefreshTokenRequest{RefreshToken: "current"}).Return(tokens, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"current"}`))
+		recorder := httptest.NewRecorder()
+		handler.Refresh(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response dto.TokenResponse
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+		assert.Equal(t, "next", response.RefreshToken)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return 401 for reused token", func(t *testing.T) {
+		mockUsecase := new(MockAuthUsecase)
+		handler := NewAuthHandler(mockUsecase)
+		mockUsecase.On("Refresh", mock.Anything, mock.Anything).
+			Return(nil, entities.NewUnauthorizedError("refresh failed", entities.ErrRefreshTokenReused))
+
+		request := httptest.NewRequest(http.MethodPost, "/api/v1/auth/refresh", strings.NewReader(`{"refresh_token":"spent"}`))
+		recorder := httptest.NewRecorder()
+		handler.Refresh(recorder, request)
+
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidToken, decodeProblem(t, recorder).Code)
+	})
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+)
+
+// setETag exposes the user version as a strong entity tag
+func setETag(w http.ResponseWriter, user *dto.UserResponse) {
+	w.Header().Set("ETag", `"`+strconv.FormatInt(user.Version, 10)+`"`)
+}
+
+// ifMatchVersion returns the version required by the If-Match header, or
+// zero when any version may be written. It writes a problem and returns
+// false when the header is missing but required, or cannot be evaluated.
+func (h *UserHandler) ifMatchVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	switch {
+	case header == "":
+		if h.requireIfMatch {
+			writeProblem(w, r, http.StatusPreconditionRequired, entities.PreconditionFailedError, CodePreconditionRequired, "If-Match header is required")
+			return 0, false
+		}
+		return 0, true
+	case header == "*":
+		return 0, true
+	case strings.HasPrefix(header, "W/"):
+		// If-Match uses strong comparison, so a weak tag never matches
+		writeProblem(w, r, http.StatusPreconditionFailed, entities.PreconditionFailedError, entities.CodeVersionMismatch, "If-Match does not accept weak entity tags")
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(header, `"`), 10, 64)
+	if err != nil || version <= 0 || len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		writeValidationProblem(w, r, CodeInvalidIfMatch, "If-Match must be * or a single entity tag taken from ETag")
+		return 0, false
+	}
+	return version, true
+}
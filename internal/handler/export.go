@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+)
+
+const jsonContentType = "application/json"
+
+// exportFormats maps the format parameter of GET /users/export to the media
+// type written
+var exportFormats = map[string]string{
+	"csv":    dto.CSVContentType,
+	"ndjson": dto.NDJSONContentType,
+	"json":   jsonContentType,
+}
+
+// exportExtensions names the file suggested for each export media type
+var exportExtensions = map[string]string{
+	dto.CSVContentType:    "csv",
+	dto.NDJSONContentType: "ndjson",
+	jsonContentType:       "json",
+}
+
+// exportCSVHeader is the first row of a CSV export
+var exportCSVHeader = []string{"id", "name", "email", "version", "created_at", "updated_at"}
+
+// ExportUsers streams every user matching the list filters as CSV, NDJSON or
+// a JSON array, chosen by the format parameter or else the Accept header. An
+// error after the first user has been written aborts the response, so the
+// client sees a truncated transfer rather than a complete-looking file.
+func (h *UserHandler) ExportUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var contentType string
+	if format := query.Get("format"); format != "" {
+		var ok bool
+		if contentType, ok = exportFormats[format]; !ok {
+			writeValidationProblem(w, r, CodeInvalidFormat, "format must be csv, ndjson or json")
+			return
+		}
+	} else if contentType = negotiateExport(r.Header.Get("Accept")); contentType == "" {
+		writeProblem(w, r, http.StatusNotAcceptable, entities.ValidationError, CodeNotAcceptable,
+			"Accept must allow "+dto.CSVContentType+", "+dto.NDJSONContentType+" or "+jsonContentType)
+		return
+	}
+
+	req := dto.ExportUsersRequest{
+		Email:         query.Get("email"),
+		Name:          query.Get("name"),
+		NamePrefix:    query.Get("name_prefix"),
+		EmailDomain:   query.Get("email_domain"),
+		CreatedAfter:  query.Get("created_after"),
+		CreatedBefore: query.Get("created_before"),
+		UpdatedSince:  query.Get("updated_since"),
+		Sort:          query.Get("sort"),
+	}
+
+	// An export may take longer than the server write timeout allows
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	export := &exportWriter{w: w, contentType: contentType}
+	err := h.userUsecase.ExportUsers(r.Context(), req, export.write)
+	if err == nil {
+		err = export.finish()
+	}
+	switch {
+	case err == nil || r.Context().Err() != nil:
+		// Done, or the client went away
+	case !export.started:
+		writeError(w, r, err)
+	default:
+		logInternalError(r, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// negotiateExport picks the export media type the Accept header prefers, or
+// "" when it allows none. A missing header or wildcard selects JSON.
+func negotiateExport(accept string) string {
+	if strings.TrimSpace(accept) == "" {
+		return jsonContentType
+	}
+
+	var (
+		best        string
+		bestQuality float64
+	)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(mediaRange)
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+
+		var candidate string
+		switch mediaType {
+		case dto.CSVContentType, dto.NDJSONContentType, jsonContentType:
+			candidate = mediaType
+		case "*/*", "application/*":
+			candidate = jsonContentType
+		case "text/*":
+			candidate = dto.CSVContentType
+		}
+		if candidate != "" && quality > bestQuality {
+			best, bestQuality = candidate, quality
+		}
+	}
+	return best
+}
+
+// exportWriter encodes exported users in one media type. The status line and
+// any preamble are written with the first user, so errors raised before it
+// can still become a problem response.
+type exportWriter struct {
+	w           http.ResponseWriter
+	contentType string
+	csv         *csv.Writer
+	started     bool
+	count       int
+}
+
+func (e *exportWriter) begin() error {
+	e.started = true
+	e.w.Header().Set("Content-Type", e.contentType)
+	e.w.Header().Set("Content-Disposition", `attachment; filename="users.`+exportExtensions[e.contentType]+`"`)
+	e.w.WriteHeader(http.StatusOK)
+
+	switch e.contentType {
+	case dto.CSVContentType:
+		e.csv = csv.NewWriter(e.w)
+		return e.csv.Write(exportCSVHeader)
+	case jsonContentType:
+		_, err := e.w.Write([]byte("["))
+		return err
+	}
+	return nil
+}
+
+func (e *exportWriter) write(user *dto.ExportedUser) error {
+	if !e.started {
+		if err := e.begin(); err != nil {
+			return err
+		}
+	}
+	e.count++
+
+	switch e.contentType {
+	case dto.CSVContentType:
+		return e.csv.Write([]string{
+			user.ID.String(),
+			user.Name,
+			user.Email,
+			strconv.FormatInt(user.Version, 10),
+			user.CreatedAt.UTC().Format(time.RFC3339Nano),
+			user.UpdatedAt.UTC().Format(time.RFC3339Nano),
+		})
+	case jsonContentType:
+		if e.count > 1 {
+			if _, err := e.w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+	}
+	return json.NewEncoder(e.w).Encode(user)
+}
+
+// finish completes the document, writing an empty one when no user matched
+func (e *exportWriter) finish() error {
+	if !e.started {
+		if err := e.begin(); err != nil {
+			return err
+		}
+	}
+
+	switch e.contentType {
+	case dto.CSVContentType:
+		e.csv.Flush()
+		return e.csv.Error()
+	case jsonContentType:
+		_, err := e.w.Write([]byte("]\n"))
+		return err
+	}
+	return nil
+}
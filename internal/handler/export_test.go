@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserHandler_ExportUsers(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []*dto.ExportedUser{
+		{ID: uuid.New(), Name: "John Doe", Email: "john@example.com", Version: 1, CreatedAt: createdAt, UpdatedAt: createdAt},
+		{ID: uuid.New(), Name: "Doe, Jane", Email: "jane@example.com", Version: 2, CreatedAt: createdAt, UpdatedAt: createdAt},
+	}
+	newRequest := func(target, accept string) *http.Request {
+		request := httptest.NewRequest(http.MethodGet, target, nil)
+		if accept != "" {
+			request.Header.Set("Accept", accept)
+		}
+		return request
+	}
+
+	t.Run("should stream CSV with list filters", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		expected := dto.ExportUsersRequest{EmailDomain: "example.com", Sort: "-name"}
+		mockUsecase.On("ExportUsers", mock.Anything, expected).Return(users, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export?format=csv&email_domain=example.com&sort=-name", "application/json"))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Header().Get("Content-Disposition"), "users.csv")
+		records, err := csv.NewReader(recorder.Body).ReadAll()
+		assert.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"id", "name", "email", "version", "created_at", "updated_at"},
+			{users[0].ID.String(), "John Doe", "john@example.com", "1", "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"},
+			{users[1].ID.String(), "Doe, Jane", "jane@example.com", "2", "2024-01-02T03:04:05Z", "2024-01-02T03:04:05Z"},
+		}, records)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should stream NDJSON chosen by Accept", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		mockUsecase.On("ExportUsers", mock.Anything, dto.ExportUsersRequest{}).Return(users, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export", "application/json;q=0.5, application/x-ndjson"))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "application/x-ndjson", recorder.Header().Get("Content-Type"))
+		lines := strings.Split(strings.TrimSpace(recorder.Body.String()), "\n")
+		assert.Len(t, lines, 2)
+		var first dto.ExportedUser
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.Equal(t, users[0].ID, first.ID)
+	})
+
+	t.Run("should stream a JSON array by default", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		mockUsecase.On("ExportUsers", mock.Anything, dto.ExportUsersRequest{}).Return(users, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export", "*/*"))
+
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		var exported []*dto.ExportedUser
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &exported))
+		assert.Equal(t, users, exported)
+	})
+
+	t.Run("should write an empty document when nothing matches", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		mockUsecase.On("ExportUsers", mock.Anything, dto.ExportUsersRequest{}).Return(nil, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export?format=json", ""))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, "[]", recorder.Body.String())
+	})
+
+	t.Run("should reject an unknown format", func(t *testing.T) {
+		handler := NewUserHandler(new(MockUserUsecase))
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export?format=xml", ""))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), CodeInvalidFormat)
+	})
+
+	t.Run("should respond 406 when Accept allows no format", func(t *testing.T) {
+		handler := NewUserHandler(new(MockUserUsecase))
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export", "application/xml, text/csv;q=0"))
+
+		assert.Equal(t, http.StatusNotAcceptable, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), CodeNotAcceptable)
+	})
+
+	t.Run("should write a problem for errors before the first user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		invalid := entities.NewValidationError("unsupported sort", entities.ErrInvalidSort)
+		mockUsecase.On("ExportUsers", mock.Anything, dto.ExportUsersRequest{Sort: "password"}).Return(nil, invalid)
+
+		recorder := httptest.NewRecorder()
+		handler.ExportUsers(recorder, newRequest("/users/export?sort=password", ""))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), entities.CodeInvalidSort)
+	})
+
+	t.Run("should abort the response on errors after the first user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+		mockUsecase.On("ExportUsers", mock.Anything, dto.ExportUsersRequest{}).Return(users, errors.New("connection reset"))
+
+		recorder := httptest.NewRecorder()
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			handler.ExportUsers(recorder, newRequest("/users/export?format=ndjson", ""))
+		})
+	})
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go-clean-code/internal/health"
+)
+
+// ReadinessChecker reports whether the service's dependencies are usable
+type ReadinessChecker interface {
+	Check(ctx context.Context) health.Report
+}
+
+type HealthHandler struct {
+	checker ReadinessChecker
+}
+
+func NewHealthHandler(checker ReadinessChecker) *HealthHandler {
+	return &HealthHandler{
+		checker: checker,
+	}
+}
+
+// Livez reports that the process is serving requests. It checks no
+// dependency, so an unreachable database never gets the service restarted.
+func (h *HealthHandler) Livez(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, health.Report{Status: health.StatusUp, Checks: []health.Result{}})
+}
+
+// Readyz runs the readiness checks and answers 503 Service Unavailable
+// when any fails, including once the service has begun shutting down
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	writeHealth(w, r, h.checker.Check(r.Context()))
+}
+
+// writeHealth answers "OK" or "UNAVAILABLE", or with ?verbose the report
+// as JSON listing every check with its status and latency
+func writeHealth(w http.ResponseWriter, r *http.Request, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if _, verbose := r.URL.Query()["verbose"]; verbose {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	if status == http.StatusOK {
+		_, _ = w.Write([]byte("OK"))
+	} else {
+		_, _ = w.Write([]byte("UNAVAILABLE"))
+	}
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-clean-code/internal/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// stubChecker reports a fixed readiness
+type stubChecker struct {
+	report health.Report
+}
+
+func (s stubChecker) Check(ctx context.Context) health.Report {
+	return s.report
+}
+
+func TestHealthHandler(t *testing.T) {
+	down := health.Report{
+		Status: health.StatusDown,
+		Checks: []health.Result{
+			{Name: health.ShutdownCheck, Status: health.StatusUp},
+			{Name: "database", Status: health.StatusDown, LatencyMS: 2000, Error: "context deadline exceeded"},
+			{Name: "migrations", Status: health.StatusUp, LatencyMS: 1.5, Details: map[string]any{"version": 12, "dirty": false}},
+		},
+	}
+	handler := NewHealthHandler(stubChecker{report: down})
+
+	serve := func(serve http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		serve(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+		return recorder
+	}
+
+	t.Run("should report live regardless of dependencies", func(t *testing.T) {
+		recorder := serve(handler.Livez, "/livez")
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "OK", recorder.Body.String())
+		assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+		recorder = serve(handler.Livez, "/livez?verbose")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.JSONEq(t, `{"status":"up","checks":[]}`, recorder.Body.String())
+	})
+
+	t.Run("should report unavailable when a check fails", func(t *testing.T) {
+		recorder := serve(handler.Readyz, "/readyz")
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "UNAVAILABLE", recorder.Body.String())
+	})
+
+	t.Run("should list every check in verbose mode", func(t *testing.T) {
+		recorder := serve(handler.Readyz, "/readyz?verbose=1")
+
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{
+			"status": "down",
+			"checks": [
+				{"name": "shutdown", "status": "up", "latency_ms": 0},
+				{"name": "database", "status": "down", "latency_ms": 2000, "error": "context deadline exceeded"},
+				{"name": "migrations", "status": "up", "latency_ms": 1.5, "details": {"version": 12, "dirty": false}}
+			]
+		}`, recorder.Body.String())
+	})
+
+	t.Run("should report ready when every check passes", func(t *testing.T) {
+		checker := health.New()
+		checker.Register("database", func(ctx context.Context) (map[string]any, error) { return nil, nil })
+		handler := NewHealthHandler(checker)
+
+		recorder := serve(handler.Readyz, "/readyz")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "OK", recorder.Body.String())
+
+		recorder = serve(handler.Readyz, "/readyz?verbose")
+		var report health.Report
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+		assert.Equal(t, health.StatusUp, report.Status)
+		assert.Len(t, report.Checks, 2)
+
+		checker.Drain()
+		recorder = serve(handler.Readyz, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	})
+}
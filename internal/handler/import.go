@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+)
+
+// MaxImportBytes bounds the body of POST /users/import
+const MaxImportBytes = 32 << 20
+
+// maxNDJSONLine bounds a single NDJSON line
+const maxNDJSONLine = 64 << 10
+
+// ImportUsers creates users from a CSV or NDJSON upload and responds with a
+// per-row report. A rejected all-or-nothing import responds 422.
+func (h *UserHandler) ImportUsers(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxImportBytes)
+
+	var (
+		rows []*dto.ImportUserRow
+		err  error
+	)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case dto.CSVContentType:
+		rows, err = parseCSVImport(r.Body)
+	case dto.NDJSONContentType:
+		rows, err = parseNDJSONImport(r.Body)
+	default:
+		writeProblem(w, r, http.StatusUnsupportedMediaType, entities.ValidationError, CodeUnsupportedMediaType,
+			"Content-Type must be "+dto.CSVContentType+" or "+dto.NDJSONContentType)
+		return
+	}
+
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, entities.ValidationError, CodeRequestTooLarge,
+			fmt.Sprintf("Import must not exceed %d bytes", MaxImportBytes))
+		return
+	case err != nil:
+		writeValidationProblem(w, r, entities.CodeInvalidImport, err.Error())
+		return
+	}
+
+	report, err := h.userUsecase.ImportUsers(r.Context(), dto.ImportUsersRequest{
+		Mode: r.URL.Query().Get("mode"),
+		Rows: rows,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if !report.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseCSVImport reads a CSV document whose header row names a "name" and an
+// "email" column, in any order. Rows with the wrong number of fields are
+// reported as invalid rather than failing the whole document.
+func parseCSVImport(body io.Reader) ([]*dto.ImportUserRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, errors.New("CSV header row is missing")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	nameCol, emailCol := -1, -1
+	for i, column := range header {
+		switch strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))) {
+		case "name":
+			nameCol = i
+		case "email":
+			emailCol = i
+		}
+	}
+	if nameCol < 0 || emailCol < 0 {
+		return nil, errors.New("CSV header must include name and email columns")
+	}
+
+	var rows []*dto.ImportUserRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &dto.ImportUserRow{Line: line}
+		if len(record) != len(header) {
+			row.Error = fmt.Sprintf("expected %d fields, got %d", len(header), len(record))
+		} else {
+			row.Name = strings.TrimSpace(record[nameCol])
+			row.Email = strings.TrimSpace(record[emailCol])
+		}
+		rows = append(rows, row)
+	}
+}
+
+// csvError describes CSV syntax errors by line and passes read errors through
+func csvError(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("CSV line %d: %v", parseErr.Line, parseErr.Err)
+	}
+	return err
+}
+
+// parseNDJSONImport reads one JSON object with name and email per line.
+// Blank lines are skipped and lines that are not such an object are
+// reported as invalid rows.
+func parseNDJSONImport(body io.Reader) ([]*dto.ImportUserRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), maxNDJSONLine)
+
+	var rows []*dto.ImportUserRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var fields struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		}
+		row := &dto.ImportUserRow{Line: line}
+		if err := json.Unmarshal([]byte(text), &fields); err != nil {
+			row.Error = "line is not a JSON object with name and email"
+		} else {
+			row.Name = fields.Name
+			row.Email = fields.Email
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, fmt.Errorf("NDJSON lines must not exceed %d bytes", maxNDJSONLine)
+		}
+		return nil, err
+	}
+	return rows, nil
+}
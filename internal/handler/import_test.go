@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserHandler_ImportUsers(t *testing.T) {
+	newRequest := func(contentType, body string) *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/users/import?mode=best_effort", strings.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		return request
+	}
+
+	t.Run("should parse CSV rows with line numbers", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expected := dto.ImportUsersRequest{
+			Mode: "best_effort",
+			Rows: []*dto.ImportUserRow{
+				{Line: 2, Name: "John Doe", Email: "john@example.com"},
+				{Line: 3, Error: "expected 2 fields, got 1"},
+				{Line: 4, Name: "Jane, Doe", Email: "jane@example.com"},
+			},
+		}
+		report := &dto.ImportUsersResponse{Mode: "best_effort", Committed: true, Total: 3, Created: 2, Invalid: 1}
+		mockUsecase.On("ImportUsers", mock.Anything, expected).Return(report, nil)
+
+		recorder := httptest.NewRecorder()
+		body := "email,name\njohn@example.com,John Doe\nlonely\njane@example.com,\"Jane, Doe\"\n"
+		handler.ImportUsers(recorder, newRequest("text/csv; charset=utf-8", body))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		var response dto.ImportUsersResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, 2, response.Created)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should parse NDJSON rows and skip blank lines", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expected := dto.ImportUsersRequest{
+			Mode: "best_effort",
+			Rows: []*dto.ImportUserRow{
+				{Line: 1, Name: "John Doe", Email: "john@example.com"},
+				{Line: 3, Error: "line is not a JSON object with name and email"},
+			},
+		}
+		mockUsecase.On("ImportUsers", mock.Anything, expected).Return(&dto.ImportUsersResponse{Committed: true}, nil)
+
+		recorder := httptest.NewRecorder()
+		body := "{\"name\":\"John Doe\",\"email\":\"john@example.com\"}\n\n[1, 2]\n"
+		handler.ImportUsers(recorder, newRequest(dto.NDJSONContentType, body))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should respond 422 when an all-or-nothing import is rejected", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("ImportUsers", mock.Anything, mock.Anything).Return(&dto.ImportUsersResponse{Committed: false, Conflicts: 1}, nil)
+
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest(dto.CSVContentType, "name,email\nJohn Doe,john@example.com\n"))
+
+		assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+		assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	})
+
+	t.Run("should reject CSV without name and email columns", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest(dto.CSVContentType, "full_name,mail\nJohn Doe,john@example.com\n"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		var problem dto.ProblemDetails
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+		assert.Equal(t, entities.CodeInvalidImport, problem.Code)
+		mockUsecase.AssertNotCalled(t, "ImportUsers", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject malformed CSV", func(t *testing.T) {
+		handler := NewUserHandler(new(MockUserUsecase))
+
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest(dto.CSVContentType, "name,email\n\"John,john@example.com\n"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), "CSV line")
+	})
+
+	t.Run("should reject unsupported content type", func(t *testing.T) {
+		handler := NewUserHandler(new(MockUserUsecase))
+
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest("application/json", "[]"))
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+	})
+
+	t.Run("should reject oversized bodies", func(t *testing.T) {
+		handler := NewUserHandler(new(MockUserUsecase))
+
+		line := "{\"name\":\"John Doe\",\"email\":\"john@example.com\"}\n"
+		body := strings.Repeat(line, MaxImportBytes/len(line)+1)
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest(dto.NDJSONContentType, body))
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+	})
+
+	t.Run("should map usecase validation errors", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("ImportUsers", mock.Anything, mock.Anything).
+			Return(nil, entities.NewValidationError("mode must be all_or_nothing or best_effort", entities.ErrInvalidImport))
+
+		recorder := httptest.NewRecorder()
+		handler.ImportUsers(recorder, newRequest(dto.CSVContentType, "name,email\nJohn Doe,john@example.com\n"))
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, recorder.Body.String(), entities.CodeInvalidImport)
+	})
+}
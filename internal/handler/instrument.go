@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteMatcher finds the route a request is dispatched to, as *mux.Router does
+type RouteMatcher interface {
+	Match(r *http.Request, match *mux.RouteMatch) bool
+}
+
+// RequestInfo describes a served request
+type RequestInfo struct {
+	Method string
+	// Route is the matched route template, or "" when no route matched
+	Route    string
+	Status   int
+	Bytes    int64
+	Duration time.Duration
+}
+
+// RequestObserver is told about every request once it has been served
+type RequestObserver interface {
+	ObserveRequest(ctx context.Context, info RequestInfo)
+}
+
+// RequestObserverFunc adapts a function to RequestObserver
+type RequestObserverFunc func(ctx context.Context, info RequestInfo)
+
+func (f RequestObserverFunc) ObserveRequest(ctx context.Context, info RequestInfo) {
+	f(ctx, info)
+}
+
+// Instrument reports every request to the observers with its route
+// template, status, size and duration. It wraps the whole router, so
+// requests that match no route are reported too, with an empty route.
+func Instrument(routes RouteMatcher, observers ...RequestObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info := RequestInfo{Method: r.Method}
+			var match mux.RouteMatch
+			if routes.Match(r, &match) && match.Route != nil {
+				info.Route, _ = match.Route.GetPathTemplate()
+			}
+
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			// Deferred so aborted responses are reported before the panic unwinds
+			defer func() {
+				info.Status, info.Bytes, info.Duration = recorder.status, recorder.bytes, time.Since(start)
+				for _, observer := range observers {
+					observer.ObserveRequest(r.Context(), info)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+		})
+	}
+}
+
+// AccessLogger logs one line per request with its method, route template,
+// status, latency and response size
+func AccessLogger(logger *slog.Logger) RequestObserver {
+	return RequestObserverFunc(func(ctx context.Context, info RequestInfo) {
+		logger.LogAttrs(ctx, slog.LevelInfo, "request",
+			slog.String("method", info.Method),
+			slog.String("route", info.Route),
+			slog.Int("status", info.Status),
+			slog.Float64("latency_ms", float64(info.Duration.Microseconds())/1000),
+			slog.Int64("bytes", info.Bytes),
+		)
+	})
+}
+
+// statusRecorder captures the status and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status, s.wroteHeader = status, true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets streaming responses flush through the recorder
+func (s *statusRecorder) Flush() {
+	_ = http.NewResponseController(s.ResponseWriter).Flush()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
+
+// logInternalError records an error the client only sees as "Internal
+// server error", with the cause a DomainError wraps
+func logInternalError(r *http.Request, err error) {
+	attrs := []any{"method", r.Method, "path", r.URL.Path, "error", err}
+	var domainErr *entities.DomainError
+	if errors.As(err, &domainErr) && domainErr.Cause != nil {
+		attrs = append(attrs, "cause", domainErr.Cause)
+	}
+	slog.ErrorContext(r.Context(), "Internal error", attrs...)
+}
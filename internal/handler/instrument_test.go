@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/logging"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeLog returns the records written to buf
+func decodeLog(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var record map[string]any
+		assert.NoError(t, decoder.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestInstrument(t *testing.T) {
+	var buf bytes.Buffer
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}).Methods(http.MethodGet)
+	var observed RequestInfo
+	logged := RequestID(Instrument(router,
+		AccessLogger(logging.New(&buf, slog.LevelInfo)),
+		RequestObserverFunc(func(ctx context.Context, info RequestInfo) { observed = info }),
+	)(router))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedRoute  string
+		expectedStatus int
+		expectedBytes  float64
+	}{
+		{"should log the route template", http.MethodGet, "/users/42", "/users/{id}", http.StatusCreated, 5},
+		{"should log requests matching no route", http.MethodGet, "/missing", "", http.StatusNotFound, 19},
+		{"should log requests with the wrong method", http.MethodPost, "/users/42", "", http.StatusMethodNotAllowed, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			request := httptest.NewRequest(tt.method, tt.path, nil)
+			request.Header.Set(RequestIDHeader, "req-42")
+			recorder := httptest.NewRecorder()
+
+			logged.ServeHTTP(recorder, request)
+
+			records := decodeLog(t, &buf)
+			if assert.Len(t, records, 1) {
+				record := records[0]
+				assert.Equal(t, "request", record["msg"])
+				assert.Equal(t, tt.method, record["method"])
+				assert.Equal(t, tt.expectedRoute, record["route"])
+				assert.Equal(t, float64(tt.expectedStatus), record["status"])
+				assert.Equal(t, tt.expectedBytes, record["bytes"])
+				assert.Contains(t, record, "latency_ms")
+				assert.Equal(t, "req-42", record[logging.RequestIDKey])
+			}
+			assert.Equal(t, tt.expectedRoute, observed.Route)
+			assert.Equal(t, tt.expectedStatus, observed.Status)
+		})
+	}
+}
+
+func TestWriteError_LogsInternalErrors(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, slog.LevelInfo))
+	defer slog.SetDefault(previous)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	request = request.WithContext(entities.ContextWithRequestID(request.Context(), "req-7"))
+
+	t.Run("should log the cause behind an internal error", func(t *testing.T) {
+		buf.Reset()
+		recorder := httptest.NewRecorder()
+
+		writeError(recorder, request, entities.NewInternalError("failed to get user", errors.New("connection reset by peer")))
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.NotContains(t, recorder.Body.String(), "connection reset")
+		records := decodeLog(t, &buf)
+		if assert.Len(t, records, 1) {
+			assert.Equal(t, "ERROR", records[0]["level"])
+			assert.Equal(t, "connection reset by peer", records[0]["cause"])
+			assert.Equal(t, "req-7", records[0][logging.RequestIDKey])
+			assert.Equal(t, "/api/v1/users/1", records[0]["path"])
+		}
+	})
+
+	t.Run("should not log client errors", func(t *testing.T) {
+		buf.Reset()
+
+		writeError(httptest.NewRecorder(), request, entities.NewNotFoundError("user not found", entities.ErrUserNotFound))
+
+		assert.Empty(t, buf.String())
+	})
+}
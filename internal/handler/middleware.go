@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// RequestIDHeader carries the request ID between clients and this service
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds caller-supplied request IDs before they are stored
+const maxRequestIDLength = 128
+
+// AdminActor is the principal recorded for requests authenticated by RequireBearerToken
+const AdminActor = "admin"
+
+// RequestID propagates the caller's X-Request-ID, or assigns a new one when
+// it is missing or too long, and stores it in the request context and the
+// response headers
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(entities.ContextWithRequestID(r.Context(), requestID)))
+	})
+}
+
+// RequireBearerToken rejects requests whose Authorization header does not
+// carry token as a bearer credential, and records accepted requests as
+// AdminActor acting with the admin role
+func RequireBearerToken(token string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+				writeProblem(w, r, http.StatusUnauthorized, entities.UnauthorizedError, CodeUnauthorized, "A valid admin bearer token is required")
+				return
+			}
+			ctx := entities.ContextWithActor(r.Context(), AdminActor)
+			ctx = entities.ContextWithPrincipal(ctx, entities.Principal{Role: entities.RoleAdmin})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyHeader carries an API key as an alternative to the Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// AccessTokenVerifier returns the user an access token was issued to
+type AccessTokenVerifier interface {
+	VerifyAccessToken(token string) (uuid.UUID, error)
+}
+
+// APIKeyAuthenticator returns the active API key matching key
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*entities.APIKey, error)
+}
+
+// Authenticate rejects requests without a valid credential: an API key in
+// X-API-Key, or a bearer API key or access token. Requests with an access
+// token carry its user in the context as both the authenticated user ID and
+// the actor; requests with an API key carry the key as the actor, may do
+// what its scopes grant and are limited to those scopes. Either kind of credential is
+// rejected when its verifier is nil.
+func Authenticate(verifier AccessTokenVerifier, apiKeys APIKeyAuthenticator) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := r.Header.Get(APIKeyHeader)
+			isAPIKey := credential != ""
+			if !isAPIKey {
+				token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+				if !ok || token == "" {
+					w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+					writeProblem(w, r, http.StatusUnauthorized, entities.UnauthorizedError, CodeUnauthorized, "A bearer access token or API key is required")
+					return
+				}
+				credential, isAPIKey = token, entities.IsAPIKey(token)
+			}
+
+			if isAPIKey {
+				if apiKeys == nil {
+					writeInvalidCredential(w, r, entities.ErrInvalidAPIKey, "API keys are not accepted")
+					return
+				}
+				key, err := apiKeys.AuthenticateAPIKey(r.Context(), credential)
+				if err != nil {
+					if entities.IsUnauthorizedError(err) {
+						writeInvalidCredential(w, r, err, "The API key is invalid, expired or revoked")
+					} else {
+						writeError(w, r, err)
+					}
+					return
+				}
+
+				ctx := entities.ContextWithScopes(r.Context(), key.Scopes)
+				ctx = entities.ContextWithActor(ctx, entities.APIKeyActor(key.ID))
+				ctx = entities.ContextWithPrincipal(ctx, entities.APIKeyPrincipal(key.Scopes))
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			if verifier == nil {
+				writeInvalidCredential(w, r, entities.ErrInvalidToken, "Access tokens are not accepted")
+				return
+			}
+			userID, err := verifier.VerifyAccessToken(credential)
+			if err != nil {
+				writeInvalidCredential(w, r, err, "The access token is invalid or expired")
+				return
+			}
+
+			ctx := entities.ContextWithUserID(r.Context(), userID)
+			ctx = entities.ContextWithActor(ctx, entities.UserActor(userID))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// writeInvalidCredential writes the 401 problem for a credential that was
+// presented but rejected
+func writeInvalidCredential(w http.ResponseWriter, r *http.Request, err error, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+	writeProblem(w, r, http.StatusUnauthorized, entities.UnauthorizedError, entities.ErrorCode(err), detail)
+}
+
+// RequireScope rejects requests limited to scopes that do not include scope.
+// Requests that are not limited by scopes pass through.
+func RequireScope(scope string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if scopes, limited := entities.ScopesFromContext(r.Context()); limited && !slices.Contains(scopes, scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api", error="insufficient_scope", scope="`+scope+`"`)
+				writeProblem(w, r, http.StatusForbidden, entities.ForbiddenError, CodeInsufficientScope, "The API key lacks the "+scope+" scope")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
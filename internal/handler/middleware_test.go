@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	var actor string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor = entities.ActorFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	protected := RequireBearerToken("s3cret")(next)
+
+	tests := []struct {
+		name          string
+		authorization string
+		expectedCode  int
+	}{
+		{"should allow matching token", "Bearer s3cret", http.StatusNoContent},
+		{"should reject missing header", "", http.StatusUnauthorized},
+		{"should reject wrong token", "Bearer guess", http.StatusUnauthorized},
+		{"should reject other schemes", "Basic s3cret", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/users/1", nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			recorder := httptest.NewRecorder()
+
+			protected.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedCode == http.StatusUnauthorized {
+				assert.Equal(t, CodeUnauthorized, decodeProblem(t, recorder).Code)
+			} else {
+				assert.Equal(t, AdminActor, actor)
+			}
+		})
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	var seen string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = entities.RequestIDFromContext(r.Context())
+	})
+	handler := RequestID(next)
+
+	t.Run("should propagate caller request ID", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		request.Header.Set(RequestIDHeader, "req-42")
+		recorder := httptest.NewRecorder()
+
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, "req-42", seen)
+		assert.Equal(t, "req-42", recorder.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("should assign request ID when missing or too long", func(t *testing.T) {
+		for _, provided := range []string{"", strings.Repeat("x", maxRequestIDLength+1)} {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			request.Header.Set(RequestIDHeader, provided)
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, request)
+
+			_, err := uuid.Parse(seen)
+			assert.NoError(t, err)
+			assert.Equal(t, seen, recorder.Header().Get(RequestIDHeader))
+		}
+	})
+}
+
+// stubVerifier accepts a single token issued to userID
+type stubVerifier struct {
+	token  string
+	userID uuid.UUID
+}
+
+func (v stubVerifier) VerifyAccessToken(token string) (uuid.UUID, error) {
+	if token != v.token {
+		return uuid.Nil, entities.NewUnauthorizedError("invalid access token", entities.ErrInvalidToken)
+	}
+	return v.userID, nil
+}
+
+// stubAPIKeys accepts a single API key
+type stubAPIKeys struct {
+	key *entities.APIKey
+}
+
+func (s stubAPIKeys) AuthenticateAPIKey(ctx context.Context, key string) (*entities.APIKey, error) {
+	if entities.HashAPIKey(key) != s.key.KeyHash {
+		return nil, entities.NewUnauthorizedError("API key not found", entities.ErrInvalidAPIKey)
+	}
+	return s.key, nil
+}
+
+func TestAuthenticate(t *testing.T) {
+	userID := uuid.New()
+	apiKey, key, err := entities.NewAPIKey("billing", []string{entities.ScopeUsersRead}, nil)
+	assert.NoError(t, err)
+
+	var (
+		seen    uuid.UUID
+		actor   string
+		scopes  []string
+		limited bool
+	)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = entities.UserIDFromContext(r.Context())
+		actor = entities.ActorFromContext(r.Context())
+		scopes, limited = entities.ScopesFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+	verifier := stubVerifier{token: "valid", userID: userID}
+	protected := Authenticate(verifier, stubAPIKeys{key: apiKey})(next)
+
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		expectedCode  int
+		expectedError string
+		expectedActor string
+	}{
+		{"should allow valid token", map[string]string{"Authorization": "Bearer valid"}, http.StatusNoContent, "", entities.UserActor(userID)},
+		{"should allow bearer API key", map[string]string{"Authorization": "Bearer " + key}, http.StatusNoContent, "", entities.APIKeyActor(apiKey.ID)},
+		{"should allow API key header", map[string]string{APIKeyHeader: key}, http.StatusNoContent, "", entities.APIKeyActor(apiKey.ID)},
+		{"should reject missing header", nil, http.StatusUnauthorized, CodeUnauthorized, ""},
+		{"should reject other schemes", map[string]string{"Authorization": "Basic valid"}, http.StatusUnauthorized, CodeUnauthorized, ""},
+		{"should reject invalid token", map[string]string{"Authorization": "Bearer forged"}, http.StatusUnauthorized, entities.CodeInvalidToken, ""},
+		{"should reject invalid API key", map[string]string{APIKeyHeader: entities.APIKeyPrefix + "forged"}, http.StatusUnauthorized, entities.CodeInvalidAPIKey, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen, actor, scopes, limited = uuid.Nil, "", nil, false
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			for name, value := range tt.headers {
+				request.Header.Set(name, value)
+			}
+			recorder := httptest.NewRecorder()
+
+			protected.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedError != "" {
+				assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), "Bearer")
+				assert.Equal(t, tt.expectedError, decodeProblem(t, recorder).Code)
+				return
+			}
+			assert.Equal(t, tt.expectedActor, actor)
+			if tt.expectedActor == entities.UserActor(userID) {
+				assert.Equal(t, userID, seen)
+				assert.False(t, limited)
+			} else {
+				assert.Equal(t, uuid.Nil, seen)
+				assert.True(t, limited)
+				assert.Equal(t, apiKey.Scopes, scopes)
+			}
+		})
+	}
+
+	t.Run("should reject credentials without a verifier", func(t *testing.T) {
+		tests := []struct {
+			middleware    func(http.Handler) http.Handler
+			credential    string
+			expectedError string
+		}{
+			{Authenticate(nil, stubAPIKeys{key: apiKey}), "valid", entities.CodeInvalidToken},
+			{Authenticate(verifier, nil), key, entities.CodeInvalidAPIKey},
+		}
+		for _, tt := range tests {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+			request.Header.Set("Authorization", "Bearer "+tt.credential)
+			recorder := httptest.NewRecorder()
+
+			tt.middleware(next).ServeHTTP(recorder, request)
+
+			assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			assert.Equal(t, tt.expectedError, decodeProblem(t, recorder).Code)
+		}
+	})
+}
+
+func TestRequireScope(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	protected := RequireScope(entities.ScopeUsersWrite)(next)
+
+	tests := []struct {
+		name         string
+		ctx          context.Context
+		expectedCode int
+	}{
+		{"should allow requests not limited by scopes", context.Background(), http.StatusNoContent},
+		{"should allow granted scope", entities.ContextWithScopes(context.Background(), []string{entities.ScopeUsersRead, entities.ScopeUsersWrite}), http.StatusNoContent},
+		{"should forbid missing scope", entities.ContextWithScopes(context.Background(), []string{entities.ScopeUsersRead}), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPut, "/api/v1/users/1", nil).WithContext(tt.ctx)
+			recorder := httptest.NewRecorder()
+
+			protected.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+			if tt.expectedCode == http.StatusForbidden {
+				assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+				problem := decodeProblem(t, recorder)
+				assert.Equal(t, CodeInsufficientScope, problem.Code)
+				assert.Equal(t, string(entities.ForbiddenError), problem.ErrorType)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+)
+
+// queryInt parses the integer query parameter name, which is 0 when absent,
+// writing a 400 problem naming it when it is not an integer
+func queryInt(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		writeValidationProblem(w, r, entities.CodeInvalidPagination, fmt.Sprintf("%s must be an integer", name))
+		return 0, false
+	}
+	return n, true
+}
+
+// offsetPageURL returns the request path and query positioned at offset
+func offsetPageURL(r *http.Request, limit, offset int) string {
+	u := *r.URL
+	query := u.Query()
+	query.Del("cursor")
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// cursorPageURL returns the request path and query positioned at cursor
+func cursorPageURL(r *http.Request, limit int, cursor string) string {
+	u := *r.URL
+	query := u.Query()
+	query.Del("offset")
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("cursor", cursor)
+	u.RawQuery = query.Encode()
+	return u.RequestURI()
+}
+
+// setPaginationLinks fills the next/prev links of resp and writes the
+// RFC 8288 Link and X-Total-Count headers. Cursor requests only get
+// first and next links since a keyset page cannot be addressed backwards.
+func setPaginationLinks(w http.ResponseWriter, r *http.Request, resp *dto.ListUsersResponse) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(resp.Total))
+	if resp.Limit <= 0 {
+		return
+	}
+
+	var links []string
+
+	links = append(links, fmt.Sprintf(`<%s>; rel="first"`, offsetPageURL(r, resp.Limit, 0)))
+
+	if r.URL.Query().Get("cursor") != "" {
+		if resp.NextCursor != "" {
+			resp.Next = cursorPageURL(r, resp.Limit, resp.NextCursor)
+			links = append(links, fmt.Sprintf(`<%s>; rel="next"`, resp.Next))
+		}
+		w.Header().Set("Link", strings.Join(links, ", "))
+		return
+	}
+
+	if resp.Offset > 0 {
+		prevOffset := resp.Offset - resp.Limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		resp.Prev = offsetPageURL(r, resp.Limit, prevOffset)
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, resp.Prev))
+	}
+
+	if resp.Offset+resp.Limit < resp.Total {
+		resp.Next = offsetPageURL(r, resp.Limit, resp.Offset+resp.Limit)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, resp.Next))
+	}
+
+	if resp.Total > 0 {
+		lastOffset := ((resp.Total - 1) / resp.Limit) * resp.Limit
+		links = append(links, fmt.Sprintf(`<%s>; rel="last"`, offsetPageURL(r, resp.Limit, lastOffset)))
+	}
+
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/tracing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const problemContentType = "application/problem+json"
+
+// Codes for errors raised by the handler itself, before reaching the usecase
+const (
+	CodeInvalidJSON          = "INVALID_JSON"
+	CodeInvalidUserID        = "INVALID_USER_ID"
+	CodeInvalidInput         = "INVALID_INPUT"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeInvalidIfMatch       = "INVALID_IF_MATCH"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeRequestTooLarge      = "REQUEST_TOO_LARGE"
+	CodeInvalidFormat        = "INVALID_FORMAT"
+	CodeNotAcceptable        = "NOT_ACCEPTABLE"
+	CodeInsufficientScope    = "INSUFFICIENT_SCOPE"
+	CodeInvalidAPIKeyID      = "INVALID_API_KEY_ID"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+)
+
+// acceptPatch advertises the patch formats PATCH /users/{id} understands
+const acceptPatch = dto.MergePatchContentType + ", " + dto.JSONPatchContentType
+
+// writeProblem writes an RFC 7807 problem+json response
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, errorType entities.ErrorType, code, detail string) {
+	problem := dto.ProblemDetails{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		ErrorType: string(errorType),
+		Code:      code,
+	}
+
+	trace.SpanFromContext(r.Context()).SetAttributes(tracing.ErrorType(errorType))
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// writeValidationProblem writes a 400 problem for malformed client input
+func writeValidationProblem(w http.ResponseWriter, r *http.Request, code, detail string) {
+	writeProblem(w, r, http.StatusBadRequest, entities.ValidationError, code, detail)
+}
+
+// MethodNotAllowed answers with a 405 problem listing the allowed methods
+func MethodNotAllowed(methods ...string) http.Handler {
+	allow := strings.Join(methods, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", allow)
+		writeProblem(w, r, http.StatusMethodNotAllowed, entities.ValidationError, CodeMethodNotAllowed,
+			r.Method+" is not allowed on "+r.URL.Path+", use "+allow)
+	})
+}
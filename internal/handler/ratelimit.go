@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimiter takes a token for a client's request to a route
+type RateLimiter interface {
+	Allow(ctx context.Context, method, route, client string) (ratelimit.Limit, ratelimit.Result, error)
+}
+
+type rateLimitConfig struct {
+	trustForwardedFor bool
+}
+
+// RateLimitOption configures optional RateLimit behaviour
+type RateLimitOption func(*rateLimitConfig)
+
+// WithTrustForwardedFor identifies anonymous clients by the last address in
+// X-Forwarded-For, as appended by a trusted reverse proxy, instead of the
+// connection's remote address
+func WithTrustForwardedFor(trust bool) RateLimitOption {
+	return func(c *rateLimitConfig) {
+		c.trustForwardedFor = trust
+	}
+}
+
+// RateLimit rejects requests beyond the client's quota for the matched route
+// with 429 Too Many Requests and Retry-After, and reports the quota in
+// RateLimit-* headers. Clients are identified by the actor authenticated
+// before it runs, such as a user or API key, or else by their IP address.
+// Requests are let through when the limiter fails.
+func RateLimit(limiter RateLimiter, opts ...RateLimitOption) mux.MiddlewareFunc {
+	config := &rateLimitConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			limit, result, err := limiter.Allow(r.Context(), r.Method, route, rateLimitClient(r, config.trustForwardedFor))
+			if err != nil {
+				slog.WarnContext(r.Context(), "Rate limiter failed, allowing request", "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if limit.IsZero() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			w.Header().Set("RateLimit-Policy", strconv.Itoa(limit.Requests)+";w="+strconv.Itoa(ceilSeconds(limit.Period)))
+			if !result.Allowed {
+				retryAfter := max(ceilSeconds(result.RetryAfter), 1)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				writeProblem(w, r, http.StatusTooManyRequests, entities.RateLimitedError, CodeRateLimited,
+					"Rate limit of "+limit.String()+" exceeded, retry in "+strconv.Itoa(retryAfter)+"s")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// authFailuresRoute names the bucket LimitAuthFailures counts failures in
+const authFailuresRoute = "auth failures"
+
+// LimitAuthFailures counts every 401 Unauthorized response against the
+// client's IP address. Once an address has failed more often than the
+// limiter allows, its requests are rejected with 429 Too Many Requests
+// before reaching next until a token is available again, so credentials
+// cannot be guessed faster than the limit whatever other limits apply. It
+// belongs in front of the middleware that authenticates. Failures are not
+// counted when the limiter fails.
+func LimitAuthFailures(limiter RateLimiter, opts ...RateLimitOption) mux.MiddlewareFunc {
+	config := &rateLimitConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	blocked := &blocklist{until: make(map[string]time.Time)}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			client := clientAddress(r, config.trustForwardedFor)
+			if wait := blocked.remaining(client, time.Now()); wait > 0 {
+				retryAfter := max(ceilSeconds(wait), 1)
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+				writeProblem(w, r, http.StatusTooManyRequests, entities.RateLimitedError, CodeRateLimited,
+					"Too many failed authentication attempts, retry in "+strconv.Itoa(retryAfter)+"s")
+				return
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r)
+			if recorder.status != http.StatusUnauthorized {
+				return
+			}
+
+			limit, result, err := limiter.Allow(r.Context(), "", authFailuresRoute, client)
+			if err != nil {
+				slog.WarnContext(r.Context(), "Rate limiter failed, not counting authentication failure", "error", err)
+				return
+			}
+			switch {
+			case limit.IsZero():
+			case !result.Allowed:
+				blocked.add(client, time.Now().Add(result.RetryAfter))
+			case result.Remaining == 0:
+				// This failure took the last token; wait for the next one
+				blocked.add(client, time.Now().Add(limit.Period/time.Duration(limit.Requests)))
+			}
+		})
+	}
+}
+
+// blocklist holds the addresses LimitAuthFailures rejects until a deadline
+type blocklist struct {
+	mu    sync.Mutex
+	until map[string]time.Time
+}
+
+// remaining returns how long client is still blocked for
+func (b *blocklist) remaining(client string, now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.until[client].Sub(now)
+}
+
+// add blocks client until deadline and forgets addresses no longer blocked
+func (b *blocklist) add(client string, deadline time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	for address, until := range b.until {
+		if !until.After(now) {
+			delete(b.until, address)
+		}
+	}
+	b.until[client] = deadline
+}
+
+// rateLimitClient names the client a request is counted against
+func rateLimitClient(r *http.Request, trustForwardedFor bool) string {
+	if actor := entities.ActorFromContext(r.Context()); actor != entities.AnonymousActor {
+		return actor
+	}
+	return clientAddress(r, trustForwardedFor)
+}
+
+// clientAddress names a client by its IP address
+func clientAddress(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			hops := strings.Split(forwarded, ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return "ip:" + ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
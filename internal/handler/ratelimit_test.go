@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/ratelimit"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingLimiter stands in for a store that cannot be reached
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, method, route, client string) (ratelimit.Limit, ratelimit.Result, error) {
+	return ratelimit.Limit{}, ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestRateLimit(t *testing.T) {
+	newRouter := func(limiter RateLimiter, opts ...RateLimitOption) *mux.Router {
+		router := mux.NewRouter()
+		router.Use(RateLimit(limiter, opts...))
+		router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		return router
+	}
+	get := func(router http.Handler, path, remoteAddr string, prepare func(*http.Request) *http.Request) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		request.RemoteAddr = remoteAddr
+		if prepare != nil {
+			request = prepare(request)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("should limit each client per route template", func(t *testing.T) {
+		router := newRouter(ratelimit.NewLimiter(ratelimit.WithRouteLimits(map[string]ratelimit.Limit{
+			"GET /users/{id}": {Requests: 2, Period: time.Minute},
+		})))
+
+		recorder := get(router, "/users/1", "10.0.0.1:1234", nil)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", recorder.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", recorder.Header().Get("RateLimit-Policy"))
+
+		assert.Equal(t, http.StatusNoContent, get(router, "/users/2", "10.0.0.1:1234", nil).Code)
+
+		recorder = get(router, "/users/3", "10.0.0.1:5678", nil)
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+		assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+		problem := decodeProblem(t, recorder)
+		assert.Equal(t, CodeRateLimited, problem.Code)
+		assert.Equal(t, string(entities.RateLimitedError), problem.ErrorType)
+
+		assert.Equal(t, http.StatusNoContent, get(router, "/users/1", "10.0.0.2:1234", nil).Code)
+	})
+
+	t.Run("should count authenticated clients by actor", func(t *testing.T) {
+		router := newRouter(ratelimit.NewLimiter(ratelimit.WithDefaultLimit(ratelimit.Limit{Requests: 1, Period: time.Minute})))
+		as := func(actor string) func(*http.Request) *http.Request {
+			return func(r *http.Request) *http.Request {
+				return r.WithContext(entities.ContextWithActor(r.Context(), actor))
+			}
+		}
+
+		assert.Equal(t, http.StatusNoContent, get(router, "/users/1", "10.0.0.1:1234", as("user:1")).Code)
+		assert.Equal(t, http.StatusNoContent, get(router, "/users/1", "10.0.0.1:1234", as("api_key:1")).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(router, "/users/1", "10.0.0.2:1234", as("user:1")).Code)
+	})
+
+	t.Run("should trust X-Forwarded-For only when configured", func(t *testing.T) {
+		limit := ratelimit.WithDefaultLimit(ratelimit.Limit{Requests: 1, Period: time.Minute})
+		forwardedFor := func(ip string) func(*http.Request) *http.Request {
+			return func(r *http.Request) *http.Request {
+				r.Header.Set("X-Forwarded-For", "203.0.113.9, "+ip)
+				return r
+			}
+		}
+
+		trusting := newRouter(ratelimit.NewLimiter(limit), WithTrustForwardedFor(true))
+		assert.Equal(t, http.StatusNoContent, get(trusting, "/users/1", "10.0.0.1:1234", forwardedFor("198.51.100.1")).Code)
+		assert.Equal(t, http.StatusNoContent, get(trusting, "/users/1", "10.0.0.1:1234", forwardedFor("198.51.100.2")).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(trusting, "/users/1", "10.0.0.1:1234", forwardedFor("198.51.100.1")).Code)
+
+		untrusting := newRouter(ratelimit.NewLimiter(limit))
+		assert.Equal(t, http.StatusNoContent, get(untrusting, "/users/1", "10.0.0.1:1234", forwardedFor("198.51.100.1")).Code)
+		assert.Equal(t, http.StatusTooManyRequests, get(untrusting, "/users/1", "10.0.0.1:1234", forwardedFor("198.51.100.2")).Code)
+	})
+
+	t.Run("should allow requests when the limiter fails", func(t *testing.T) {
+		recorder := get(newRouter(failingLimiter{}), "/users/1", "10.0.0.1:1234", nil)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("RateLimit-Limit"))
+	})
+}
+
+func TestLimitAuthFailures(t *testing.T) {
+	newRouter := func(limiter RateLimiter) *mux.Router {
+		router := mux.NewRouter()
+		router.Use(LimitAuthFailures(limiter))
+		router.Use(RequireBearerToken("secret"))
+		router.HandleFunc("/admin", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		return router
+	}
+	get := func(router http.Handler, token, remoteAddr string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		request.RemoteAddr = remoteAddr
+		request.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("should reject an address that failed too often before checking its credentials", func(t *testing.T) {
+		router := newRouter(ratelimit.NewLimiter(ratelimit.WithDefaultLimit(ratelimit.Limit{Requests: 2, Period: time.Minute})))
+
+		assert.Equal(t, http.StatusNoContent, get(router, "secret", "10.0.0.1:1234").Code)
+		assert.Equal(t, http.StatusNoContent, get(router, "secret", "10.0.0.1:1234").Code)
+		assert.Equal(t, http.StatusNoContent, get(router, "secret", "10.0.0.1:1234").Code)
+		for i := 0; i < 2; i++ {
+			assert.Equal(t, http.StatusUnauthorized, get(router, "guess", "10.0.0.1:1234").Code)
+		}
+
+		recorder := get(router, "secret", "10.0.0.1:5678")
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+		assert.Equal(t, CodeRateLimited, decodeProblem(t, recorder).Code)
+
+		assert.Equal(t, http.StatusNoContent, get(router, "secret", "10.0.0.2:1234").Code)
+	})
+
+	t.Run("should not count failures when the limiter fails", func(t *testing.T) {
+		router := newRouter(failingLimiter{})
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, get(router, "guess", "10.0.0.1:1234").Code)
+		}
+		assert.Equal(t, http.StatusNoContent, get(router, "secret", "10.0.0.1:1234").Code)
+	})
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the request spans
+const tracerName = "go-clean-code/internal/handler"
+
+// Trace serves every request in a server span named after its method and
+// route template, continuing the trace of a W3C traceparent header when
+// there is one. UserHandler adds the ID of the user the request addresses,
+// and problem responses their error type. Spans come from the global tracer
+// provider.
+func Trace(routes RouteMatcher) func(http.Handler) http.Handler {
+	propagator := propagation.TraceContext{}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			name := r.Method
+			opts := []trace.SpanStartOption{
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+				),
+			}
+			var match mux.RouteMatch
+			if routes.Match(r, &match) && match.Route != nil {
+				if route, err := match.Route.GetPathTemplate(); err == nil {
+					name += " " + route
+					opts = append(opts, trace.WithAttributes(semconv.HTTPRoute(route)))
+				}
+			}
+
+			ctx, span := otel.Tracer(tracerName).Start(ctx, name, opts...)
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			// Deferred so aborted responses end their span before the panic unwinds
+			defer func() {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+				if recorder.status >= http.StatusInternalServerError {
+					span.SetStatus(codes.Error, http.StatusText(recorder.status))
+				}
+				span.End()
+			}()
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/tracing/tracingtest"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTrace(t *testing.T) {
+	exporter := tracingtest.Record(t)
+
+	mockUsecase := new(MockUserUsecase)
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{id}", NewUserHandler(mockUsecase).GetUser).Methods("GET")
+	server := Trace(router)(router)
+
+	serve := func(path string, header http.Header) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, path, nil)
+		for key, values := range header {
+			request.Header[key] = values
+		}
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("should continue the caller's trace", func(t *testing.T) {
+		exporter.Reset()
+		userID := uuid.New()
+		traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+		inTrace := mock.MatchedBy(func(ctx context.Context) bool {
+			return trace.SpanContextFromContext(ctx).TraceID() == traceID
+		})
+		mockUsecase.On("GetUser", inTrace, userID).
+			Return(nil, entities.NewNotFoundError("user not found", entities.ErrUserNotFound)).Once()
+
+		recorder := serve("/users/"+userID.String(), http.Header{
+			"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		})
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		spans := exporter.GetSpans()
+		if assert.Len(t, spans, 1) {
+			span := spans[0]
+			assert.Equal(t, "GET /users/{id}", span.Name)
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+			assert.Equal(t, traceID, span.SpanContext.TraceID())
+			assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+			assert.True(t, span.Parent.IsRemote())
+			// Client errors are the caller's, not the server's
+			assert.Equal(t, codes.Unset, span.Status.Code)
+
+			attrs := tracingtest.Attributes(&span)
+			assert.Equal(t, "GET", attrs["http.request.method"])
+			assert.Equal(t, "/users/{id}", attrs["http.route"])
+			assert.Equal(t, "404", attrs["http.response.status_code"])
+			assert.Equal(t, userID.String(), attrs["user.id"])
+			assert.Equal(t, string(entities.NotFoundError), attrs["error.type"])
+		}
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should start a trace and fail on server errors", func(t *testing.T) {
+		exporter.Reset()
+		userID := uuid.New()
+		mockUsecase.On("GetUser", mock.Anything, userID).
+			Return(nil, entities.NewInternalError("failed to get user by ID", nil)).Once()
+
+		recorder := serve("/users/"+userID.String(), nil)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		spans := exporter.GetSpans()
+		if assert.Len(t, spans, 1) {
+			assert.False(t, spans[0].Parent.IsValid())
+			assert.Equal(t, codes.Error, spans[0].Status.Code)
+			assert.Equal(t, string(entities.InternalError), tracingtest.Attributes(&spans[0])["error.type"])
+		}
+	})
+
+	t.Run("should trace requests that match no route", func(t *testing.T) {
+		exporter.Reset()
+
+		recorder := serve("/nope", nil)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+		spans := exporter.GetSpans()
+		if assert.Len(t, spans, 1) {
+			assert.Equal(t, "GET", spans[0].Name)
+			attrs := tracingtest.Attributes(&spans[0])
+			assert.NotContains(t, attrs, "http.route")
+			assert.Equal(t, "/nope", attrs["url.path"])
+		}
+	})
+}
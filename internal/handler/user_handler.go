@@ -2,48 +2,72 @@ package handler
 
 import (
 	"encoding/json"
+	"mime"
 	"net/http"
 	"strconv"
 
 	"go-clean-code/internal/dto"
 	"go-clean-code/internal/entities"
+	"go-clean-code/internal/tracing"
 	"go-clean-code/internal/usecase"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type UserHandler struct {
-	userUsecase usecase.UserUsecaseInterface
+	userUsecase    usecase.UserUsecaseInterface
+	requireIfMatch bool
 }
 
-func NewUserHandler(userUsecase usecase.UserUsecaseInterface) *UserHandler {
-	return &UserHandler{
+// UserHandlerOption configures optional UserHandler behaviour
+type UserHandlerOption func(*UserHandler)
+
+// WithRequireIfMatch rejects PUT, PATCH and DELETE requests without an
+// If-Match header with 428 Precondition Required
+func WithRequireIfMatch(required bool) UserHandlerOption {
+	return func(h *UserHandler) {
+		h.requireIfMatch = required
+	}
+}
+
+func NewUserHandler(userUsecase usecase.UserUsecaseInterface, opts ...UserHandlerOption) *UserHandler {
+	h := &UserHandler{
 		userUsecase: userUsecase,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// handleError handles domain errors and maps them to appropriate HTTP responses
-func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
+// writeError maps err to a problem+json response
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
 	switch err {
 	case usecase.ErrInvalidInput:
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, entities.ValidationError, CodeInvalidInput, err.Error())
 	case usecase.ErrEmailExists:
-		http.Error(w, err.Error(), http.StatusConflict)
+		writeProblem(w, r, http.StatusConflict, entities.ConflictError, entities.CodeEmailAlreadyUsed, err.Error())
 	case usecase.ErrUserNotFound:
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeProblem(w, r, http.StatusNotFound, entities.NotFoundError, entities.CodeUserNotFound, err.Error())
 	default:
 		switch {
 		case entities.IsValidationError(err):
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, entities.ValidationError, entities.ErrorCode(err), err.Error())
 		case entities.IsNotFoundError(err):
-			http.Error(w, err.Error(), http.StatusNotFound)
+			writeProblem(w, r, http.StatusNotFound, entities.NotFoundError, entities.ErrorCode(err), err.Error())
 		case entities.IsConflictError(err):
-			http.Error(w, err.Error(), http.StatusConflict)
-		case entities.IsInternalError(err):
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			writeProblem(w, r, http.StatusConflict, entities.ConflictError, entities.ErrorCode(err), err.Error())
+		case entities.IsPreconditionFailedError(err):
+			writeProblem(w, r, http.StatusPreconditionFailed, entities.PreconditionFailedError, entities.ErrorCode(err), err.Error())
+		case entities.IsUnauthorizedError(err):
+			writeProblem(w, r, http.StatusUnauthorized, entities.UnauthorizedError, entities.ErrorCode(err), err.Error())
+		case entities.IsForbiddenError(err):
+			writeProblem(w, r, http.StatusForbidden, entities.ForbiddenError, entities.ErrorCode(err), err.Error())
 		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logInternalError(r, err)
+			writeProblem(w, r, http.StatusInternalServerError, entities.InternalError, string(entities.InternalError), "Internal server error")
 		}
 	}
 }
@@ -51,73 +75,218 @@ func (h *UserHandler) handleError(w http.ResponseWriter, err error) {
 func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	var req dto.CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
 		return
 	}
 
 	user, err := h.userUsecase.CreateUser(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, user)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
 }
 
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	id, ok := pathUserID(w, r)
+	if !ok {
 		return
 	}
 
 	user, err := h.userUsecase.GetUser(r.Context(), id)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, user)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
 
 func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["id"])
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(w, r)
+	if !ok {
 		return
 	}
 
 	var req dto.UpdateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
 		return
 	}
+	req.ExpectedVersion = expectedVersion
 
 	user, err := h.userUsecase.UpdateUser(r.Context(), id, req)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangeUserRole gives the user the role in the body, honouring If-Match
+func (h *UserHandler) ChangeUserRole(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangeUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	req.ExpectedVersion = expectedVersion
+
+	user, err := h.userUsecase.ChangeUserRole(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// ChangePassword sets the user's password to the one in the body, honouring
+// If-Match
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeValidationProblem(w, r, CodeInvalidJSON, "Invalid JSON")
+		return
+	}
+	req.ExpectedVersion = expectedVersion
+
+	user, err := h.userUsecase.ChangePassword(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	setETag(w, user)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// PatchUser applies an RFC 7396 merge patch or an RFC 6902 JSON Patch,
+// selected by the request Content-Type
+func (h *UserHandler) PatchUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	req := dto.PatchUserRequest{ExpectedVersion: expectedVersion}
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case dto.MergePatchContentType:
+		if err := json.NewDecoder(r.Body).Decode(&req.MergePatch); err != nil || req.MergePatch == nil {
+			writeValidationProblem(w, r, CodeInvalidJSON, "Merge patch must be a JSON object")
+			return
+		}
+	case dto.JSONPatchContentType:
+		if err := json.NewDecoder(r.Body).Decode(&req.Operations); err != nil || req.Operations == nil {
+			writeValidationProblem(w, r, CodeInvalidJSON, "JSON Patch must be an array of operations")
+			return
+		}
+	default:
+		w.Header().Set("Accept-Patch", acceptPatch)
+		writeProblem(w, r, http.StatusUnsupportedMediaType, entities.ValidationError, CodeUnsupportedMediaType,
+			"Content-Type must be "+dto.MergePatchContentType+" or "+dto.JSONPatchContentType)
+		return
+	}
+
+	user, err := h.userUsecase.PatchUser(r.Context(), id, req)
+	if err != nil {
+		writeError(w, r, err)
 		return
 	}
 
+	setETag(w, user)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
 }
 
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	id, err := uuid.Parse(vars["id"])
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	expectedVersion, ok := h.ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userUsecase.DeleteUser(r.Context(), id, expectedVersion); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *UserHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.userUsecase.RestoreUser(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	if err := h.userUsecase.DeleteUser(r.Context(), id); err != nil {
-		h.handleError(w, err)
+	setETag(w, user)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// PurgeUser permanently removes a soft-deleted user. It is mounted on the admin API.
+func (h *UserHandler) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.userUsecase.PurgeUser(r.Context(), id); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
@@ -125,15 +294,128 @@ func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	query := r.URL.Query()
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+	offset, ok := queryInt(w, r, "offset")
+	if !ok {
+		return
+	}
+
+	req := dto.ListUsersRequest{
+		Limit:         limit,
+		Offset:        offset,
+		Cursor:        query.Get("cursor"),
+		Email:         query.Get("email"),
+		Name:          query.Get("name"),
+		NamePrefix:    query.Get("name_prefix"),
+		EmailDomain:   query.Get("email_domain"),
+		CreatedAfter:  query.Get("created_after"),
+		CreatedBefore: query.Get("created_before"),
+		UpdatedSince:  query.Get("updated_since"),
+		Sort:          query.Get("sort"),
+	}
 
-	users, err := h.userUsecase.ListUsers(r.Context(), limit, offset)
+	users, err := h.userUsecase.ListUsers(r.Context(), req)
 	if err != nil {
-		h.handleError(w, err)
+		writeError(w, r, err)
 		return
 	}
 
+	setPaginationLinks(w, r, users)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(users)
 }
+
+func (h *UserHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	results, err := h.userUsecase.SearchUsers(r.Context(), dto.SearchUsersRequest{
+		Query: query.Get("q"),
+		Limit: limit,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+func (h *UserHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	req, ok := auditRequest(w, r)
+	if !ok {
+		return
+	}
+	h.listAudit(w, r, req)
+}
+
+// ListUserAudit lists the changes to one user, including users since purged
+func (h *UserHandler) ListUserAudit(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	req, ok := auditRequest(w, r)
+	if !ok {
+		return
+	}
+	req.UserID = id
+	h.listAudit(w, r, req)
+}
+
+func (h *UserHandler) listAudit(w http.ResponseWriter, r *http.Request, req dto.ListAuditRequest) {
+	records, err := h.userUsecase.ListAudit(r.Context(), req)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(records.Total))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(records)
+}
+
+// auditRequest reads the audit filter and page from the query string,
+// writing a 400 problem when the page is malformed
+func auditRequest(w http.ResponseWriter, r *http.Request) (dto.ListAuditRequest, bool) {
+	query := r.URL.Query()
+	limit, ok := queryInt(w, r, "limit")
+	if !ok {
+		return dto.ListAuditRequest{}, false
+	}
+	offset, ok := queryInt(w, r, "offset")
+	if !ok {
+		return dto.ListAuditRequest{}, false
+	}
+
+	return dto.ListAuditRequest{
+		Actor:     query.Get("actor"),
+		Action:    query.Get("action"),
+		RequestID: query.Get("request_id"),
+		From:      query.Get("from"),
+		To:        query.Get("to"),
+		Limit:     limit,
+		Offset:    offset,
+	}, true
+}
+
+// pathUserID parses the {id} route variable, writing a 400 problem when it is
+// not a UUID, and labels the request's span with it
+func pathUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		writeValidationProblem(w, r, CodeInvalidUserID, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	trace.SpanFromContext(r.Context()).SetAttributes(tracing.UserID(id.String()))
+	return id, true
+}
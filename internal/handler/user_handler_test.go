@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
 	"go-clean-code/internal/usecase"
 
 	"github.com/gorilla/mux"
@@ -46,19 +47,92 @@ func (m *MockUserUsecase) UpdateUser(ctx context.Context, id uuid.UUID, req dto.
 	return args.Get(0).(*dto.UserResponse), args.Error(1)
 }
 
-func (m *MockUserUsecase) DeleteUser(ctx context.Context, id uuid.UUID) error {
+func (m *MockUserUsecase) PatchUser(ctx context.Context, id uuid.UUID, req dto.PatchUserRequest) (*dto.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserUsecase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockUserUsecase) RestoreUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserUsecase) PurgeUser(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockUserUsecase) ListUsers(ctx context.Context, limit, offset int) (*dto.ListUsersResponse, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockUserUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*dto.ListUsersResponse), args.Error(1)
 }
 
+func (m *MockUserUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.SearchUsersResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SearchUsersResponse), args.Error(1)
+}
+
+func (m *MockUserUsecase) ListAudit(ctx context.Context, req dto.ListAuditRequest) (*dto.ListAuditResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ListAuditResponse), args.Error(1)
+}
+
+func (m *MockUserUsecase) ImportUsers(ctx context.Context, req dto.ImportUsersRequest) (*dto.ImportUsersResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ImportUsersResponse), args.Error(1)
+}
+
+// ExportUsers emits the users given to Return, then returns its error
+func (m *MockUserUsecase) ExportUsers(ctx context.Context, req dto.ExportUsersRequest, emit func(*dto.ExportedUser) error) error {
+	args := m.Called(ctx, req)
+	users, _ := args.Get(0).([]*dto.ExportedUser)
+	for _, user := range users {
+		if err := emit(user); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockUserUsecase) ChangeUserRole(ctx context.Context, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
+func (m *MockUserUsecase) ChangePassword(ctx context.Context, id uuid.UUID, req dto.ChangePasswordRequest) (*dto.UserResponse, error) {
+	args := m.Called(ctx, id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.UserResponse), args.Error(1)
+}
+
 func TestUserHandler_CreateUser(t *testing.T) {
 	mockUsecase := new(MockUserUsecase)
 	handler := NewUserHandler(mockUsecase)
@@ -102,6 +176,10 @@ func TestUserHandler_CreateUser(t *testing.T) {
 		handler.CreateUser(recorder, request)
 
 		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		problem := decodeProblem(t, recorder)
+		assert.Equal(t, CodeInvalidJSON, problem.Code)
+		assert.Equal(t, string(entities.ValidationError), problem.ErrorType)
 	})
 
 	t.Run("should return bad request for invalid input", func(t *testing.T) {
@@ -256,6 +334,298 @@ func TestUserHandler_UpdateUser(t *testing.T) {
 	})
 }
 
+func TestUserHandler_PatchUser(t *testing.T) {
+	userID := uuid.New()
+	expectedResponse := &dto.UserResponse{ID: userID, Name: "John Smith", Email: "john@example.com"}
+
+	t.Run("should accept merge patch", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedRequest := dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"name": json.RawMessage(`"John Smith"`)}}
+		mockUsecase.On("PatchUser", mock.Anything, userID, expectedRequest).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`{"name":"John Smith"}`))
+		request.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PatchUser(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should accept JSON Patch", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedRequest := dto.PatchUserRequest{Operations: []dto.PatchOperation{
+			{Op: "replace", Path: "/name", Value: json.RawMessage(`"John Smith"`)},
+		}}
+		mockUsecase.On("PatchUser", mock.Anything, userID, expectedRequest).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`[{"op":"replace","path":"/name","value":"John Smith"}]`))
+		request.Header.Set("Content-Type", "application/json-patch+json")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PatchUser(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should reject unsupported content type", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`{"name":"John Smith"}`))
+		request.Header.Set("Content-Type", "application/json")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PatchUser(recorder, request)
+
+		assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+		assert.Contains(t, recorder.Header().Get("Accept-Patch"), "application/merge-patch+json")
+		assert.Equal(t, CodeUnsupportedMediaType, decodeProblem(t, recorder).Code)
+		mockUsecase.AssertNotCalled(t, "PatchUser")
+	})
+
+	t.Run("should reject merge patch that is not an object", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`["name"]`))
+		request.Header.Set("Content-Type", "application/merge-patch+json")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PatchUser(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, CodeInvalidJSON, decodeProblem(t, recorder).Code)
+	})
+
+	t.Run("should map immutable path to problem code", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("PatchUser", mock.Anything, userID, mock.Anything).
+			Return(nil, entities.NewValidationError(`operation 0 (remove /id): path "/id" is immutable`, entities.ErrImmutableField))
+
+		request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`[{"op":"remove","path":"/id"}]`))
+		request.Header.Set("Content-Type", "application/json-patch+json")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PatchUser(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, entities.CodeImmutableField, decodeProblem(t, recorder).Code)
+	})
+}
+
+func TestUserHandler_ChangeUserRole(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should change role", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedResponse := &dto.UserResponse{ID: userID, Name: "John Doe", Email: "john@example.com", Role: "manager", Version: 2}
+		mockUsecase.On("ChangeUserRole", mock.Anything, userID, dto.ChangeUserRoleRequest{Role: "manager", ExpectedVersion: 1}).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodPut, "/users/"+userID.String()+"/role", bytes.NewBufferString(`{"role":"manager"}`))
+		request.Header.Set("If-Match", `"1"`)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.ChangeUserRole(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"2"`, recorder.Header().Get("ETag"))
+		var response dto.UserResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, "manager", response.Role)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return forbidden when not allowed", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("ChangeUserRole", mock.Anything, userID, dto.ChangeUserRoleRequest{Role: "admin"}).
+			Return(nil, entities.NewForbiddenError("not allowed to change_role", entities.ErrPermissionDenied))
+
+		request := httptest.NewRequest(http.MethodPut, "/users/"+userID.String()+"/role", bytes.NewBufferString(`{"role":"admin"}`))
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.ChangeUserRole(recorder, request)
+
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		problem := decodeProblem(t, recorder)
+		assert.Equal(t, entities.CodePermissionDenied, problem.Code)
+		assert.Equal(t, string(entities.ForbiddenError), problem.ErrorType)
+	})
+}
+
+func TestUserHandler_ChangePassword(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should change password", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		req := dto.ChangePasswordRequest{Password: "staple battery horse", CurrentPassword: "correct horse battery", ExpectedVersion: 1}
+		expectedResponse := &dto.UserResponse{ID: userID, Name: "John Doe", Email: "john@example.com", Role: "member", Version: 2}
+		mockUsecase.On("ChangePassword", mock.Anything, userID, req).Return(expectedResponse, nil)
+
+		body := `{"password":"staple battery horse","current_password":"correct horse battery"}`
+		request := httptest.NewRequest(http.MethodPut, "/users/"+userID.String()+"/password", bytes.NewBufferString(body))
+		request.Header.Set("If-Match", `"1"`)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.ChangePassword(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"2"`, recorder.Header().Get("ETag"))
+		assert.NotContains(t, recorder.Body.String(), "password")
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should reject a password that breaks the policy", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("ChangePassword", mock.Anything, userID, dto.ChangePasswordRequest{Password: "short"}).
+			Return(nil, entities.NewValidationError("invalid password", entities.ErrInvalidPassword))
+
+		request := httptest.NewRequest(http.MethodPut, "/users/"+userID.String()+"/password", bytes.NewBufferString(`{"password":"short"}`))
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.ChangePassword(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidPassword, decodeProblem(t, recorder).Code)
+	})
+}
+
+func TestUserHandler_ConditionalRequests(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should expose version as ETag", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("GetUser", mock.Anything, userID).Return(&dto.UserResponse{ID: userID, Version: 7}, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/users/"+userID.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.GetUser(recorder, request)
+
+		assert.Equal(t, `"7"`, recorder.Header().Get("ETag"))
+	})
+
+	t.Run("should pass If-Match version to update", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedRequest := dto.UpdateUserRequest{Name: "John Smith", ExpectedVersion: 7}
+		mockUsecase.On("UpdateUser", mock.Anything, userID, expectedRequest).Return(&dto.UserResponse{ID: userID, Version: 8}, nil)
+
+		request := httptest.NewRequest(http.MethodPut, "/users/"+userID.String(), bytes.NewBufferString(`{"name":"John Smith"}`))
+		request.Header.Set("If-Match", `"7"`)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.UpdateUser(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"8"`, recorder.Header().Get("ETag"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should map stale version to 412", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("DeleteUser", mock.Anything, userID, int64(3)).
+			Return(entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch))
+
+		request := httptest.NewRequest(http.MethodDelete, "/users/"+userID.String(), nil)
+		request.Header.Set("If-Match", `"3"`)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.DeleteUser(recorder, request)
+
+		assert.Equal(t, http.StatusPreconditionFailed, recorder.Code)
+		problem := decodeProblem(t, recorder)
+		assert.Equal(t, string(entities.PreconditionFailedError), problem.ErrorType)
+		assert.Equal(t, entities.CodeVersionMismatch, problem.Code)
+	})
+
+	t.Run("should treat wildcard as unconditional", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase, WithRequireIfMatch(true))
+
+		mockUsecase.On("DeleteUser", mock.Anything, userID, int64(0)).Return(nil)
+
+		request := httptest.NewRequest(http.MethodDelete, "/users/"+userID.String(), nil)
+		request.Header.Set("If-Match", "*")
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.DeleteUser(recorder, request)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should reject unusable If-Match values", func(t *testing.T) {
+		tests := []struct {
+			name         string
+			ifMatch      string
+			requireMatch bool
+			expectedCode int
+			expectedErr  string
+		}{
+			{"missing when required", "", true, http.StatusPreconditionRequired, CodePreconditionRequired},
+			{"weak entity tag", `W/"3"`, false, http.StatusPreconditionFailed, entities.CodeVersionMismatch},
+			{"unquoted entity tag", "3", false, http.StatusBadRequest, CodeInvalidIfMatch},
+			{"entity tag list", `"3", "4"`, false, http.StatusBadRequest, CodeInvalidIfMatch},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockUsecase := new(MockUserUsecase)
+				handler := NewUserHandler(mockUsecase, WithRequireIfMatch(tt.requireMatch))
+
+				request := httptest.NewRequest(http.MethodPatch, "/users/"+userID.String(), bytes.NewBufferString(`{"name":"John Smith"}`))
+				request.Header.Set("Content-Type", dto.MergePatchContentType)
+				if tt.ifMatch != "" {
+					request.Header.Set("If-Match", tt.ifMatch)
+				}
+				request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+				recorder := httptest.NewRecorder()
+
+				handler.PatchUser(recorder, request)
+
+				assert.Equal(t, tt.expectedCode, recorder.Code)
+				assert.Equal(t, tt.expectedErr, decodeProblem(t, recorder).Code)
+				mockUsecase.AssertNotCalled(t, "PatchUser")
+			})
+		}
+	})
+}
+
 func TestUserHandler_DeleteUser(t *testing.T) {
 	mockUsecase := new(MockUserUsecase)
 	handler := NewUserHandler(mockUsecase)
@@ -263,7 +633,7 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 	userID := uuid.New()
 
 	t.Run("should delete user successfully", func(t *testing.T) {
-		mockUsecase.On("DeleteUser", mock.Anything, userID).Return(nil)
+		mockUsecase.On("DeleteUser", mock.Anything, userID, int64(0)).Return(nil)
 
 		request := httptest.NewRequest(http.MethodDelete, "/users/"+userID.String(), nil)
 		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
@@ -286,6 +656,83 @@ func TestUserHandler_DeleteUser(t *testing.T) {
 	})
 }
 
+func TestUserHandler_RestoreUser(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should restore user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("RestoreUser", mock.Anything, userID).Return(&dto.UserResponse{ID: userID, Version: 3}, nil)
+
+		request := httptest.NewRequest(http.MethodPost, "/users/"+userID.String()+"/restore", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.RestoreUser(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, `"3"`, recorder.Header().Get("ETag"))
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should report reused email as conflict", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("RestoreUser", mock.Anything, userID).
+			Return(nil, entities.NewConflictError("cannot restore user: its email is now used by another user", entities.ErrEmailAlreadyUsed))
+
+		request := httptest.NewRequest(http.MethodPost, "/users/"+userID.String()+"/restore", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.RestoreUser(recorder, request)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		problem := decodeProblem(t, recorder)
+		assert.Equal(t, entities.CodeEmailAlreadyUsed, problem.Code)
+		assert.Contains(t, problem.Detail, "cannot restore user")
+	})
+}
+
+func TestUserHandler_PurgeUser(t *testing.T) {
+	userID := uuid.New()
+
+	t.Run("should purge deleted user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("PurgeUser", mock.Anything, userID).Return(nil)
+
+		request := httptest.NewRequest(http.MethodDelete, "/admin/users/"+userID.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PurgeUser(recorder, request)
+
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should refuse to purge active user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("PurgeUser", mock.Anything, userID).
+			Return(entities.NewConflictError("user must be deleted before it can be purged", entities.ErrUserNotDeleted))
+
+		request := httptest.NewRequest(http.MethodDelete, "/admin/users/"+userID.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.PurgeUser(recorder, request)
+
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, entities.CodeUserNotDeleted, decodeProblem(t, recorder).Code)
+	})
+}
+
 func TestUserHandler_ListUsers(t *testing.T) {
 	mockUsecase := new(MockUserUsecase)
 	handler := NewUserHandler(mockUsecase)
@@ -304,7 +751,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 			Offset: 0,
 		}
 
-		mockUsecase.On("ListUsers", mock.Anything, 0, 0).Return(expectedResponse, nil)
+		mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Limit: 0, Offset: 0}).Return(expectedResponse, nil)
 
 		request := httptest.NewRequest(http.MethodGet, "/users", nil)
 		recorder := httptest.NewRecorder()
@@ -329,7 +776,7 @@ func TestUserHandler_ListUsers(t *testing.T) {
 			Offset: 10,
 		}
 
-		mockUsecase.On("ListUsers", mock.Anything, 5, 10).Return(expectedResponse, nil)
+		mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Limit: 5, Offset: 10}).Return(expectedResponse, nil)
 
 		request := httptest.NewRequest(http.MethodGet, "/users?limit=5&offset=10", nil)
 		recorder := httptest.NewRecorder()
@@ -339,4 +786,347 @@ func TestUserHandler_ListUsers(t *testing.T) {
 		assert.Equal(t, http.StatusOK, recorder.Code)
 		mockUsecase.AssertExpectations(t)
 	})
-}
\ No newline at end of file
+
+	t.Run("should return problem naming a malformed pagination parameter", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		for query, name := range map[string]string{"limit=abc": "limit", "limit=5&offset=1.5": "offset"} {
+			request := httptest.NewRequest(http.MethodGet, "/api/v1/users?"+query, nil)
+			recorder := httptest.NewRecorder()
+
+			handler.ListUsers(recorder, request)
+
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			problem := decodeProblem(t, recorder)
+			assert.Equal(t, entities.CodeInvalidPagination, problem.Code)
+			assert.Equal(t, name+" must be an integer", problem.Detail)
+		}
+		mockUsecase.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything)
+	})
+	t.Run("should emit pagination links and total count", func(t *testing.T) {
+		expectedResponse := &dto.ListUsersResponse{
+			Users:  []*dto.UserResponse{},
+			Total:  25,
+			Limit:  10,
+			Offset: 10,
+		}
+
+		mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Limit: 10, Offset: 10}).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=10&offset=10", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListUsers(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "25", recorder.Header().Get("X-Total-Count"))
+		assert.Equal(t,
+			`</api/v1/users?limit=10&offset=0>; rel="first", `+
+				`</api/v1/users?limit=10&offset=0>; rel="prev", `+
+				`</api/v1/users?limit=10&offset=20>; rel="next", `+
+				`</api/v1/users?limit=10&offset=20>; rel="last"`,
+			recorder.Header().Get("Link"))
+
+		var response dto.ListUsersResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "/api/v1/users?limit=10&offset=20", response.Next)
+		assert.Equal(t, "/api/v1/users?limit=10&offset=0", response.Prev)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should omit next link on last page", func(t *testing.T) {
+		expectedResponse := &dto.ListUsersResponse{
+			Users:  []*dto.UserResponse{},
+			Total:  25,
+			Limit:  10,
+			Offset: 20,
+		}
+
+		mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Limit: 10, Offset: 20}).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=10&offset=20", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListUsers(recorder, request)
+
+		var response dto.ListUsersResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Empty(t, response.Next)
+		assert.NotContains(t, recorder.Header().Get("Link"), `rel="next"`)
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("should pass cursor and link to the next cursor page", func(t *testing.T) {
+		expectedResponse := &dto.ListUsersResponse{
+			Users:      []*dto.UserResponse{},
+			Total:      25,
+			Limit:      10,
+			NextCursor: "next-token",
+		}
+
+		mockUsecase.On("ListUsers", mock.Anything, dto.ListUsersRequest{Limit: 10, Cursor: "current-token"}).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=10&cursor=current-token", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListUsers(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t,
+			`</api/v1/users?limit=10&offset=0>; rel="first", `+
+				`</api/v1/users?cursor=next-token&limit=10>; rel="next"`,
+			recorder.Header().Get("Link"))
+
+		var response dto.ListUsersResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Equal(t, "next-token", response.NextCursor)
+		assert.Equal(t, "/api/v1/users?cursor=next-token&limit=10", response.Next)
+		assert.Empty(t, response.Prev)
+		mockUsecase.AssertExpectations(t)
+	})
+	t.Run("should pass filter and sort parameters", func(t *testing.T) {
+		expectedRequest := dto.ListUsersRequest{
+			Limit:        10,
+			Name:         "doe",
+			EmailDomain:  "example.com",
+			CreatedAfter: "2024-01-01T00:00:00Z",
+			Sort:         "-name",
+		}
+		expectedResponse := &dto.ListUsersResponse{Users: []*dto.UserResponse{}, Limit: 10}
+
+		mockUsecase.On("ListUsers", mock.Anything, expectedRequest).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users?limit=10&name=doe&email_domain=example.com&created_after=2024-01-01T00:00:00Z&sort=-name", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListUsers(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+}
+
+func TestUserHandler_SearchUsers(t *testing.T) {
+	t.Run("should return ranked hits", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedResponse := &dto.SearchUsersResponse{
+			Query: "jonh",
+			Hits: []*dto.UserSearchHit{
+				{User: &dto.UserResponse{ID: uuid.New(), Name: "John Doe", Email: "john@example.com"}, Score: 0.5},
+			},
+		}
+		mockUsecase.On("SearchUsers", mock.Anything, dto.SearchUsersRequest{Query: "jonh", Limit: 5}).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users/search?q=jonh&limit=5", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.SearchUsers(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var response dto.SearchUsersResponse
+		err := json.Unmarshal(recorder.Body.Bytes(), &response)
+		assert.NoError(t, err)
+		assert.Len(t, response.Hits, 1)
+		assert.Equal(t, 0.5, response.Hits[0].Score)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return problem for empty query", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("SearchUsers", mock.Anything, dto.SearchUsersRequest{}).
+			Return(nil, entities.NewValidationError("search query must not be empty", entities.ErrInvalidSearch))
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users/search", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.SearchUsers(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidSearch, decodeProblem(t, recorder).Code)
+	})
+}
+
+func TestUserHandler_ListAudit(t *testing.T) {
+	t.Run("should pass filters and report total", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		expectedReq := dto.ListAuditRequest{Actor: "admin", Action: "delete", From: "2024-01-01T00:00:00Z", Limit: 5, Offset: 10}
+		expectedResponse := &dto.ListAuditResponse{
+			Records: []*dto.AuditRecordResponse{{ID: uuid.New(), UserID: uuid.New(), Action: "delete", Actor: "admin"}},
+			Total:   11,
+			Limit:   5,
+			Offset:  10,
+		}
+		mockUsecase.On("ListAudit", mock.Anything, expectedReq).Return(expectedResponse, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/audit?actor=admin&action=delete&from=2024-01-01T00:00:00Z&limit=5&offset=10", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListAudit(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "11", recorder.Header().Get("X-Total-Count"))
+
+		var response dto.ListAuditResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Len(t, response.Records, 1)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should return problem for invalid action", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		mockUsecase.On("ListAudit", mock.Anything, dto.ListAuditRequest{Action: "rename"}).
+			Return(nil, entities.NewValidationError(`unsupported action "rename"`, entities.ErrInvalidFilter))
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/audit?action=rename", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListAudit(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, entities.CodeInvalidFilter, decodeProblem(t, recorder).Code)
+	})
+
+	t.Run("should return problem for malformed offset", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/audit?offset=ten", nil)
+		recorder := httptest.NewRecorder()
+
+		handler.ListAudit(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "offset must be an integer", decodeProblem(t, recorder).Detail)
+		mockUsecase.AssertNotCalled(t, "ListAudit", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserHandler_ListUserAudit(t *testing.T) {
+	t.Run("should scope the query to the user", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		userID := uuid.New()
+		mockUsecase.On("ListAudit", mock.Anything, dto.ListAuditRequest{UserID: userID, Limit: 2}).
+			Return(&dto.ListAuditResponse{Records: []*dto.AuditRecordResponse{}, Limit: 2}, nil)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+userID.String()+"/audit?limit=2", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+		recorder := httptest.NewRecorder()
+
+		handler.ListUserAudit(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		mockUsecase.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid user ID", func(t *testing.T) {
+		mockUsecase := new(MockUserUsecase)
+		handler := NewUserHandler(mockUsecase)
+
+		request := httptest.NewRequest(http.MethodGet, "/api/v1/users/not-a-uuid/audit", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": "not-a-uuid"})
+		recorder := httptest.NewRecorder()
+
+		handler.ListUserAudit(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, CodeInvalidUserID, decodeProblem(t, recorder).Code)
+		mockUsecase.AssertNotCalled(t, "ListAudit", mock.Anything, mock.Anything)
+	})
+}
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) dto.ProblemDetails {
+	t.Helper()
+
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+	var problem dto.ProblemDetails
+	err := json.Unmarshal(recorder.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+	return problem
+}
+
+func TestUserHandler_ProblemDetails(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		err           error
+		expectedCode  int
+		expectedType  entities.ErrorType
+		expectedError string
+		expectedTitle string
+	}{
+		{
+			name:          "should map validation error with sentinel code",
+			err:           entities.NewValidationError("invalid user input", entities.ErrInvalidEmail),
+			expectedCode:  http.StatusBadRequest,
+			expectedType:  entities.ValidationError,
+			expectedError: entities.CodeInvalidEmail,
+			expectedTitle: "Bad Request",
+		},
+		{
+			name:          "should map not found error",
+			err:           entities.NewNotFoundError("user not found", entities.ErrUserNotFound),
+			expectedCode:  http.StatusNotFound,
+			expectedType:  entities.NotFoundError,
+			expectedError: entities.CodeUserNotFound,
+			expectedTitle: "Not Found",
+		},
+		{
+			name:          "should map conflict error",
+			err:           entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed),
+			expectedCode:  http.StatusConflict,
+			expectedType:  entities.ConflictError,
+			expectedError: entities.CodeEmailAlreadyUsed,
+			expectedTitle: "Conflict",
+		},
+		{
+			name:          "should hide internal error details",
+			err:           entities.NewInternalError("failed to get user by ID", assert.AnError),
+			expectedCode:  http.StatusInternalServerError,
+			expectedType:  entities.InternalError,
+			expectedError: string(entities.InternalError),
+			expectedTitle: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsecase := new(MockUserUsecase)
+			handler := NewUserHandler(mockUsecase)
+
+			mockUsecase.On("GetUser", mock.Anything, userID).Return((*dto.UserResponse)(nil), tt.err)
+
+			path := "/api/v1/users/" + userID.String()
+			request := httptest.NewRequest(http.MethodGet, path, nil)
+			request = mux.SetURLVars(request, map[string]string{"id": userID.String()})
+			recorder := httptest.NewRecorder()
+
+			handler.GetUser(recorder, request)
+
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+
+			problem := decodeProblem(t, recorder)
+			assert.Equal(t, tt.expectedCode, problem.Status)
+			assert.Equal(t, string(tt.expectedType), problem.ErrorType)
+			assert.Equal(t, tt.expectedError, problem.Code)
+			assert.Equal(t, tt.expectedTitle, problem.Title)
+			assert.Equal(t, path, problem.Instance)
+			assert.NotContains(t, problem.Detail, assert.AnError.Error())
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCheckTimeout bounds each check unless WithCheckTimeout changes it
+const DefaultCheckTimeout = 2 * time.Second
+
+// ShutdownCheck names the check that fails once Drain has been called
+const ShutdownCheck = "shutdown"
+
+// Status tells whether a check, or the service as a whole, is usable
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// ErrShuttingDown is reported by ShutdownCheck once the service is draining
+var ErrShuttingDown = errors.New("shutting down")
+
+// CheckFunc reports whether a dependency is usable. The details it returns
+// are shown in the report whether or not it fails.
+type CheckFunc func(ctx context.Context) (details map[string]any, err error)
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string         `json:"name"`
+	Status    Status         `json:"status"`
+	LatencyMS float64        `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// Report is the outcome of every check; the service is up only when all are
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+type namedCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Checker runs the readiness checks registered with it
+type Checker struct {
+	timeout  time.Duration
+	draining atomic.Bool
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// Option configures optional Checker behaviour
+type Option func(*Checker)
+
+// WithCheckTimeout sets how long each check may take before it counts as failed
+func WithCheckTimeout(timeout time.Duration) Option {
+	return func(c *Checker) {
+		if timeout > 0 {
+			c.timeout = timeout
+		}
+	}
+}
+
+func New(opts ...Option) *Checker {
+	c := &Checker{timeout: DefaultCheckTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Register adds a check run under name by every Check, replacing any
+// registered under the same name before
+func (c *Checker) Register(name string, check CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.checks {
+		if c.checks[i].name == name {
+			c.checks[i].check = check
+			return
+		}
+	}
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes every later Check fail, so load balancers stop routing
+// traffic to the service while it shuts down
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check runs the registered checks concurrently, each bounded by the check
+// timeout, and reports their results after ShutdownCheck's in registration
+// order
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.RLock()
+	checks := append([]namedCheck{{name: ShutdownCheck, check: c.checkShutdown}}, c.checks...)
+	c.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// run reports a check that outlives the timeout as failed without waiting
+// for it, so one that ignores its context cannot hold up the report
+func (c *Checker) run(ctx context.Context, check namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := check.check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var out outcome
+	select {
+	case out = <-done:
+	case <-ctx.Done():
+		out.err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      check.name,
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+		Details:   out.details,
+	}
+	if out.err != nil {
+		result.Status, result.Error = StatusDown, out.err.Error()
+	}
+	return result
+}
+
+func (c *Checker) checkShutdown(ctx context.Context) (map[string]any, error) {
+	if c.draining.Load() {
+		return nil, ErrShuttingDown
+	}
+	return nil, nil
+}
@@ -0,0 +1,136 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) (map[string]any, error) {
+	return nil, nil
+}
+
+func TestChecker(t *testing.T) {
+	t.Run("is up when every check passes", func(t *testing.T) {
+		checker := New()
+		checker.Register("database", up)
+		checker.Register("cache", func(ctx context.Context) (map[string]any, error) {
+			return map[string]any{"entries": 3}, nil
+		})
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, StatusUp, report.Status)
+		require.Len(t, report.Checks, 3)
+		assert.Equal(t, []string{ShutdownCheck, "database", "cache"}, names(report))
+		assert.Equal(t, map[string]any{"entries": 3}, report.Checks[2].Details)
+		for _, result := range report.Checks {
+			assert.Equal(t, StatusUp, result.Status, result.Name)
+			assert.Empty(t, result.Error, result.Name)
+		}
+	})
+
+	t.Run("is down when any check fails", func(t *testing.T) {
+		checker := New()
+		checker.Register("database", func(ctx context.Context) (map[string]any, error) {
+			return nil, errors.New("connection refused")
+		})
+		checker.Register("cache", up)
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, StatusDown, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+		assert.Equal(t, StatusUp, report.Checks[2].Status)
+	})
+
+	t.Run("replaces a check registered twice", func(t *testing.T) {
+		checker := New()
+		checker.Register("database", func(ctx context.Context) (map[string]any, error) {
+			return nil, errors.New("connection refused")
+		})
+		checker.Register("cache", up)
+		checker.Register("database", up)
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, StatusUp, report.Status)
+		assert.Equal(t, []string{ShutdownCheck, "database", "cache"}, names(report))
+	})
+
+	t.Run("fails checks that outlive the timeout", func(t *testing.T) {
+		checker := New(WithCheckTimeout(20 * time.Millisecond))
+		release := make(chan struct{})
+		defer close(release)
+		checker.Register("stuck", func(ctx context.Context) (map[string]any, error) {
+			<-release
+			return nil, nil
+		})
+
+		start := time.Now()
+		report := checker.Check(context.Background())
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks[1].Error)
+		assert.GreaterOrEqual(t, report.Checks[1].LatencyMS, 20.0)
+	})
+
+	t.Run("is down once draining", func(t *testing.T) {
+		checker := New()
+		checker.Register("database", up)
+		checker.Drain()
+
+		report := checker.Check(context.Background())
+		assert.Equal(t, StatusDown, report.Status)
+		assert.Equal(t, ErrShuttingDown.Error(), report.Checks[0].Error)
+		assert.Equal(t, StatusUp, report.Checks[1].Status)
+	})
+}
+
+func names(report Report) []string {
+	var names []string
+	for _, result := range report.Checks {
+		names = append(names, result.Name)
+	}
+	return names
+}
+
+func TestSQLChecks(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	ctx := context.Background()
+
+	_, err = PingCheck(db)(ctx)
+	assert.NoError(t, err)
+
+	migrations := MigrationCheck(db, "schema_migrations")
+	_, err = migrations(ctx)
+	assert.Error(t, err, "the table does not exist yet")
+
+	_, err = db.Exec(`CREATE TABLE schema_migrations (version uint64, dirty bool)`)
+	require.NoError(t, err)
+	_, err = migrations(ctx)
+	assert.EqualError(t, err, "no migration applied")
+
+	_, err = db.Exec(`INSERT INTO schema_migrations (version, dirty) VALUES (12, false)`)
+	require.NoError(t, err)
+	details, err := migrations(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"version": int64(12), "dirty": false}, details)
+
+	_, err = db.Exec(`UPDATE schema_migrations SET version = 13, dirty = true`)
+	require.NoError(t, err)
+	details, err = migrations(ctx)
+	assert.EqualError(t, err, "migration 13 is dirty")
+	assert.Equal(t, map[string]any{"version": int64(13), "dirty": true}, details)
+
+	require.NoError(t, db.Close())
+	_, err = PingCheck(db)(ctx)
+	assert.Error(t, err)
+}
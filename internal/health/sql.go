@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// PingCheck reports whether db accepts connections
+func PingCheck(db *sql.DB) CheckFunc {
+	return func(ctx context.Context) (map[string]any, error) {
+		return nil, db.PingContext(ctx)
+	}
+}
+
+// MigrationCheck reports the schema version golang-migrate recorded in
+// table. It fails until a migration has been applied, and while the last
+// one is dirty because it failed part way through.
+func MigrationCheck(db *sql.DB, table string) CheckFunc {
+	query := `SELECT version, dirty FROM ` + table + ` LIMIT 1`
+
+	return func(ctx context.Context) (map[string]any, error) {
+		var (
+			version int64
+			dirty   bool
+		)
+		err := db.QueryRowContext(ctx, query).Scan(&version, &dirty)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("no migration applied")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		details := map[string]any{"version": version, "dirty": dirty}
+		if dirty {
+			return details, fmt.Errorf("migration %d is dirty", version)
+		}
+		return details, nil
+	}
+}
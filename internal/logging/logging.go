@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go-clean-code/internal/entities"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDKey is the attribute records logged during a request carry its ID under
+const RequestIDKey = "request_id"
+
+// Attributes records logged within a trace carry its trace and span IDs under
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+// New returns a logger writing JSON records at or above level to w. Records
+// logged with a request's context carry its request ID, and its trace and
+// span IDs when it is traced.
+func New(w io.Writer, level slog.Leveler) *slog.Logger {
+	return slog.New(contextHandler{slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})})
+}
+
+// ParseLevel reads debug, info, warn or error, ignoring case
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	switch name := strings.ToLower(strings.TrimSpace(s)); name {
+	case "debug", "info", "warn", "error":
+		err := level.UnmarshalText([]byte(name))
+		return level, err
+	default:
+		return level, fmt.Errorf("log level %q must be debug, info, warn or error", s)
+	}
+}
+
+// contextHandler adds the request ID and span in the context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := entities.RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String(RequestIDKey, requestID))
+	}
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		record.AddAttrs(slog.String(TraceIDKey, span.TraceID().String()), slog.String(SpanIDKey, span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
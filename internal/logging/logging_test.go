@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo).With("component", "test")
+
+	logger.DebugContext(context.Background(), "hidden")
+	logger.InfoContext(entities.ContextWithRequestID(context.Background(), "req-1"), "shown", "status", 200)
+	logger.Info("outside a request")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "shown", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "req-1", record[RequestIDKey])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, float64(200), record["status"])
+
+	record = nil
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.NotContains(t, record, RequestIDKey)
+}
+
+func TestNew_Trace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, slog.LevelInfo)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	logger.InfoContext(ctx, "traced")
+	logger.Info("untraced")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record[TraceIDKey])
+	assert.Equal(t, "00f067aa0ba902b7", record[SpanIDKey])
+
+	record = nil
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.NotContains(t, record, TraceIDKey)
+	assert.NotContains(t, record, SpanIDKey)
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, expected := range map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"INFO":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"Error": slog.LevelError,
+	} {
+		level, err := ParseLevel(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, level)
+	}
+
+	for _, input := range []string{"", "verbose", "info+2"} {
+		_, err := ParseLevel(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/handler"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// DefaultNamespace prefixes every metric name unless WithNamespace changes it
+const DefaultNamespace = "go_clean_code"
+
+// UnmatchedRoute labels requests that matched no route
+const UnmatchedRoute = "unmatched"
+
+// UnknownErrorType labels errors that are not a *entities.DomainError
+const UnknownErrorType = "UNKNOWN"
+
+// Metrics collects the service's Prometheus metrics in its own registry
+type Metrics struct {
+	namespace   string
+	httpBuckets []float64
+	registry    *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	usecaseErrors *prometheus.CounterVec
+}
+
+// Option configures optional Metrics behaviour
+type Option func(*Metrics)
+
+// WithNamespace sets the prefix of every metric name; an empty namespace
+// leaves names unprefixed
+func WithNamespace(namespace string) Option {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithHTTPBuckets sets the upper bounds, in seconds, of the request latency
+// histogram buckets
+func WithHTTPBuckets(buckets []float64) Option {
+	return func(m *Metrics) {
+		if len(buckets) > 0 {
+			m.httpBuckets = buckets
+		}
+	}
+}
+
+// New registers the HTTP, usecase, Go runtime and process metrics in a new
+// registry
+func New(opts ...Option) *Metrics {
+	m := &Metrics{
+		namespace:   DefaultNamespace,
+		httpBuckets: prometheus.DefBuckets,
+		registry:    prometheus.NewRegistry(),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	m.httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests served, by method, route template and status.",
+	}, []string{"method", "route", "status"})
+	m.httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Time taken to serve HTTP requests, by method, route template and status.",
+		Buckets:   m.httpBuckets,
+	}, []string{"method", "route", "status"})
+	m.usecaseErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace,
+		Subsystem: "usecase",
+		Name:      "errors_total",
+		Help:      "Errors returned by user operations, by operation and error type.",
+	}, []string{"operation", "type"})
+
+	m.registry.MustRegister(
+		m.httpRequests,
+		m.httpDuration,
+		m.usecaseErrors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest counts a served request and its duration
+func (m *Metrics) ObserveRequest(ctx context.Context, info handler.RequestInfo) {
+	route := info.Route
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	status := strconv.Itoa(info.Status)
+	m.httpRequests.WithLabelValues(info.Method, route, status).Inc()
+	m.httpDuration.WithLabelValues(info.Method, route, status).Observe(info.Duration.Seconds())
+}
+
+// observeError counts an error returned by operation; nil errors are ignored
+func (m *Metrics) observeError(operation string, err error) {
+	if err == nil {
+		return
+	}
+	errorType := UnknownErrorType
+	var domainErr *entities.DomainError
+	if errors.As(err, &domainErr) {
+		errorType = string(domainErr.Type)
+	}
+	m.usecaseErrors.WithLabelValues(operation, errorType).Inc()
+}
+
+// ParseBuckets reads histogram bucket bounds written as comma-separated
+// seconds in increasing order, such as "0.01,0.05,0.1,0.5,1"
+func ParseBuckets(spec string) ([]float64, error) {
+	var buckets []float64
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		bound, err := strconv.ParseFloat(field, 64)
+		if err != nil || bound <= 0 {
+			return nil, fmt.Errorf("bucket %q must be a positive number of seconds", field)
+		}
+		if len(buckets) > 0 && bound <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("buckets must be in increasing order, but %q is not", field)
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
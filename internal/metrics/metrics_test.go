@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/handler"
+	"go-clean-code/internal/usecase"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return rec.Body.String()
+}
+
+func TestMetrics_ObserveRequest(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+
+	m.ObserveRequest(ctx, handler.RequestInfo{Method: "GET", Route: "/api/v1/users/{id}", Status: 200, Duration: 20 * time.Millisecond})
+	m.ObserveRequest(ctx, handler.RequestInfo{Method: "GET", Route: "/api/v1/users/{id}", Status: 200, Duration: 30 * time.Millisecond})
+	m.ObserveRequest(ctx, handler.RequestInfo{Method: "GET", Route: "/api/v1/users/{id}", Status: 404, Duration: time.Millisecond})
+	m.ObserveRequest(ctx, handler.RequestInfo{Method: "GET", Status: 404})
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/users/{id}", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/v1/users/{id}", "404")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", UnmatchedRoute, "404")))
+	assert.Equal(t, 3, testutil.CollectAndCount(m.httpDuration))
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `go_clean_code_http_requests_total{method="GET",route="/api/v1/users/{id}",status="200"} 2`)
+	assert.Contains(t, body, `go_clean_code_http_request_duration_seconds_bucket{method="GET",route="/api/v1/users/{id}",status="200",le="0.025"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func TestMetrics_Options(t *testing.T) {
+	m := New(WithNamespace("users_api"), WithHTTPBuckets([]float64{0.1, 1}))
+	m.ObserveRequest(context.Background(), handler.RequestInfo{Method: "POST", Route: "/api/v1/users", Status: 201, Duration: 200 * time.Millisecond})
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `users_api_http_request_duration_seconds_bucket{method="POST",route="/api/v1/users",status="201",le="0.1"} 0`)
+	assert.Contains(t, body, `users_api_http_request_duration_seconds_bucket{method="POST",route="/api/v1/users",status="201",le="1"} 1`)
+	assert.NotContains(t, body, `le="0.005"`)
+	assert.NotContains(t, body, "go_clean_code_")
+}
+
+func TestParseBuckets(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []float64
+		wantErr string
+	}{
+		{name: "empty", spec: "", want: nil},
+		{name: "ascending", spec: "0.01, 0.1,1,10", want: []float64{0.01, 0.1, 1, 10}},
+		{name: "not a number", spec: "0.1,fast", wantErr: `bucket "fast"`},
+		{name: "not positive", spec: "0,1", wantErr: `bucket "0"`},
+		{name: "out of order", spec: "1,0.5", wantErr: "increasing order"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseBuckets(tt.spec)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// stubUsecase returns err from every operation the tests call
+type stubUsecase struct {
+	usecase.UserUsecaseInterface
+	err error
+}
+
+func (s *stubUsecase) GetUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &dto.UserResponse{ID: id}, nil
+}
+
+func (s *stubUsecase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	return s.err
+}
+
+func TestMetrics_InstrumentUserUsecase(t *testing.T) {
+	m := New()
+	stub := &stubUsecase{}
+	uc := m.InstrumentUserUsecase(stub)
+	ctx := context.Background()
+
+	user, err := uc.GetUser(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.NotNil(t, user)
+	assert.Equal(t, 0, testutil.CollectAndCount(m.usecaseErrors))
+
+	notFound := entities.NewNotFoundError("User not found", nil)
+	stub.err = notFound
+	_, err = uc.GetUser(ctx, uuid.New())
+	assert.Same(t, notFound, err)
+	_, _ = uc.GetUser(ctx, uuid.New())
+
+	stub.err = entities.NewValidationError("version required", nil)
+	_ = uc.DeleteUser(ctx, uuid.New(), 0)
+
+	stub.err = errors.New("connection reset")
+	_ = uc.DeleteUser(ctx, uuid.New(), 1)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.usecaseErrors.WithLabelValues("GetUser", string(entities.NotFoundError))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.usecaseErrors.WithLabelValues("DeleteUser", string(entities.ValidationError))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.usecaseErrors.WithLabelValues("DeleteUser", UnknownErrorType)))
+}
+
+func TestMetrics_RegisterDB(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	db.SetMaxOpenConns(4)
+	require.NoError(t, db.Ping())
+
+	m := New()
+	require.NoError(t, m.RegisterDB(db, "sqlite"))
+	assert.Error(t, m.RegisterDB(db, "sqlite"), "the same pool cannot be registered twice")
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `go_clean_code_db_max_open_connections{db="sqlite"} 4`)
+	assert.Contains(t, body, `go_clean_code_db_open_connections{db="sqlite"} 1`)
+	assert.Contains(t, body, `go_clean_code_db_idle_connections{db="sqlite"} 1`)
+	assert.Contains(t, body, "go_clean_code_db_wait_count_total")
+}
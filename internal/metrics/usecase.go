@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/usecase"
+
+	"github.com/google/uuid"
+)
+
+// userUsecase counts the errors each UserUsecaseInterface operation returns
+type userUsecase struct {
+	next    usecase.UserUsecaseInterface
+	metrics *Metrics
+}
+
+// InstrumentUserUsecase returns next with its errors counted by operation
+// and entities.ErrorType
+func (m *Metrics) InstrumentUserUsecase(next usecase.UserUsecaseInterface) usecase.UserUsecaseInterface {
+	return &userUsecase{next: next, metrics: m}
+}
+
+func (u *userUsecase) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	user, err := u.next.CreateUser(ctx, req)
+	u.metrics.observeError("CreateUser", err)
+	return user, err
+}
+
+func (u *userUsecase) GetUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	user, err := u.next.GetUser(ctx, id)
+	u.metrics.observeError("GetUser", err)
+	return user, err
+}
+
+func (u *userUsecase) UpdateUser(ctx context.Context, id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	user, err := u.next.UpdateUser(ctx, id, req)
+	u.metrics.observeError("UpdateUser", err)
+	return user, err
+}
+
+func (u *userUsecase) PatchUser(ctx context.Context, id uuid.UUID, req dto.PatchUserRequest) (*dto.UserResponse, error) {
+	user, err := u.next.PatchUser(ctx, id, req)
+	u.metrics.observeError("PatchUser", err)
+	return user, err
+}
+
+func (u *userUsecase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	err := u.next.DeleteUser(ctx, id, expectedVersion)
+	u.metrics.observeError("DeleteUser", err)
+	return err
+}
+
+func (u *userUsecase) RestoreUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	user, err := u.next.RestoreUser(ctx, id)
+	u.metrics.observeError("RestoreUser", err)
+	return user, err
+}
+
+func (u *userUsecase) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	err := u.next.PurgeUser(ctx, id)
+	u.metrics.observeError("PurgeUser", err)
+	return err
+}
+
+func (u *userUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+	users, err := u.next.ListUsers(ctx, req)
+	u.metrics.observeError("ListUsers", err)
+	return users, err
+}
+
+func (u *userUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.SearchUsersResponse, error) {
+	users, err := u.next.SearchUsers(ctx, req)
+	u.metrics.observeError("SearchUsers", err)
+	return users, err
+}
+
+func (u *userUsecase) ExportUsers(ctx context.Context, req dto.ExportUsersRequest, emit func(*dto.ExportedUser) error) error {
+	err := u.next.ExportUsers(ctx, req, emit)
+	u.metrics.observeError("ExportUsers", err)
+	return err
+}
+
+func (u *userUsecase) ImportUsers(ctx context.Context, req dto.ImportUsersRequest) (*dto.ImportUsersResponse, error) {
+	report, err := u.next.ImportUsers(ctx, req)
+	u.metrics.observeError("ImportUsers", err)
+	return report, err
+}
+
+func (u *userUsecase) ListAudit(ctx context.Context, req dto.ListAuditRequest) (*dto.ListAuditResponse, error) {
+	records, err := u.next.ListAudit(ctx, req)
+	u.metrics.observeError("ListAudit", err)
+	return records, err
+}
+
+func (u *userUsecase) ChangeUserRole(ctx context.Context, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.UserResponse, error) {
+	user, err := u.next.ChangeUserRole(ctx, id, req)
+	u.metrics.observeError("ChangeUserRole", err)
+	return user, err
+}
+
+func (u *userUsecase) ChangePassword(ctx context.Context, id uuid.UUID, req dto.ChangePasswordRequest) (*dto.UserResponse, error) {
+	user, err := u.next.ChangePassword(ctx, id, req)
+	u.metrics.observeError("ChangePassword", err)
+	return user, err
+}
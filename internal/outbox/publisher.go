@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"go-clean-code/internal/entities"
+)
+
+// WriterPublisher writes each event as a line of JSON to w
+type WriterPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterPublisher(w io.Writer) *WriterPublisher {
+	return &WriterPublisher{w: w}
+}
+
+// NewStdoutPublisher writes events to standard output
+func NewStdoutPublisher() *WriterPublisher {
+	return NewWriterPublisher(os.Stdout)
+}
+
+func (p *WriterPublisher) Publish(ctx context.Context, event *entities.UserEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err = p.w.Write(append(data, '\n'))
+	return err
+}
+
+// FilePublisher appends each event as a line of JSON to a file
+type FilePublisher struct {
+	*WriterPublisher
+	file *os.File
+}
+
+// NewFilePublisher opens path for appending, creating it when missing
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open outbox file: %w", err)
+	}
+	return &FilePublisher{WriterPublisher: NewWriterPublisher(file), file: file}, nil
+}
+
+// Publish returns only once the event has reached stable storage
+func (p *FilePublisher) Publish(ctx context.Context, event *entities.UserEvent) error {
+	if err := p.WriterPublisher.Publish(ctx, event); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
+
+// IdempotencyKeyHeader carries the event ID so receivers can drop redeliveries
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultHTTPTimeout bounds a single HTTPPublisher request
+const DefaultHTTPTimeout = 10 * time.Second
+
+// HTTPPublisher POSTs each event as JSON to a URL; any non-2xx response is
+// treated as a failed delivery
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher publishes to url using client, or a client with
+// DefaultHTTPTimeout when client is nil
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	return &HTTPPublisher{url: url, client: client}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event *entities.UserEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, event.ID.String())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("publish event: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
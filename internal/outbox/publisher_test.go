@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEvent() *entities.UserEvent {
+	return &entities.UserEvent{
+		ID:         uuid.New(),
+		Type:       entities.UserCreated,
+		UserID:     uuid.New(),
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewWriterPublisher(&buf)
+	first, second := newTestEvent(), newTestEvent()
+
+	require.NoError(t, publisher.Publish(context.Background(), first))
+	require.NoError(t, publisher.Publish(context.Background(), second))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var decoded entities.UserEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	assert.Equal(t, second.ID, decoded.ID)
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	require.NoError(t, os.WriteFile(path, []byte("existing\n"), 0o644))
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+	event := newTestEvent()
+	require.NoError(t, publisher.Publish(context.Background(), event))
+	require.NoError(t, publisher.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "existing\n"))
+	assert.Contains(t, string(data), event.ID.String())
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	t.Run("should post event with idempotency key", func(t *testing.T) {
+		event := newTestEvent()
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, event.ID.String(), r.Header.Get(IdempotencyKeyHeader))
+
+			var decoded entities.UserEvent
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&decoded))
+			assert.Equal(t, event.Type, decoded.Type)
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		err := NewHTTPPublisher(server.URL, nil).Publish(context.Background(), event)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should fail on non-2xx response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := NewHTTPPublisher(server.URL, nil).Publish(context.Background(), newTestEvent())
+		assert.ErrorContains(t, err, "503")
+	})
+}
@@ -0,0 +1,178 @@
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+)
+
+// Publisher hands an event to downstream consumers. A returned error makes
+// the relay retry the event later, so Publish may see an event more than once.
+type Publisher interface {
+	Publish(ctx context.Context, event *entities.UserEvent) error
+}
+
+// Relay defaults
+const (
+	DefaultPollInterval = time.Second
+	DefaultBatchSize    = 100
+	DefaultBaseBackoff  = time.Second
+	DefaultMaxBackoff   = 5 * time.Minute
+	DefaultLease        = 5 * time.Minute
+	DefaultRetention    = 24 * time.Hour
+)
+
+// maxPruneInterval bounds how long the relay goes between prunes
+const maxPruneInterval = time.Hour
+
+// Relay polls the outbox and publishes pending events with at-least-once
+// delivery, retrying failed events with capped exponential backoff
+type Relay struct {
+	repo         repository.OutboxRepositoryInterface
+	publisher    Publisher
+	pollInterval time.Duration
+	batchSize    int
+	baseBackoff  time.Duration
+	maxBackoff   time.Duration
+	lease        time.Duration
+	retention    time.Duration
+}
+
+// RelayOption configures optional Relay behaviour
+type RelayOption func(*Relay)
+
+// WithPollInterval sets how often the relay looks for pending events
+func WithPollInterval(interval time.Duration) RelayOption {
+	return func(r *Relay) {
+		if interval > 0 {
+			r.pollInterval = interval
+		}
+	}
+}
+
+// WithBatchSize sets how many events the relay claims at once
+func WithBatchSize(size int) RelayOption {
+	return func(r *Relay) {
+		if size > 0 {
+			r.batchSize = size
+		}
+	}
+}
+
+// WithBackoff sets the delay before the first retry and the cap it doubles up to
+func WithBackoff(base, maxDelay time.Duration) RelayOption {
+	return func(r *Relay) {
+		if base > 0 {
+			r.baseBackoff = base
+		}
+		if maxDelay > 0 {
+			r.maxBackoff = maxDelay
+		}
+	}
+}
+
+// WithLease sets how long claimed events are reserved for delivery before
+// another relay may claim them again; it should cover publishing a whole batch
+func WithLease(lease time.Duration) RelayOption {
+	return func(r *Relay) {
+		if lease > 0 {
+			r.lease = lease
+		}
+	}
+}
+
+// WithRetention sets how long published events are kept before the relay
+// deletes them; zero keeps them forever
+func WithRetention(retention time.Duration) RelayOption {
+	return func(r *Relay) {
+		if retention >= 0 {
+			r.retention = retention
+		}
+	}
+}
+
+func NewRelay(repo repository.OutboxRepositoryInterface, publisher Publisher, opts ...RelayOption) *Relay {
+	r := &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		pollInterval: DefaultPollInterval,
+		batchSize:    DefaultBatchSize,
+		baseBackoff:  DefaultBaseBackoff,
+		maxBackoff:   DefaultMaxBackoff,
+		lease:        DefaultLease,
+		retention:    DefaultRetention,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run publishes pending events, and prunes published ones, until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	var pruned time.Time
+	for {
+		if err := r.Drain(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Outbox relay failed", "error", err)
+		}
+		if r.retention > 0 && time.Since(pruned) >= min(r.retention, maxPruneInterval) {
+			pruned = time.Now()
+			if err := r.Prune(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Outbox prune failed", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain dispatches batches until fewer events than a full batch are pending
+func (r *Relay) Drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		claimed, err := r.repo.Dispatch(ctx, r.batchSize, r.lease, r.publisher.Publish, r.backoff)
+		if err != nil {
+			return err
+		}
+		if claimed < r.batchSize {
+			return nil
+		}
+	}
+	return nil
+}
+
+// Prune deletes events published longer ago than the retention period
+func (r *Relay) Prune(ctx context.Context) error {
+	deleted, err := r.repo.Prune(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "Pruned published outbox events", "count", deleted)
+	}
+	return nil
+}
+
+// backoff doubles the base delay for every failed attempt up to maxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.baseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= r.maxBackoff {
+			return r.maxBackoff
+		}
+	}
+	if delay > r.maxBackoff {
+		return r.maxBackoff
+	}
+	return delay
+}
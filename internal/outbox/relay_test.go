@@ -0,0 +1,156 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []*entities.UserEvent
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *entities.UserEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.events)
+}
+
+func createUsers(t *testing.T, repo repository.UserRepositoryInterface, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		user, err := entities.NewUser("John Doe", uuid.NewString()+"@example.com")
+		require.NoError(t, err)
+		require.NoError(t, repo.Create(context.Background(), user))
+	}
+}
+
+func TestRelay_Drain(t *testing.T) {
+	t.Run("should publish every pending event across batches", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		createUsers(t, repo, 5)
+		publisher := &recordingPublisher{}
+
+		relay := NewRelay(repo, publisher, WithBatchSize(2))
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Equal(t, 5, publisher.count())
+
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Equal(t, 5, publisher.count())
+	})
+
+	t.Run("should keep failed events for a later attempt", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		createUsers(t, repo, 1)
+		publisher := &recordingPublisher{err: errors.New("broker down")}
+
+		relay := NewRelay(repo, publisher, WithBackoff(time.Millisecond, time.Millisecond))
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Equal(t, 0, publisher.count())
+
+		time.Sleep(5 * time.Millisecond)
+		publisher.err = nil
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Equal(t, 1, publisher.count())
+	})
+}
+
+func TestRelay_Run(t *testing.T) {
+	repo := repository.NewUserMemoryRepository()
+	publisher := &recordingPublisher{}
+	relay := NewRelay(repo, publisher, WithPollInterval(5*time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+
+	createUsers(t, repo, 2)
+	assert.Eventually(t, func() bool { return publisher.count() == 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("relay did not stop after cancellation")
+	}
+}
+
+// pruneRecorder records the cutoffs the relay prunes with
+type pruneRecorder struct {
+	repository.OutboxRepositoryInterface
+	mu      sync.Mutex
+	cutoffs []time.Time
+}
+
+func (r *pruneRecorder) Prune(ctx context.Context, publishedBefore time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cutoffs = append(r.cutoffs, publishedBefore)
+	return 0, nil
+}
+
+func (r *pruneRecorder) pruned() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cutoffs
+}
+
+func TestRelay_Prune(t *testing.T) {
+	run := func(t *testing.T, repo *pruneRecorder, opts ...RelayOption) {
+		relay := NewRelay(repo, &recordingPublisher{}, append(opts, WithPollInterval(5*time.Millisecond))...)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		relay.Run(ctx)
+	}
+
+	t.Run("should prune events older than the retention period once per interval", func(t *testing.T) {
+		repo := &pruneRecorder{OutboxRepositoryInterface: repository.NewUserMemoryRepository()}
+		start := time.Now()
+		run(t, repo, WithRetention(time.Hour))
+
+		cutoffs := repo.pruned()
+		require.Len(t, cutoffs, 1)
+		assert.WithinDuration(t, start.Add(-time.Hour), cutoffs[0], time.Second)
+	})
+
+	t.Run("should keep published events when retention is zero", func(t *testing.T) {
+		repo := &pruneRecorder{OutboxRepositoryInterface: repository.NewUserMemoryRepository()}
+		run(t, repo, WithRetention(0))
+
+		assert.Empty(t, repo.pruned())
+	})
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	relay := NewRelay(nil, nil, WithBackoff(time.Second, 10*time.Second))
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 8*time.Second, relay.backoff(4))
+	assert.Equal(t, 10*time.Second, relay.backoff(5))
+	assert.Equal(t, 10*time.Second, relay.backoff(100))
+}
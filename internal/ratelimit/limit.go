@@ -0,0 +1,104 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows a client Requests requests per Period. Tokens refill
+// continuously, so a client that has been idle for Period may send Requests
+// requests at once.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// IsZero reports whether the limit is unset
+func (l Limit) IsZero() bool {
+	return l.Requests == 0 || l.Period == 0
+}
+
+// perSecond is the rate tokens refill at
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// String formats the limit the way ParseLimit reads it
+func (l Limit) String() string {
+	period := l.Period.String()
+	switch l.Period {
+	case time.Second:
+		period = "s"
+	case time.Minute:
+		period = "m"
+	case time.Hour:
+		period = "h"
+	}
+	return strconv.Itoa(l.Requests) + "/" + period
+}
+
+// ParseLimit reads a limit written as requests/period, such as "100/m",
+// "5/s" or "1000/1h". The period is s, m, h or a Go duration.
+func ParseLimit(s string) (Limit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q must be written as requests/period", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must allow a positive number of requests", s)
+	}
+	switch period {
+	case "s", "m", "h":
+		period = "1" + period
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("rate limit %q must have a positive period", s)
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// ParseRouteLimits reads per-route limits written as comma-separated
+// route=limit pairs, such as "POST /api/v1/users=10/m, /api/v1/users/search=30/m".
+// A route is a method and path template, or a path template alone to cover
+// every method.
+func ParseRouteLimits(spec string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		route, limit, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("route limit %q must be written as route=limit", pair)
+		}
+		key, err := routeKey(route)
+		if err != nil {
+			return nil, err
+		}
+		if _, duplicate := limits[key]; duplicate {
+			return nil, fmt.Errorf("route %q has more than one limit", key)
+		}
+		if limits[key], err = ParseLimit(limit); err != nil {
+			return nil, err
+		}
+	}
+	return limits, nil
+}
+
+// routeKey normalises "post  /users" to "POST /users"
+func routeKey(route string) (string, error) {
+	fields := strings.Fields(route)
+	switch {
+	case len(fields) == 1 && strings.HasPrefix(fields[0], "/"):
+		return fields[0], nil
+	case len(fields) == 2 && strings.HasPrefix(fields[1], "/"):
+		return strings.ToUpper(fields[0]) + " " + fields[1], nil
+	default:
+		return "", fmt.Errorf("route %q must be a path template, optionally preceded by a method", route)
+	}
+}
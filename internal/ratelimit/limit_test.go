@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		spec     string
+		expected Limit
+	}{
+		{"100/m", Limit{Requests: 100, Period: time.Minute}},
+		{" 5/s ", Limit{Requests: 5, Period: time.Second}},
+		{"1000/h", Limit{Requests: 1000, Period: time.Hour}},
+		{"10/30s", Limit{Requests: 10, Period: 30 * time.Second}},
+	}
+	for _, tt := range tests {
+		limit, err := ParseLimit(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.expected, limit)
+	}
+	assert.Equal(t, "100/m", Limit{Requests: 100, Period: time.Minute}.String())
+	assert.Equal(t, "10/30s", Limit{Requests: 10, Period: 30 * time.Second}.String())
+
+	for _, spec := range []string{"", "100", "0/m", "-1/m", "ten/m", "10/fortnight", "10/-1s"} {
+		_, err := ParseLimit(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseRouteLimits(t *testing.T) {
+	limits, err := ParseRouteLimits("post /api/v1/users=10/m, /api/v1/users/search=30/m,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"POST /api/v1/users":   {Requests: 10, Period: time.Minute},
+		"/api/v1/users/search": {Requests: 30, Period: time.Minute},
+	}, limits)
+
+	for _, spec := range []string{
+		"POST /api/v1/users",
+		"POST=10/m",
+		"POST users=10/m",
+		"/api/v1/users=often",
+		"POST /api/v1/users=10/m,POST /api/v1/users=20/m",
+	} {
+		_, err := ParseRouteLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
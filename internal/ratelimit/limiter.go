@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limiter applies a limit per route and client. A route with its own limit
+// gives each client a bucket for that route; the remaining routes share one
+// bucket per client under the default limit.
+type Limiter struct {
+	store        Store
+	defaultLimit Limit
+	routes       map[string]Limit
+	now          func() time.Time
+}
+
+// LimiterOption configures optional Limiter behaviour
+type LimiterOption func(*Limiter)
+
+// WithStore sets where buckets are kept; the default is a MemoryStore
+func WithStore(store Store) LimiterOption {
+	return func(l *Limiter) {
+		if store != nil {
+			l.store = store
+		}
+	}
+}
+
+// WithDefaultLimit sets the limit for routes without their own; such routes
+// are unlimited when it is zero
+func WithDefaultLimit(limit Limit) LimiterOption {
+	return func(l *Limiter) {
+		l.defaultLimit = limit
+	}
+}
+
+// WithRouteLimits sets limits by route, as read by ParseRouteLimits
+func WithRouteLimits(routes map[string]Limit) LimiterOption {
+	return func(l *Limiter) {
+		for route, limit := range routes {
+			l.routes[route] = limit
+		}
+	}
+}
+
+func NewLimiter(opts ...LimiterOption) *Limiter {
+	l := &Limiter{
+		routes: make(map[string]Limit),
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+	return l
+}
+
+// Allow takes a token for client's request to the route with method and
+// path template. The returned limit is zero when the route is unlimited.
+func (l *Limiter) Allow(ctx context.Context, method, route, client string) (Limit, Result, error) {
+	key, limit := l.limitFor(method, route)
+	if limit.IsZero() {
+		return Limit{}, Result{Allowed: true}, nil
+	}
+	result, err := l.store.Take(ctx, key+" "+client, limit, l.now())
+	return limit, result, err
+}
+
+// limitFor returns the limit for the route and the name of its bucket
+func (l *Limiter) limitFor(method, route string) (string, Limit) {
+	if limit, ok := l.routes[method+" "+route]; ok {
+		return method + " " + route, limit
+	}
+	if limit, ok := l.routes[route]; ok {
+		return route, limit
+	}
+	return "*", l.defaultLimit
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	perMinute := func(n int) Limit { return Limit{Requests: n, Period: time.Minute} }
+	limiter := NewLimiter(
+		WithDefaultLimit(perMinute(2)),
+		WithRouteLimits(map[string]Limit{
+			"POST /users":   perMinute(1),
+			"/users/search": perMinute(5),
+		}),
+	)
+
+	t.Run("should prefer the method-specific route limit", func(t *testing.T) {
+		limit, result, err := limiter.Allow(ctx, "POST", "/users", "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, perMinute(1), limit)
+		assert.True(t, result.Allowed)
+
+		_, result, err = limiter.Allow(ctx, "POST", "/users", "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("should apply path limits to every method", func(t *testing.T) {
+		limit, _, err := limiter.Allow(ctx, "GET", "/users/search", "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, perMinute(5), limit)
+	})
+
+	t.Run("should share the default bucket between other routes", func(t *testing.T) {
+		_, result, err := limiter.Allow(ctx, "GET", "/users", "user:1")
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.Remaining)
+
+		limit, result, err := limiter.Allow(ctx, "GET", "/users/{id}", "user:1")
+		require.NoError(t, err)
+		assert.Equal(t, perMinute(2), limit)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("should not limit routes without a limit", func(t *testing.T) {
+		limiter := NewLimiter(WithRouteLimits(map[string]Limit{"POST /users": perMinute(1)}))
+
+		limit, result, err := limiter.Allow(ctx, "GET", "/users", "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, limit.IsZero())
+		assert.True(t, result.Allowed)
+	})
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result is the state of a client's bucket after it asked for a token
+type Result struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long a rejected client must wait for a token
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps one token bucket per key. Implementations must be safe for
+// concurrent use; a store shared between instances, such as one backed by
+// Redis, makes them enforce a single quota.
+type Store interface {
+	// Take removes a token from the bucket named key, which refills at limit
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error)
+}
+
+// DefaultSweepInterval is how often MemoryStore evicts idle buckets
+const DefaultSweepInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory. A bucket that has refilled
+// completely behaves exactly like a missing one, so it is evicted on the
+// next sweep; memory is bounded by the clients active within one period.
+type MemoryStore struct {
+	mu            sync.Mutex
+	buckets       map[string]*bucket
+	sweepInterval time.Duration
+	lastSweep     time.Time
+}
+
+// MemoryStoreOption configures optional MemoryStore behaviour
+type MemoryStoreOption func(*MemoryStore)
+
+// WithSweepInterval sets how often idle buckets are evicted
+func WithSweepInterval(interval time.Duration) MemoryStoreOption {
+	return func(s *MemoryStore) {
+		if interval > 0 {
+			s.sweepInterval = interval
+		}
+	}
+}
+
+func NewMemoryStore(opts ...MemoryStoreOption) *MemoryStore {
+	s := &MemoryStore{
+		buckets:       make(map[string]*bucket),
+		sweepInterval: DefaultSweepInterval,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), last: now}
+		s.buckets[key] = b
+	}
+	b.limit = limit
+	b.refill(now)
+
+	if b.tokens < 1 {
+		return Result{
+			RetryAfter: seconds((1 - b.tokens) / limit.perSecond()),
+			Reset:      b.untilFull(),
+		}, nil
+	}
+	b.tokens--
+	return Result{
+		Allowed:   true,
+		Remaining: int(b.tokens),
+		Reset:     b.untilFull(),
+	}, nil
+}
+
+// sweep evicts full buckets once per sweep interval; the caller holds mu
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		b.refill(now)
+		if b.untilFull() == 0 {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// bucket holds the tokens left to a client as of last
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Requests), b.tokens+elapsed.Seconds()*b.limit.perSecond())
+		b.last = now
+	}
+}
+
+func (b *bucket) untilFull() time.Duration {
+	return seconds((float64(b.limit.Requests) - b.tokens) / b.limit.perSecond())
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
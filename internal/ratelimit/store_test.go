@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore_Take(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	now := time.Now()
+
+	t.Run("should allow a burst up to the limit", func(t *testing.T) {
+		for remaining := 2; remaining >= 0; remaining-- {
+			result, err := store.Take(ctx, "client", limit, now)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, remaining, result.Remaining)
+		}
+
+		result, err := store.Take(ctx, "client", limit, now)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, time.Second, result.RetryAfter)
+		assert.Equal(t, 3*time.Second, result.Reset)
+	})
+
+	t.Run("should refill continuously", func(t *testing.T) {
+		result, err := store.Take(ctx, "client", limit, now.Add(1500*time.Millisecond))
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+
+		result, err = store.Take(ctx, "client", limit, now.Add(1500*time.Millisecond))
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+	})
+
+	t.Run("should keep clients apart", func(t *testing.T) {
+		result, err := store.Take(ctx, "other", limit, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+}
+
+func TestMemoryStore_Sweep(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore(WithSweepInterval(time.Minute))
+	limit := Limit{Requests: 10, Period: time.Minute}
+	now := time.Now()
+
+	_, err := store.Take(ctx, "idle", limit, now)
+	require.NoError(t, err)
+	_, err = store.Take(ctx, "active", limit, now.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 2)
+
+	// Only the bucket that has refilled completely is evicted
+	_, err = store.Take(ctx, "active", limit, now.Add(61*time.Second))
+	require.NoError(t, err)
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "active")
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyRepositoryInterface stores API keys by the hash of the key
+type APIKeyRepositoryInterface interface {
+	CreateAPIKey(ctx context.Context, key *entities.APIKey) error
+	// GetAPIKeyByHash returns the key with hash, including revoked and
+	// expired keys; an unknown hash is an ErrInvalidAPIKey error
+	GetAPIKeyByHash(ctx context.Context, hash string) (*entities.APIKey, error)
+	// ListAPIKeys returns every key, newest first
+	ListAPIKeys(ctx context.Context) ([]*entities.APIKey, error)
+	// RevokeAPIKey revokes the key at now. Revoking a revoked key keeps its
+	// original revocation time.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error
+	// TouchAPIKey records that the key was used at now
+	TouchAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error
+}
+
+const apiKeyColumns = "id, name, prefix, key_hash, scopes, expires_at, last_used_at, created_at, revoked_at"
+
+// nullableTimeArg converts t for dialect, or to NULL when nil
+func nullableTimeArg(dialect sqlDialect, t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return dialect.timeArg(*t)
+}
+
+func createAPIKey(ctx context.Context, db dbtx, dialect sqlDialect, key *entities.APIKey) error {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		INSERT INTO api_keys (id, name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES (` + b.arg(key.ID) + `, ` + b.arg(key.Name) + `, ` + b.arg(key.Prefix) + `, ` + b.arg(key.KeyHash) + `, ` +
+		b.arg(strings.Join(key.Scopes, " ")) + `, ` + b.arg(nullableTimeArg(dialect, key.ExpiresAt)) + `, ` +
+		b.arg(dialect.timeArg(key.CreatedAt)) + `)`
+
+	if _, err := db.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to create API key", err)
+	}
+	return nil
+}
+
+func getAPIKeyByHash(ctx context.Context, db dbtx, dialect sqlDialect, hash string) (*entities.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE key_hash = ` + dialect.placeholder(1)
+
+	key, err := scanAPIKey(db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.NewUnauthorizedError("API key not found", entities.ErrInvalidAPIKey)
+		}
+		return nil, entities.NewInternalError("failed to get API key", err)
+	}
+	return key, nil
+}
+
+func listAPIKeys(ctx context.Context, db dbtx) ([]*entities.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys ORDER BY created_at DESC, id DESC`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to list API keys", err)
+	}
+	defer rows.Close()
+
+	var keys []*entities.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, entities.NewInternalError("failed to scan API key", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, entities.NewInternalError("failed to iterate API keys", err)
+	}
+	return keys, nil
+}
+
+func revokeAPIKey(ctx context.Context, db dbtx, dialect sqlDialect, id uuid.UUID, now time.Time) error {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ` + b.arg(dialect.timeArg(now)) + `)
+		WHERE id = ` + b.arg(id)
+
+	result, err := db.ExecContext(ctx, query, b.args...)
+	if err != nil {
+		return entities.NewInternalError("failed to revoke API key", err)
+	}
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return entities.NewInternalError("failed to revoke API key", err)
+	}
+	if revoked == 0 {
+		return entities.NewNotFoundError("API key not found", entities.ErrAPIKeyNotFound)
+	}
+	return nil
+}
+
+func touchAPIKey(ctx context.Context, db dbtx, dialect sqlDialect, id uuid.UUID, now time.Time) error {
+	b := &queryBuilder{dialect: dialect}
+	query := `UPDATE api_keys SET last_used_at = ` + b.arg(dialect.timeArg(now)) + ` WHERE id = ` + b.arg(id)
+
+	if _, err := db.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to record API key use", err)
+	}
+	return nil
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row interface {
+	Scan(dest ...interface{}) error
+}) (*entities.APIKey, error) {
+	var (
+		key                              = &entities.APIKey{}
+		scopes                           string
+		expiresAt, lastUsedAt, revokedAt sql.NullTime
+	)
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&expiresAt,
+		&lastUsedAt,
+		&key.CreatedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	key.ExpiresAt = nullTimePtr(expiresAt)
+	key.LastUsedAt = nullTimePtr(lastUsedAt)
+	key.RevokedAt = nullTimePtr(revokedAt)
+	return key, nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func (r *UserRepositoryImpl) CreateAPIKey(ctx context.Context, key *entities.APIKey) error {
+	return createAPIKey(ctx, conn(ctx, r.db), postgresDialect, key)
+}
+
+func (r *UserRepositoryImpl) GetAPIKeyByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	return getAPIKeyByHash(ctx, conn(ctx, r.db), postgresDialect, hash)
+}
+
+func (r *UserRepositoryImpl) ListAPIKeys(ctx context.Context) ([]*entities.APIKey, error) {
+	return listAPIKeys(ctx, conn(ctx, r.db))
+}
+
+func (r *UserRepositoryImpl) RevokeAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return revokeAPIKey(ctx, conn(ctx, r.db), postgresDialect, id, now)
+}
+
+func (r *UserRepositoryImpl) TouchAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return touchAPIKey(ctx, conn(ctx, r.db), postgresDialect, id, now)
+}
+
+func (r *UserSQLiteRepository) CreateAPIKey(ctx context.Context, key *entities.APIKey) error {
+	return createAPIKey(ctx, conn(ctx, r.db), sqliteDialect, key)
+}
+
+func (r *UserSQLiteRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	return getAPIKeyByHash(ctx, conn(ctx, r.db), sqliteDialect, hash)
+}
+
+func (r *UserSQLiteRepository) ListAPIKeys(ctx context.Context) ([]*entities.APIKey, error) {
+	return listAPIKeys(ctx, conn(ctx, r.db))
+}
+
+func (r *UserSQLiteRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return revokeAPIKey(ctx, conn(ctx, r.db), sqliteDialect, id, now)
+}
+
+func (r *UserSQLiteRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	return touchAPIKey(ctx, conn(ctx, r.db), sqliteDialect, id, now)
+}
+
+func (r *UserMemoryRepository) CreateAPIKey(ctx context.Context, key *entities.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.apiKeys = append(r.apiKeys, copyAPIKey(key))
+	return nil
+}
+
+func (r *UserMemoryRepository) GetAPIKeyByHash(ctx context.Context, hash string) (*entities.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, key := range r.apiKeys {
+		if key.KeyHash == hash {
+			return copyAPIKey(key), nil
+		}
+	}
+	return nil, entities.NewUnauthorizedError("API key not found", entities.ErrInvalidAPIKey)
+}
+
+func (r *UserMemoryRepository) ListAPIKeys(ctx context.Context) ([]*entities.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*entities.APIKey, len(r.apiKeys))
+	for i, key := range r.apiKeys {
+		keys[i] = copyAPIKey(key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.After(keys[j].CreatedAt)
+		}
+		return bytes.Compare(keys[i].ID[:], keys[j].ID[:]) > 0
+	})
+	return keys, nil
+}
+
+func (r *UserMemoryRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.apiKeys {
+		if key.ID == id {
+			if key.RevokedAt == nil {
+				revokedAt := now
+				key.RevokedAt = &revokedAt
+			}
+			return nil
+		}
+	}
+	return entities.NewNotFoundError("API key not found", entities.ErrAPIKeyNotFound)
+}
+
+func (r *UserMemoryRepository) TouchAPIKey(ctx context.Context, id uuid.UUID, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, key := range r.apiKeys {
+		if key.ID == id {
+			lastUsedAt := now
+			key.LastUsedAt = &lastUsedAt
+		}
+	}
+	return nil
+}
+
+// copyAPIKey copies key so callers cannot modify the stored key
+func copyAPIKey(key *entities.APIKey) *entities.APIKey {
+	copied := *key
+	copied.Scopes = append([]string(nil), key.Scopes...)
+	return &copied
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAPIKeys runs the APIKeyRepositoryInterface contract against a
+// repository implementation
+func testAPIKeys(t *testing.T, repo APIKeyRepositoryInterface) {
+	ctx := context.Background()
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	older, olderKey, err := entities.NewAPIKey("billing", []string{entities.ScopeUsersWrite, entities.ScopeUsersRead}, &expiresAt)
+	require.NoError(t, err)
+	older.CreatedAt = older.CreatedAt.Add(-time.Minute)
+	newer, _, err := entities.NewAPIKey("reporting", []string{entities.ScopeAuditRead}, nil)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateAPIKey(ctx, older))
+	require.NoError(t, repo.CreateAPIKey(ctx, newer))
+
+	t.Run("should get a key by hash", func(t *testing.T) {
+		stored, err := repo.GetAPIKeyByHash(ctx, entities.HashAPIKey(olderKey))
+		require.NoError(t, err)
+		assert.Equal(t, older.ID, stored.ID)
+		assert.Equal(t, "billing", stored.Name)
+		assert.Equal(t, older.Prefix, stored.Prefix)
+		assert.Equal(t, []string{entities.ScopeUsersRead, entities.ScopeUsersWrite}, stored.Scopes)
+		require.NotNil(t, stored.ExpiresAt)
+		assert.WithinDuration(t, expiresAt, *stored.ExpiresAt, time.Second)
+		assert.Nil(t, stored.LastUsedAt)
+		assert.Nil(t, stored.RevokedAt)
+	})
+
+	t.Run("should reject unknown keys", func(t *testing.T) {
+		key, err := repo.GetAPIKeyByHash(ctx, entities.HashAPIKey("unknown"))
+		assert.Nil(t, key)
+		assert.ErrorIs(t, err, entities.ErrInvalidAPIKey)
+	})
+
+	t.Run("should list keys newest first", func(t *testing.T) {
+		keys, err := repo.ListAPIKeys(ctx)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, newer.ID, keys[0].ID)
+		assert.Nil(t, keys[0].ExpiresAt)
+		assert.Equal(t, older.ID, keys[1].ID)
+	})
+
+	t.Run("should record use", func(t *testing.T) {
+		usedAt := time.Now()
+		require.NoError(t, repo.TouchAPIKey(ctx, older.ID, usedAt))
+
+		stored, err := repo.GetAPIKeyByHash(ctx, older.KeyHash)
+		require.NoError(t, err)
+		require.NotNil(t, stored.LastUsedAt)
+		assert.WithinDuration(t, usedAt, *stored.LastUsedAt, time.Second)
+	})
+
+	t.Run("should revoke once", func(t *testing.T) {
+		revokedAt := time.Now()
+		require.NoError(t, repo.RevokeAPIKey(ctx, older.ID, revokedAt))
+		require.NoError(t, repo.RevokeAPIKey(ctx, older.ID, revokedAt.Add(time.Hour)))
+
+		stored, err := repo.GetAPIKeyByHash(ctx, older.KeyHash)
+		require.NoError(t, err)
+		require.NotNil(t, stored.RevokedAt)
+		assert.WithinDuration(t, revokedAt, *stored.RevokedAt, time.Second)
+
+		err = repo.RevokeAPIKey(ctx, uuid.New(), revokedAt)
+		assert.True(t, entities.IsNotFoundError(err))
+		assert.ErrorIs(t, err, entities.ErrAPIKeyNotFound)
+	})
+}
+
+func TestUserMemoryRepository_APIKeys(t *testing.T) {
+	testAPIKeys(t, NewUserMemoryRepository())
+}
+
+func TestUserSQLiteRepository_APIKeys(t *testing.T) {
+	testAPIKeys(t, newSQLiteTestRepository(t))
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// AuditFilter narrows the records returned by ListAudit. Zero-valued fields
+// are ignored; From is inclusive and To exclusive.
+type AuditFilter struct {
+	UserID    uuid.UUID
+	Actor     string
+	Action    entities.AuditAction
+	RequestID string
+	From      time.Time
+	To        time.Time
+}
+
+// AuditListParams selects a newest-first page of audit records
+type AuditListParams struct {
+	Limit  int
+	Offset int
+	Filter AuditFilter
+}
+
+// auditWhere renders the WHERE clause for filter, or "" when it is empty
+func (b *queryBuilder) auditWhere(filter AuditFilter) string {
+	var conditions []string
+
+	if filter.UserID != uuid.Nil {
+		conditions = append(conditions, "user_id = "+b.arg(filter.UserID))
+	}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+b.arg(filter.Actor))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+b.arg(string(filter.Action)))
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = "+b.arg(filter.RequestID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+b.arg(b.dialect.timeArg(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+b.arg(b.dialect.timeArg(filter.To)))
+	}
+
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// buildAuditListQuery renders the page query for params
+func buildAuditListQuery(dialect sqlDialect, params AuditListParams) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	query := clauses(
+		"SELECT id, user_id, action, actor, request_id, before, after, created_at, COUNT(*) OVER() AS total_count FROM user_audit_log",
+		b.auditWhere(params.Filter),
+		"ORDER BY created_at DESC, id DESC",
+		"LIMIT "+b.arg(params.Limit)+" OFFSET "+b.arg(params.Offset),
+	)
+	return query, b.args
+}
+
+// buildAuditCountQuery renders a query counting the records matching filter
+func buildAuditCountQuery(dialect sqlDialect, filter AuditFilter) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	return clauses("SELECT COUNT(*) FROM user_audit_log", b.auditWhere(filter)), b.args
+}
+
+// changeLog says how a SQL repository records the changes it makes
+type changeLog struct {
+	dialect sqlDialect
+	// skipEvents leaves user events out of the outbox
+	skipEvents bool
+}
+
+// withChangeTx runs write in a transaction and stores the audit record it
+// returns, and the event announcing the change, in the same transaction so a
+// change is never saved unaudited or unannounced. It joins the transaction
+// carried by ctx, if any, and otherwise commits its own.
+func withChangeTx(ctx context.Context, db *sql.DB, log changeLog, write func(tx dbtx) (*entities.AuditRecord, error)) error {
+	return withChangesTx(ctx, db, log, func(tx dbtx) ([]*entities.AuditRecord, error) {
+		record, err := write(tx)
+		if err != nil {
+			return nil, err
+		}
+		return []*entities.AuditRecord{record}, nil
+	})
+}
+
+// withChangesTx is withChangeTx for writes changing several users at once
+func withChangesTx(ctx context.Context, db *sql.DB, log changeLog, write func(tx dbtx) ([]*entities.AuditRecord, error)) error {
+	if tx := txFromContext(ctx); tx != nil {
+		return recordChanges(ctx, traced(tx), log, write)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return entities.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := recordChanges(ctx, traced(tx), log, write); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entities.NewInternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+func recordChanges(ctx context.Context, tx dbtx, log changeLog, write func(tx dbtx) ([]*entities.AuditRecord, error)) error {
+	records, err := write(tx)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := insertAuditRecords(ctx, tx, log.dialect, records); err != nil {
+		return err
+	}
+	if log.skipEvents {
+		return nil
+	}
+
+	events := make([]*entities.UserEvent, len(records))
+	for i, record := range records {
+		events[i] = entities.NewUserEvent(record)
+	}
+	return insertOutboxEvents(ctx, tx, log.dialect, events)
+}
+
+// insertAuditRecords writes records with a single multi-row INSERT
+func insertAuditRecords(ctx context.Context, tx dbtx, dialect sqlDialect, records []*entities.AuditRecord) error {
+	b := &queryBuilder{dialect: dialect}
+	rows := make([]string, len(records))
+	for i, record := range records {
+		before, err := marshalSnapshot(record.Before)
+		if err != nil {
+			return err
+		}
+		after, err := marshalSnapshot(record.After)
+		if err != nil {
+			return err
+		}
+
+		values := []string{
+			b.arg(record.ID),
+			b.arg(record.UserID),
+			b.arg(string(record.Action)),
+			b.arg(record.Actor),
+			b.arg(record.RequestID),
+			b.arg(before),
+			b.arg(after),
+			b.arg(dialect.timeArg(record.CreatedAt)),
+		}
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+	query := `
+		INSERT INTO user_audit_log (id, user_id, action, actor, request_id, before, after, created_at)
+		VALUES ` + strings.Join(rows, ", ")
+
+	if _, err := tx.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to write audit record", err)
+	}
+	return nil
+}
+
+// marshalSnapshot encodes a user snapshot as JSON text, or NULL for nil
+func marshalSnapshot(user *entities.User) (interface{}, error) {
+	if user == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to encode audit snapshot", err)
+	}
+	return string(data), nil
+}
+
+func unmarshalSnapshot(data sql.NullString) (*entities.User, error) {
+	if !data.Valid {
+		return nil, nil
+	}
+	user := &entities.User{}
+	if err := json.Unmarshal([]byte(data.String), user); err != nil {
+		return nil, entities.NewInternalError("failed to decode audit snapshot", err)
+	}
+	return user, nil
+}
+
+// scanAuditPage scans audit rows followed by a total_count column
+func scanAuditPage(rows *sql.Rows) ([]*entities.AuditRecord, int, error) {
+	var (
+		records []*entities.AuditRecord
+		total   int
+	)
+	for rows.Next() {
+		var (
+			record        = &entities.AuditRecord{}
+			action        string
+			before, after sql.NullString
+		)
+		err := rows.Scan(
+			&record.ID,
+			&record.UserID,
+			&action,
+			&record.Actor,
+			&record.RequestID,
+			&before,
+			&after,
+			&record.CreatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, entities.NewInternalError("failed to scan audit record", err)
+		}
+		record.Action = entities.AuditAction(action)
+		if record.Before, err = unmarshalSnapshot(before); err != nil {
+			return nil, 0, err
+		}
+		if record.After, err = unmarshalSnapshot(after); err != nil {
+			return nil, 0, err
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, entities.NewInternalError("error iterating rows", err)
+	}
+
+	return records, total, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAuditListQuery(t *testing.T) {
+	userID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	t.Run("should omit WHERE without filters", func(t *testing.T) {
+		query, args := buildAuditListQuery(postgresDialect, AuditListParams{Limit: 10})
+
+		assert.Equal(t, "SELECT id, user_id, action, actor, request_id, before, after, created_at, COUNT(*) OVER() AS total_count FROM user_audit_log ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2", query)
+		assert.Equal(t, []interface{}{10, 0}, args)
+	})
+
+	t.Run("should combine filters in order", func(t *testing.T) {
+		filter := AuditFilter{UserID: userID, Actor: "admin", Action: entities.AuditActionDelete, RequestID: "req-1", From: from, To: from.Add(time.Hour)}
+		query, args := buildAuditListQuery(sqliteDialect, AuditListParams{Limit: 5, Offset: 5, Filter: filter})
+
+		assert.Contains(t, query, "WHERE user_id = ? AND actor = ? AND action = ? AND request_id = ? AND created_at >= ? AND created_at < ?")
+		assert.Equal(t, []interface{}{userID, "admin", "delete", "req-1", from.UTC(), from.Add(time.Hour).UTC(), 5, 5}, args)
+	})
+}
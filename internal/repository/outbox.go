@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// DeliverFunc hands one event to its destination
+type DeliverFunc func(ctx context.Context, event *entities.UserEvent) error
+
+// BackoffFunc returns how long to wait before retrying an event that has failed attempts times
+type BackoffFunc func(attempts int) time.Duration
+
+// OutboxRepositoryInterface gives a relay access to the events written
+// alongside every UserRepositoryInterface mutation
+type OutboxRepositoryInterface interface {
+	// Dispatch claims up to limit due events, oldest first, for lease and
+	// passes each to deliver. Delivered events are marked published and
+	// failed ones are retried after backoff. Events queued behind a pending
+	// event of the same user wait for it, so each user's events are
+	// delivered in order. Claimed events whose outcome is never recorded are
+	// retried once the lease expires. It returns the number of events claimed.
+	Dispatch(ctx context.Context, limit int, lease time.Duration, deliver DeliverFunc, backoff BackoffFunc) (int, error)
+
+	// Prune deletes events published before publishedBefore and returns how
+	// many it deleted
+	Prune(ctx context.Context, publishedBefore time.Time) (int, error)
+}
+
+// RepositoryOption configures optional UserRepositoryInterface behaviour
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	skipEvents bool
+}
+
+// WithoutEvents stops queueing user events in the outbox, for deployments
+// where no relay publishes them. Audit records are still written.
+func WithoutEvents() RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.skipEvents = true
+	}
+}
+
+// pendingEvent is a claimed outbox row
+type pendingEvent struct {
+	event    *entities.UserEvent
+	attempts int
+}
+
+// insertOutboxEvents queues events with a single multi-row INSERT
+func insertOutboxEvents(ctx context.Context, tx dbtx, dialect sqlDialect, events []*entities.UserEvent) error {
+	b := &queryBuilder{dialect: dialect}
+	rows := make([]string, len(events))
+	for i, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return entities.NewInternalError("failed to encode event", err)
+		}
+
+		values := []string{
+			b.arg(event.ID),
+			b.arg(string(event.Type)),
+			b.arg(event.UserID),
+			b.arg(string(payload)),
+			b.arg(dialect.timeArg(event.OccurredAt)),
+			b.arg(dialect.timeArg(event.OccurredAt)),
+		}
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+	query := `
+		INSERT INTO user_outbox (id, event_type, user_id, payload, created_at, next_attempt_at)
+		VALUES ` + strings.Join(rows, ", ")
+
+	if _, err := tx.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to write outbox event", err)
+	}
+	return nil
+}
+
+// buildClaimQuery selects due events that are not queued behind a pending
+// event of the same user that is still backing off
+func buildClaimQuery(dialect sqlDialect, now time.Time, limit int) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		SELECT o.id, o.payload, o.attempts
+		FROM user_outbox o
+		WHERE o.published_at IS NULL
+			AND o.next_attempt_at <= ` + b.arg(dialect.timeArg(now)) + `
+			AND NOT EXISTS (
+				SELECT 1 FROM user_outbox earlier
+				WHERE earlier.user_id = o.user_id
+					AND earlier.published_at IS NULL
+					AND earlier.next_attempt_at > ` + b.arg(dialect.timeArg(now)) + `
+					AND earlier.created_at < o.created_at)
+		ORDER BY o.created_at, o.id
+		LIMIT ` + b.arg(limit) + dialect.skipLocked
+	return query, b.args
+}
+
+// dispatchOutbox implements Dispatch for the SQL repositories. Events are
+// claimed in a short transaction that pushes their next_attempt_at past the
+// lease, so deliveries to slow subscribers hold neither row locks nor a
+// connection, and each outcome is then recorded with its own UPDATE. Events
+// whose relay dies mid-batch become due again once the lease runs out.
+func dispatchOutbox(ctx context.Context, db *sql.DB, dialect sqlDialect, limit int, lease time.Duration, deliver DeliverFunc, backoff BackoffFunc) (int, error) {
+	pending, err := claimOutbox(ctx, db, dialect, time.Now(), limit, lease)
+	if err != nil {
+		return 0, err
+	}
+
+	ph := dialect.placeholder
+	dbConn := traced(db)
+	published := `UPDATE user_outbox SET published_at = ` + ph(1) + `, attempts = attempts + 1 WHERE id = ` + ph(2)
+	failed := `UPDATE user_outbox SET next_attempt_at = ` + ph(1) + `, attempts = attempts + 1, last_error = ` + ph(2) + ` WHERE id = ` + ph(3)
+	released := `UPDATE user_outbox SET next_attempt_at = ` + ph(1) + ` WHERE id = ` + ph(2)
+
+	blocked := make(map[uuid.UUID]bool)
+	for _, p := range pending {
+		if blocked[p.event.UserID] {
+			// Hand the lease back; the failed event keeps it queued
+			if _, err := dbConn.ExecContext(ctx, released, dialect.timeArg(time.Now()), p.event.ID); err != nil {
+				return 0, entities.NewInternalError("failed to release outbox event", err)
+			}
+			continue
+		}
+
+		if deliverErr := deliver(ctx, p.event); deliverErr != nil {
+			blocked[p.event.UserID] = true
+			retryAt := time.Now().Add(backoff(p.attempts + 1))
+			if _, err := dbConn.ExecContext(ctx, failed, dialect.timeArg(retryAt), deliverErr.Error(), p.event.ID); err != nil {
+				return 0, entities.NewInternalError("failed to reschedule outbox event", err)
+			}
+			continue
+		}
+
+		if _, err := dbConn.ExecContext(ctx, published, dialect.timeArg(time.Now()), p.event.ID); err != nil {
+			return 0, entities.NewInternalError("failed to mark outbox event published", err)
+		}
+	}
+
+	return len(pending), nil
+}
+
+// claimOutbox leases up to limit due events until now plus lease and commits
+func claimOutbox(ctx context.Context, db *sql.DB, dialect sqlDialect, now time.Time, limit int, lease time.Duration) ([]*pendingEvent, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	query, args := buildClaimQuery(dialect, now, limit)
+	txConn := traced(tx)
+	pending, err := claimEvents(ctx, txConn, query, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, nil
+	}
+
+	leaseQuery, leaseArgs := buildLeaseQuery(dialect, now.Add(lease), pending)
+	if _, err := txConn.ExecContext(ctx, leaseQuery, leaseArgs...); err != nil {
+		return nil, entities.NewInternalError("failed to lease outbox events", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, entities.NewInternalError("failed to commit transaction", err)
+	}
+	return pending, nil
+}
+
+// buildLeaseQuery postpones the claimed events until leasedUntil
+func buildLeaseQuery(dialect sqlDialect, leasedUntil time.Time, pending []*pendingEvent) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	until := b.arg(dialect.timeArg(leasedUntil))
+	ids := make([]string, len(pending))
+	for i, p := range pending {
+		ids[i] = b.arg(p.event.ID)
+	}
+	return `UPDATE user_outbox SET next_attempt_at = ` + until + ` WHERE id IN (` + strings.Join(ids, ", ") + `)`, b.args
+}
+
+// pruneOutbox implements Prune for the SQL repositories
+func pruneOutbox(ctx context.Context, db *sql.DB, dialect sqlDialect, publishedBefore time.Time) (int, error) {
+	query := `DELETE FROM user_outbox WHERE published_at < ` + dialect.placeholder(1)
+	result, err := traced(db).ExecContext(ctx, query, dialect.timeArg(publishedBefore))
+	if err != nil {
+		return 0, entities.NewInternalError("failed to prune outbox events", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, entities.NewInternalError("failed to prune outbox events", err)
+	}
+	return int(deleted), nil
+}
+
+func claimEvents(ctx context.Context, tx dbtx, query string, args []interface{}) ([]*pendingEvent, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to claim outbox events", err)
+	}
+	defer rows.Close()
+
+	var pending []*pendingEvent
+	for rows.Next() {
+		var (
+			id      uuid.UUID
+			payload string
+			p       = &pendingEvent{event: &entities.UserEvent{}}
+		)
+		if err := rows.Scan(&id, &payload, &p.attempts); err != nil {
+			return nil, entities.NewInternalError("failed to scan outbox event", err)
+		}
+		if err := json.Unmarshal([]byte(payload), p.event); err != nil {
+			return nil, entities.NewInternalError("failed to decode outbox event", err)
+		}
+		p.event.ID = id
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entities.NewInternalError("error iterating rows", err)
+	}
+	return pending, nil
+}
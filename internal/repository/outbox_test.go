@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildClaimQuery(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.FixedZone("WIB", 7*60*60))
+
+	t.Run("should lock claimed rows on postgres", func(t *testing.T) {
+		query, args := buildClaimQuery(postgresDialect, now, 10)
+
+		assert.Contains(t, query, "AND o.next_attempt_at <= $1")
+		assert.Contains(t, query, "AND earlier.next_attempt_at > $2")
+		assert.Contains(t, query, "LIMIT $3\n\t\tFOR UPDATE OF o SKIP LOCKED")
+		assert.Equal(t, []interface{}{now, now, 10}, args)
+	})
+
+	t.Run("should not lock on sqlite", func(t *testing.T) {
+		query, args := buildClaimQuery(sqliteDialect, now, 10)
+
+		assert.NotContains(t, query, "FOR UPDATE")
+		assert.Equal(t, []interface{}{now.UTC(), now.UTC(), 10}, args)
+	})
+}
+
+type outboxTestRepository interface {
+	UserRepositoryInterface
+	OutboxRepositoryInterface
+}
+
+// testDispatch exercises Dispatch against a freshly created repository
+func testDispatch(t *testing.T, newRepo func(t *testing.T) outboxTestRepository) {
+	ctx := context.Background()
+	noBackoff := func(int) time.Duration { return 0 }
+
+	t.Run("should deliver every change in order once", func(t *testing.T) {
+		repo := newRepo(t)
+		john := newTestUser("John Doe", "john@example.com", time.Now())
+		jane := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, john))
+		require.NoError(t, john.UpdateName("John Smith"))
+		require.NoError(t, repo.Update(ctx, john))
+		require.NoError(t, repo.Create(ctx, jane))
+		require.NoError(t, repo.Delete(ctx, john.ID, 0))
+
+		var delivered []*entities.UserEvent
+		deliver := func(ctx context.Context, event *entities.UserEvent) error {
+			delivered = append(delivered, event)
+			return nil
+		}
+
+		claimed, err := repo.Dispatch(ctx, 10, time.Minute, deliver, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 4, claimed)
+		require.Len(t, delivered, 4)
+		assert.Equal(t, entities.UserCreated, delivered[0].Type)
+		assert.Equal(t, entities.UserUpdated, delivered[1].Type)
+		assert.Equal(t, "John Smith", delivered[1].User.Name)
+		assert.Equal(t, jane.ID, delivered[2].UserID)
+		assert.Equal(t, entities.UserDeleted, delivered[3].Type)
+		assert.Equal(t, john.ID, delivered[3].User.ID)
+
+		claimed, err = repo.Dispatch(ctx, 10, time.Minute, deliver, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 0, claimed)
+		assert.Len(t, delivered, 4)
+	})
+
+	t.Run("should hold a user's events behind a failed one until it is retried", func(t *testing.T) {
+		repo := newRepo(t)
+		john := newTestUser("John Doe", "john@example.com", time.Now())
+		jane := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, john))
+		require.NoError(t, repo.Create(ctx, jane))
+		require.NoError(t, john.UpdateName("John Smith"))
+		require.NoError(t, repo.Update(ctx, john))
+
+		failing := true
+		var delivered []*entities.UserEvent
+		deliver := func(ctx context.Context, event *entities.UserEvent) error {
+			if failing && event.UserID == john.ID {
+				return errors.New("subscriber unavailable")
+			}
+			delivered = append(delivered, event)
+			return nil
+		}
+		var attempts []int
+		backoff := func(n int) time.Duration {
+			attempts = append(attempts, n)
+			return 50 * time.Millisecond
+		}
+
+		_, err := repo.Dispatch(ctx, 10, time.Minute, deliver, backoff)
+		require.NoError(t, err)
+		require.Len(t, delivered, 1)
+		assert.Equal(t, jane.ID, delivered[0].UserID)
+		assert.Equal(t, []int{1}, attempts)
+
+		claimed, err := repo.Dispatch(ctx, 10, time.Minute, deliver, backoff)
+		require.NoError(t, err)
+		assert.Equal(t, 0, claimed)
+
+		time.Sleep(60 * time.Millisecond)
+		failing = false
+		claimed, err = repo.Dispatch(ctx, 10, time.Minute, deliver, backoff)
+		require.NoError(t, err)
+		assert.Equal(t, 2, claimed)
+		require.Len(t, delivered, 3)
+		assert.Equal(t, entities.UserCreated, delivered[1].Type)
+		assert.Equal(t, entities.UserUpdated, delivered[2].Type)
+	})
+
+	t.Run("should not hold the database while delivering", func(t *testing.T) {
+		repo := newRepo(t)
+		john := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, john))
+
+		var nested int
+		deliver := func(ctx context.Context, event *entities.UserEvent) error {
+			// A second relay polling mid-delivery finds the event leased
+			claimed, err := repo.Dispatch(ctx, 10, time.Minute, func(context.Context, *entities.UserEvent) error {
+				nested++
+				return nil
+			}, noBackoff)
+			require.NoError(t, err)
+			assert.Equal(t, 0, claimed)
+
+			jane := newTestUser("Jane Doe", "jane@example.com", time.Now())
+			return repo.Create(ctx, jane)
+		}
+
+		claimed, err := repo.Dispatch(ctx, 10, time.Minute, deliver, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+		assert.Equal(t, 0, nested)
+	})
+
+	t.Run("should let another relay claim events whose lease expired", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTestUser("John Doe", "john@example.com", time.Now())))
+
+		var redelivered int
+		slow := func(ctx context.Context, event *entities.UserEvent) error {
+			time.Sleep(60 * time.Millisecond)
+			claimed, err := repo.Dispatch(ctx, 10, time.Minute, func(context.Context, *entities.UserEvent) error {
+				redelivered++
+				return nil
+			}, noBackoff)
+			require.NoError(t, err)
+			assert.Equal(t, 1, claimed)
+			return nil
+		}
+
+		claimed, err := repo.Dispatch(ctx, 10, 50*time.Millisecond, slow, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+		assert.Equal(t, 1, redelivered)
+	})
+
+	t.Run("should claim at most limit events", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			require.NoError(t, repo.Create(ctx, newTestUser("User", email, time.Now())))
+		}
+		deliver := func(context.Context, *entities.UserEvent) error { return nil }
+
+		claimed, err := repo.Dispatch(ctx, 2, time.Minute, deliver, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 2, claimed)
+
+		claimed, err = repo.Dispatch(ctx, 2, time.Minute, deliver, noBackoff)
+		require.NoError(t, err)
+		assert.Equal(t, 1, claimed)
+	})
+}
+
+// testWithoutEvents checks that repo, built WithoutEvents, audits changes
+// without queueing events
+func testWithoutEvents(t *testing.T, repo outboxTestRepository) {
+	ctx := context.Background()
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID, 0))
+
+	_, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: user.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	claimed, err := repo.Dispatch(ctx, 10, time.Minute, func(context.Context, *entities.UserEvent) error { return nil }, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, claimed)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// RefreshTokenRepositoryInterface stores refresh tokens by the hash of their
+// value
+type RefreshTokenRepositoryInterface interface {
+	CreateRefreshToken(ctx context.Context, token *entities.RefreshToken) error
+	// ConsumeRefreshToken marks the token with hash as used at now and
+	// returns it. A token that was already used or revoked is returned with
+	// an ErrRefreshTokenReused error, so its family can be revoked.
+	ConsumeRefreshToken(ctx context.Context, hash string, now time.Time) (*entities.RefreshToken, error)
+	// RevokeRefreshTokenFamily revokes every token rotated from one login
+	RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error
+}
+
+func createRefreshToken(ctx context.Context, db dbtx, dialect sqlDialect, token *entities.RefreshToken) error {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES (` + b.arg(token.ID) + `, ` + b.arg(token.UserID) + `, ` + b.arg(token.FamilyID) + `, ` +
+		b.arg(token.TokenHash) + `, ` + b.arg(dialect.timeArg(token.ExpiresAt)) + `, ` + b.arg(dialect.timeArg(token.CreatedAt)) + `)`
+
+	if _, err := db.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to create refresh token", err)
+	}
+	return nil
+}
+
+func consumeRefreshToken(ctx context.Context, db dbtx, dialect sqlDialect, hash string, now time.Time) (*entities.RefreshToken, error) {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		UPDATE refresh_tokens SET used_at = ` + b.arg(dialect.timeArg(now)) + `
+		WHERE token_hash = ` + b.arg(hash) + ` AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := db.ExecContext(ctx, query, b.args...)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to consume refresh token", err)
+	}
+	consumed, err := result.RowsAffected()
+	if err != nil {
+		return nil, entities.NewInternalError("failed to consume refresh token", err)
+	}
+
+	token, err := getRefreshToken(ctx, db, dialect, hash)
+	if err != nil {
+		return nil, err
+	}
+	if consumed == 0 {
+		return token, entities.NewUnauthorizedError("refresh token already used or revoked", entities.ErrRefreshTokenReused)
+	}
+	return token, nil
+}
+
+func getRefreshToken(ctx context.Context, db dbtx, dialect sqlDialect, hash string) (*entities.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = ` + dialect.placeholder(1)
+
+	var (
+		token             = &entities.RefreshToken{}
+		usedAt, revokedAt sql.NullTime
+	)
+	err := db.QueryRowContext(ctx, query, hash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&usedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.NewUnauthorizedError("refresh token not found", entities.ErrInvalidToken)
+		}
+		return nil, entities.NewInternalError("failed to get refresh token", err)
+	}
+
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return token, nil
+}
+
+func revokeRefreshTokenFamily(ctx context.Context, db dbtx, dialect sqlDialect, familyID uuid.UUID, now time.Time) error {
+	b := &queryBuilder{dialect: dialect}
+	query := `
+		UPDATE refresh_tokens SET revoked_at = ` + b.arg(dialect.timeArg(now)) + `
+		WHERE family_id = ` + b.arg(familyID) + ` AND revoked_at IS NULL`
+
+	if _, err := db.ExecContext(ctx, query, b.args...); err != nil {
+		return entities.NewInternalError("failed to revoke refresh tokens", err)
+	}
+	return nil
+}
+
+func (r *UserRepositoryImpl) CreateRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	return createRefreshToken(ctx, conn(ctx, r.db), postgresDialect, token)
+}
+
+func (r *UserRepositoryImpl) ConsumeRefreshToken(ctx context.Context, hash string, now time.Time) (*entities.RefreshToken, error) {
+	return consumeRefreshToken(ctx, conn(ctx, r.db), postgresDialect, hash, now)
+}
+
+func (r *UserRepositoryImpl) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	return revokeRefreshTokenFamily(ctx, conn(ctx, r.db), postgresDialect, familyID, now)
+}
+
+func (r *UserSQLiteRepository) CreateRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	return createRefreshToken(ctx, conn(ctx, r.db), sqliteDialect, token)
+}
+
+func (r *UserSQLiteRepository) ConsumeRefreshToken(ctx context.Context, hash string, now time.Time) (*entities.RefreshToken, error) {
+	return consumeRefreshToken(ctx, conn(ctx, r.db), sqliteDialect, hash, now)
+}
+
+func (r *UserSQLiteRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	return revokeRefreshTokenFamily(ctx, conn(ctx, r.db), sqliteDialect, familyID, now)
+}
+
+func (r *UserMemoryRepository) CreateRefreshToken(ctx context.Context, token *entities.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *token
+	r.refreshTokens[token.TokenHash] = &stored
+	return nil
+}
+
+func (r *UserMemoryRepository) ConsumeRefreshToken(ctx context.Context, hash string, now time.Time) (*entities.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.refreshTokens[hash]
+	if !exists {
+		return nil, entities.NewUnauthorizedError("refresh token not found", entities.ErrInvalidToken)
+	}
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		token := *stored
+		return &token, entities.NewUnauthorizedError("refresh token already used or revoked", entities.ErrRefreshTokenReused)
+	}
+
+	usedAt := now
+	stored.UsedAt = &usedAt
+	token := *stored
+	return &token, nil
+}
+
+func (r *UserMemoryRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID uuid.UUID, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
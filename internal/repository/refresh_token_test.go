@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRefreshTokens runs the RefreshTokenRepositoryInterface contract
+// against a repository implementation
+func testRefreshTokens(t *testing.T, repo RefreshTokenRepositoryInterface) {
+	ctx := context.Background()
+	userID, familyID := uuid.New(), uuid.New()
+
+	first, firstValue, err := entities.NewRefreshToken(userID, familyID, time.Hour)
+	require.NoError(t, err)
+	second, secondValue, err := entities.NewRefreshToken(userID, familyID, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateRefreshToken(ctx, first))
+	require.NoError(t, repo.CreateRefreshToken(ctx, second))
+
+	t.Run("should consume a token once", func(t *testing.T) {
+		consumed, err := repo.ConsumeRefreshToken(ctx, entities.HashRefreshToken(firstValue), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, consumed.ID)
+		assert.Equal(t, familyID, consumed.FamilyID)
+		assert.WithinDuration(t, first.ExpiresAt, consumed.ExpiresAt, time.Second)
+		assert.NotNil(t, consumed.UsedAt)
+
+		reused, err := repo.ConsumeRefreshToken(ctx, entities.HashRefreshToken(firstValue), time.Now())
+		assert.ErrorIs(t, err, entities.ErrRefreshTokenReused)
+		require.NotNil(t, reused)
+		assert.Equal(t, familyID, reused.FamilyID)
+	})
+
+	t.Run("should reject unknown tokens", func(t *testing.T) {
+		token, err := repo.ConsumeRefreshToken(ctx, entities.HashRefreshToken("unknown"), time.Now())
+		assert.Nil(t, token)
+		assert.ErrorIs(t, err, entities.ErrInvalidToken)
+	})
+
+	t.Run("should revoke the whole family", func(t *testing.T) {
+		require.NoError(t, repo.RevokeRefreshTokenFamily(ctx, familyID, time.Now()))
+
+		revoked, err := repo.ConsumeRefreshToken(ctx, entities.HashRefreshToken(secondValue), time.Now())
+		assert.ErrorIs(t, err, entities.ErrRefreshTokenReused)
+		require.NotNil(t, revoked)
+		assert.NotNil(t, revoked.RevokedAt)
+		assert.Nil(t, revoked.UsedAt)
+	})
+}
+
+func TestUserMemoryRepository_RefreshTokens(t *testing.T) {
+	testRefreshTokens(t, NewUserMemoryRepository())
+}
+
+func TestUserSQLiteRepository_RefreshTokens(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	testRefreshTokens(t, repo)
+}
+
+func TestUserSQLiteRepository_PasswordHash(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	ctx := context.Background()
+
+	withPassword := newTestUser("John Doe", "john@example.com", time.Now())
+	withPassword.PasswordHash = "$2a$10$hash"
+	withoutPassword := newTestUser("Jane Doe", "jane@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, withPassword))
+	require.NoError(t, repo.Create(ctx, withoutPassword))
+
+	stored, err := repo.GetByEmail(ctx, withPassword.Email)
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$hash", stored.PasswordHash)
+
+	stored, err = repo.GetByID(ctx, withoutPassword.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.PasswordHash)
+
+	// Updates store the password, so users without one can be given one
+	stored.PasswordHash = "$2a$10$other"
+	require.NoError(t, repo.Update(ctx, stored))
+	stored, err = repo.GetByID(ctx, withoutPassword.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$other", stored.PasswordHash)
+
+	batched := newTestUser("Jim Doe", "jim@example.com", time.Now())
+	batched.PasswordHash = "$2a$10$batch"
+	_, err = repo.CreateBatch(ctx, []*entities.User{batched})
+	require.NoError(t, err)
+	stored, err = repo.GetByID(ctx, batched.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$batch", stored.PasswordHash)
+}
+
+func TestUserSQLiteRepository_Role(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	ctx := context.Background()
+
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	user.Role = ""
+	require.NoError(t, repo.Create(ctx, user))
+
+	stored, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entities.DefaultRole, stored.Role)
+
+	stored.ChangeRole(entities.RoleAdmin)
+	require.NoError(t, repo.Update(ctx, stored))
+	stored, err = repo.GetByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, entities.RoleAdmin, stored.Role)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go-clean-code/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the SQL statement spans
+const tracerName = "go-clean-code/internal/repository"
+
+// tracedConn runs every statement in a span named after it, such as
+// "SELECT users". Only statements run within a trace are traced, so
+// background work like the outbox relay's polling starts no traces of its own.
+type tracedConn struct {
+	dbtx
+}
+
+func traced(c dbtx) dbtx {
+	return tracedConn{c}
+}
+
+func (c tracedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startStatement(ctx, query)
+	result, err := c.dbtx.ExecContext(ctx, query, args...)
+	endStatement(span, err)
+	return result, err
+}
+
+func (c tracedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startStatement(ctx, query)
+	rows, err := c.dbtx.QueryContext(ctx, query, args...)
+	endStatement(span, err)
+	return rows, err
+}
+
+func (c tracedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startStatement(ctx, query)
+	row := c.dbtx.QueryRowContext(ctx, query, args...)
+	endStatement(span, row.Err())
+	return row
+}
+
+// startStatement starts the span of query, or returns a nil span outside a trace
+func startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, nil
+	}
+
+	operation, collection := summarizeStatement(query)
+	name := operation
+	if collection != "" {
+		name += " " + collection
+	}
+	attrs := []attribute.KeyValue{
+		semconv.DBQuerySummary(name),
+		semconv.DBOperationName(operation),
+		semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+	}
+	if collection != "" {
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endStatement records err, unless it only means no row matched, and ends span
+func endStatement(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != sql.ErrNoRows {
+		tracing.RecordError(span, err)
+	}
+	span.End()
+}
+
+// summarizeStatement names the operation of query and the table it acts on,
+// such as SELECT and users
+func summarizeStatement(query string) (operation, collection string) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "", ""
+	}
+	operation = strings.ToUpper(words[0])
+
+	// The table follows INTO in INSERT, the verb in UPDATE and FROM otherwise
+	after := "FROM"
+	switch operation {
+	case "INSERT":
+		after = "INTO"
+	case "UPDATE":
+		after = "UPDATE"
+	}
+	for i, word := range words[:len(words)-1] {
+		if strings.EqualFold(word, after) {
+			collection = strings.Trim(words[i+1], "(),;")
+			break
+		}
+	}
+	return operation, collection
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/tracing"
+	"go-clean-code/internal/tracing/tracingtest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSummarizeStatement(t *testing.T) {
+	tests := []struct {
+		query      string
+		operation  string
+		collection string
+	}{
+		{query: "\n\t\tINSERT INTO users (id, name) VALUES ($1, $2)", operation: "INSERT", collection: "users"},
+		{query: "SELECT id, name FROM users WHERE id = $1", operation: "SELECT", collection: "users"},
+		{query: "select id, (SELECT COUNT(*) FROM users) AS total_count FROM users", operation: "SELECT", collection: "users"},
+		{query: "UPDATE user_outbox SET published_at = $1 WHERE id = $2", operation: "UPDATE", collection: "user_outbox"},
+		{query: "DELETE FROM users WHERE id = ?", operation: "DELETE", collection: "users"},
+		{query: "SELECT 1", operation: "SELECT"},
+		{query: "  ", operation: ""},
+	}
+
+	for _, tt := range tests {
+		operation, collection := summarizeStatement(tt.query)
+		assert.Equal(t, tt.operation, operation, tt.query)
+		assert.Equal(t, tt.collection, collection, tt.query)
+	}
+}
+
+func TestTracedStatements(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	repo := newSQLiteTestRepository(t)
+
+	user, err := entities.NewUser("John Doe", "john@example.com")
+	require.NoError(t, err)
+
+	t.Run("should trace every statement within a trace", func(t *testing.T) {
+		exporter.Reset()
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "UserUsecase.CreateUser")
+		require.NoError(t, repo.Create(ctx, user))
+		_, err := repo.GetByID(ctx, uuid.New())
+		assert.True(t, entities.IsNotFoundError(err))
+		parent.End()
+
+		spans := exporter.GetSpans()
+		var names []string
+		for _, span := range spans {
+			if span.Name != "UserUsecase.CreateUser" {
+				names = append(names, span.Name)
+				assert.Equal(t, parent.SpanContext().SpanID(), span.Parent.SpanID(), span.Name)
+				assert.Equal(t, trace.SpanKindClient, span.SpanKind)
+			}
+		}
+		assert.Equal(t, []string{"INSERT users", "INSERT user_audit_log", "INSERT user_outbox", "SELECT users"}, names)
+
+		insert := tracingtest.Find(spans, "INSERT users")
+		require.NotNil(t, insert)
+		attrs := tracingtest.Attributes(insert)
+		assert.Equal(t, "INSERT users", attrs["db.query.summary"])
+		assert.Equal(t, "INSERT", attrs["db.operation.name"])
+		assert.Equal(t, "users", attrs["db.collection.name"])
+		assert.Contains(t, attrs["db.query.text"], "INSERT INTO users (id, name, email")
+
+		// A missing row is an answer, not a failure
+		selectUser := tracingtest.Find(spans, "SELECT users")
+		require.NotNil(t, selectUser)
+		assert.Equal(t, codes.Unset, selectUser.Status.Code)
+	})
+
+	t.Run("should record failed statements", func(t *testing.T) {
+		exporter.Reset()
+		ctx, parent := otel.Tracer("test").Start(context.Background(), "UserUsecase.CreateUser")
+		err := repo.Create(ctx, user)
+		parent.End()
+		assert.True(t, entities.IsConflictError(err))
+
+		insert := tracingtest.Find(exporter.GetSpans(), "INSERT users")
+		require.NotNil(t, insert)
+		assert.Equal(t, codes.Error, insert.Status.Code)
+		assert.Equal(t, tracing.UnknownErrorType, tracingtest.Attributes(insert)["error.type"])
+	})
+
+	t.Run("should not trace statements outside a trace", func(t *testing.T) {
+		exporter.Reset()
+		_, err := repo.GetByID(context.Background(), user.ID)
+		require.NoError(t, err)
+		assert.Empty(t, exporter.GetSpans())
+	})
+}
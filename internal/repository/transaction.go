@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// TransactionManagerInterface runs several repository calls as one unit of work
+type TransactionManagerInterface interface {
+	// WithinTx runs fn in a transaction. Repository calls made with the
+	// context passed to fn join that transaction, which commits when fn
+	// returns nil and rolls back otherwise. fn may run more than once, so it
+	// must read everything it depends on through the repositories. A
+	// WithinTx nested in another joins the outer transaction.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// DefaultMaxTxRetries is how often SQLTransactionManager retries a unit of
+// work after a serialization failure
+const DefaultMaxTxRetries = 3
+
+// txRetryDelay is the pause before the first retry; it grows linearly
+const txRetryDelay = 10 * time.Millisecond
+
+type txKey struct{}
+
+// dbtx is the subset of *sql.DB and *sql.Tx the repositories query through
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func txFromContext(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+	return tx
+}
+
+// conn returns the transaction carried by ctx, or db outside a unit of work,
+// with its statements traced
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx := txFromContext(ctx); tx != nil {
+		return traced(tx)
+	}
+	return traced(db)
+}
+
+// SQLTransactionManager implements TransactionManagerInterface for the
+// PostgreSQL and SQLite repositories sharing db
+type SQLTransactionManager struct {
+	db         *sql.DB
+	isolation  sql.IsolationLevel
+	maxRetries int
+}
+
+// TransactionOption configures optional SQLTransactionManager behaviour
+type TransactionOption func(*SQLTransactionManager)
+
+// WithIsolationLevel sets the isolation level of every transaction.
+// SQLite ignores it; its transactions are always serializable.
+func WithIsolationLevel(level sql.IsolationLevel) TransactionOption {
+	return func(m *SQLTransactionManager) {
+		m.isolation = level
+	}
+}
+
+// WithMaxRetries sets how often a unit of work is retried after a
+// serialization failure; zero disables retries
+func WithMaxRetries(retries int) TransactionOption {
+	return func(m *SQLTransactionManager) {
+		if retries >= 0 {
+			m.maxRetries = retries
+		}
+	}
+}
+
+func NewSQLTransactionManager(db *sql.DB, opts ...TransactionOption) *SQLTransactionManager {
+	m := &SQLTransactionManager{
+		db:         db,
+		isolation:  sql.LevelDefault,
+		maxRetries: DefaultMaxTxRetries,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+func (m *SQLTransactionManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.run(ctx, fn)
+		if err == nil || attempt >= m.maxRetries || !isSerializationFailure(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(attempt+1) * txRetryDelay):
+		}
+	}
+}
+
+func (m *SQLTransactionManager) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := m.db.BeginTx(ctx, &sql.TxOptions{Isolation: m.isolation})
+	if err != nil {
+		return entities.NewInternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return entities.NewInternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// isSerializationFailure reports whether err means the transaction lost a
+// race with a concurrent one and may succeed when run again
+func isSerializationFailure(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		// serialization_failure and deadlock_detected
+		return pqErr.Code == "40001" || pqErr.Code == "40P01"
+	}
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
+
+type memoryTxKey struct{}
+
+// memoryTx collects the undo steps of a MemoryTransactionManager unit of work
+type memoryTx struct {
+	undo []func()
+}
+
+func memoryTxFromContext(ctx context.Context) *memoryTx {
+	tx, _ := ctx.Value(memoryTxKey{}).(*memoryTx)
+	return tx
+}
+
+// onRollback registers undo to run if the unit of work fails
+func (tx *memoryTx) onRollback(undo func()) {
+	tx.undo = append(tx.undo, undo)
+}
+
+// MemoryTransactionManager implements TransactionManagerInterface for
+// UserMemoryRepository by running units of work one at a time. When fn
+// fails, the changes the repository made within it are undone; other
+// callers may observe them until then.
+type MemoryTransactionManager struct {
+	mu sync.Mutex
+}
+
+func NewMemoryTransactionManager() *MemoryTransactionManager {
+	return &MemoryTransactionManager{}
+}
+
+func (m *MemoryTransactionManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if memoryTxFromContext(ctx) != nil {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := &memoryTx{}
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, tx)); err != nil {
+		for i := len(tx.undo) - 1; i >= 0; i-- {
+			tx.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestSQLTransactionManager_WithinTx(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	manager := NewSQLTransactionManager(db.DB)
+	exec := func(ctx context.Context) error {
+		_, err := conn(ctx, db.DB).ExecContext(ctx, `UPDATE users SET name = name`)
+		return err
+	}
+
+	t.Run("should commit when fn succeeds", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE users`).WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, manager.WithinTx(ctx, exec))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back and return the error of fn", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		notFound := entities.NewNotFoundError("user not found", entities.ErrUserNotFound)
+		err := manager.WithinTx(ctx, func(ctx context.Context) error { return notFound })
+		assert.Equal(t, notFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should retry after a serialization failure", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE users`).WillReturnError(&pq.Error{Code: "40001"})
+		mock.ExpectRollback()
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE users`).WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, manager.WithinTx(ctx, exec))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should give up after max retries", func(t *testing.T) {
+		manager := NewSQLTransactionManager(db.DB, WithMaxRetries(1))
+		for i := 0; i < 2; i++ {
+			mock.ExpectBegin()
+			mock.ExpectExec(`UPDATE users`).WillReturnError(&pq.Error{Code: "40P01"})
+			mock.ExpectRollback()
+		}
+
+		err := manager.WithinTx(ctx, exec)
+		assert.True(t, isSerializationFailure(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should join an outer transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE users`).WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE users`).WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := exec(ctx); err != nil {
+				return err
+			}
+			return manager.WithinTx(ctx, exec)
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSQLTransactionManager_SQLite(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	manager := NewSQLTransactionManager(repo.db)
+	ctx := context.Background()
+
+	t.Run("should see its own writes and commit them together", func(t *testing.T) {
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, user); err != nil {
+				return err
+			}
+			_, err := repo.GetByEmail(ctx, user.Email)
+			return err
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, user.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should discard the change, audit record and event on error", func(t *testing.T) {
+		user := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		failure := errors.New("abort")
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, user); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.Equal(t, failure, err)
+
+		_, err = repo.GetByID(ctx, user.ID)
+		assert.True(t, entities.IsNotFoundError(err))
+		_, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: user.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		claimed, err := repo.Dispatch(ctx, 10, time.Minute, func(context.Context, *entities.UserEvent) error { return nil }, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, claimed, "only the committed user's event should be pending")
+	})
+}
+
+func TestMemoryTransactionManager_WithinTx(t *testing.T) {
+	manager := NewMemoryTransactionManager()
+	ctx := context.Background()
+
+	t.Run("should run units of work one at a time", func(t *testing.T) {
+		var (
+			wg      sync.WaitGroup
+			running int
+			overlap bool
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = manager.WithinTx(ctx, func(ctx context.Context) error {
+					running++
+					if running > 1 {
+						overlap = true
+					}
+					time.Sleep(time.Millisecond)
+					running--
+					return nil
+				})
+			}()
+		}
+		wg.Wait()
+		assert.False(t, overlap)
+	})
+
+	t.Run("should join an outer unit of work", func(t *testing.T) {
+		calls := 0
+		err := manager.WithinTx(ctx, func(ctx context.Context) error {
+			return manager.WithinTx(ctx, func(ctx context.Context) error {
+				calls++
+				return nil
+			})
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestMemoryTransactionManager_Rollback(t *testing.T) {
+	repo := NewUserMemoryRepository()
+	manager := NewMemoryTransactionManager()
+	ctx := context.Background()
+
+	kept := newTestUser("Kept", "kept@example.com", time.Now())
+	removed := newTestUser("Removed", "removed@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, kept))
+	require.NoError(t, repo.Create(ctx, removed))
+	require.NoError(t, repo.Delete(ctx, removed.ID, 0))
+
+	failure := errors.New("abort")
+	err := manager.WithinTx(ctx, func(ctx context.Context) error {
+		require.NoError(t, kept.UpdateEmail("changed@example.com"))
+		require.NoError(t, repo.Update(ctx, kept))
+		require.NoError(t, repo.Purge(ctx, removed.ID))
+		require.NoError(t, repo.Create(ctx, newTestUser("New", "new@example.com", time.Now())))
+		return failure
+	})
+	assert.Equal(t, failure, err)
+
+	stored, err := repo.GetByEmail(ctx, "kept@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stored.Version)
+	_, err = repo.GetByEmail(ctx, "changed@example.com")
+	assert.True(t, entities.IsNotFoundError(err))
+	_, err = repo.GetByEmail(ctx, "new@example.com")
+	assert.True(t, entities.IsNotFoundError(err))
+	assert.NoError(t, repo.Restore(ctx, removed.ID))
+
+	// create, create, delete and the restore above remain
+	_, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+}
+
+func TestIsSerializationFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"postgres serialization failure", &pq.Error{Code: "40001"}, true},
+		{"postgres deadlock", &pq.Error{Code: "40P01"}, true},
+		{"postgres unique violation", &pq.Error{Code: "23505"}, false},
+		{"wrapped in a domain error", entities.NewInternalError("failed to update user", &pq.Error{Code: "40001"}), true},
+		{"sqlite busy", sqlite3.Error{Code: sqlite3.ErrBusy}, true},
+		{"other error", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isSerializationFailure(tt.err))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// buildInsertUsersQuery renders a multi-row INSERT that skips users whose ID
+// or active email is taken and returns the IDs it inserted
+func buildInsertUsersQuery(dialect sqlDialect, users []*entities.User) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	rows := make([]string, len(users))
+	for i, user := range users {
+		values := []string{
+			b.arg(user.ID),
+			b.arg(user.Name),
+			b.arg(user.Email),
+			b.arg(userRole(user)),
+			b.arg(user.Version),
+			b.arg(passwordHash(user)),
+			b.arg(dialect.timeArg(user.CreatedAt)),
+			b.arg(dialect.timeArg(user.UpdatedAt)),
+		}
+		rows[i] = "(" + strings.Join(values, ", ") + ")"
+	}
+
+	query := clauses(
+		"INSERT INTO users (id, name, email, role, version, password_hash, created_at, updated_at) VALUES",
+		strings.Join(rows, ", "),
+		"ON CONFLICT DO NOTHING RETURNING id",
+	)
+	return query, b.args
+}
+
+// createBatch implements CreateBatch for the SQL repositories
+func createBatch(ctx context.Context, db *sql.DB, log changeLog, users []*entities.User) ([]uuid.UUID, error) {
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	var created []uuid.UUID
+	err := withChangesTx(ctx, db, log, func(tx dbtx) ([]*entities.AuditRecord, error) {
+		// A retried unit of work runs this again, so start from scratch
+		created = nil
+
+		query, args := buildInsertUsersQuery(log.dialect, users)
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, entities.NewInternalError("failed to create users", err)
+		}
+		defer rows.Close()
+
+		inserted := make(map[uuid.UUID]bool, len(users))
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				return nil, entities.NewInternalError("failed to scan user ID", err)
+			}
+			inserted[id] = true
+		}
+		if err := rows.Err(); err != nil {
+			return nil, entities.NewInternalError("error iterating rows", err)
+		}
+
+		// Report in input order, which RETURNING does not promise
+		var records []*entities.AuditRecord
+		for _, user := range users {
+			if inserted[user.ID] {
+				created = append(created, user.ID)
+				records = append(records, entities.NewAuditRecord(ctx, entities.AuditActionCreate, user.ID, nil, user))
+			}
+		}
+		return records, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
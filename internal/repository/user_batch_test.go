@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqlxmock "github.com/zhashkevych/go-sqlxmock"
+)
+
+func TestBuildInsertUsersQuery(t *testing.T) {
+	now := time.Now()
+	users := []*entities.User{
+		newTestUser("John Doe", "john@example.com", now),
+		newTestUser("Jane Doe", "jane@example.com", now),
+	}
+
+	query, args := buildInsertUsersQuery(postgresDialect, users)
+
+	assert.Equal(t, "INSERT INTO users (id, name, email, role, version, password_hash, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8), ($9, $10, $11, $12, $13, $14, $15, $16) ON CONFLICT DO NOTHING RETURNING id", query)
+	assert.Len(t, args, 16)
+	assert.Equal(t, users[1].Email, args[10])
+	assert.Equal(t, string(entities.RoleMember), args[11])
+	assert.Nil(t, args[13], "users without a password are stored with a NULL hash")
+}
+
+func TestUserRepositoryImpl_CreateBatch(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+	john := newTestUser("John Doe", "john@example.com", time.Now())
+	jane := newTestUser("Jane Doe", "jane@example.com", time.Now())
+
+	t.Run("should audit and announce only the inserted users", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO users .* ON CONFLICT DO NOTHING RETURNING id`).
+			WillReturnRows(sqlxmock.NewRows([]string{"id"}).AddRow(jane.ID))
+		mock.ExpectExec(`INSERT INTO user_audit_log .* VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)$`).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO user_outbox .* VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)$`).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		created, err := repo.CreateBatch(ctx, []*entities.User{john, jane})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{jane.ID}, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should write nothing else when every user conflicts", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO users`).
+			WillReturnRows(sqlxmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		created, err := repo.CreateBatch(ctx, []*entities.User{john})
+		assert.NoError(t, err)
+		assert.Empty(t, created)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserSQLiteRepository_CreateBatch(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	ctx := context.Background()
+
+	taken := newTestUser("Taken", "taken@example.com", time.Now())
+	gone := newTestUser("Gone", "gone@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, taken))
+	require.NoError(t, repo.Create(ctx, gone))
+	require.NoError(t, repo.Delete(ctx, gone.ID, 0))
+
+	john := newTestUser("John Doe", "john@example.com", time.Now())
+	clash := newTestUser("Clash", "taken@example.com", time.Now())
+	reuse := newTestUser("Reuse", "gone@example.com", time.Now())
+
+	created, err := repo.CreateBatch(ctx, []*entities.User{john, clash, reuse})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{john.ID, reuse.ID}, created)
+
+	stored, err := repo.GetByEmail(ctx, "gone@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, reuse.ID, stored.ID)
+
+	records, _, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: reuse.ID}})
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, entities.AuditActionCreate, records[0].Action)
+}
+
+func TestUserMemoryRepository_CreateBatch(t *testing.T) {
+	repo := NewUserMemoryRepository()
+	ctx := context.Background()
+
+	taken := newTestUser("Taken", "taken@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, taken))
+
+	john := newTestUser("John Doe", "john@example.com", time.Now())
+	clash := newTestUser("Clash", "taken@example.com", time.Now())
+	twin := newTestUser("Twin", "john@example.com", time.Now())
+
+	created, err := repo.CreateBatch(ctx, []*entities.User{john, clash, twin})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{john.ID}, created)
+
+	_, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+}
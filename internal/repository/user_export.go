@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+
+	"go-clean-code/internal/entities"
+)
+
+// ExportPageSize is the number of users a UserIterator reads per query
+const ExportPageSize = 1000
+
+// UserIterator steps through users one at a time in the manner of sql.Rows.
+// Next advances to the next user, which User returns, and reports false once
+// the users are exhausted, an error occurs or the context the iterator was
+// created with is cancelled; Err then reports the error, if any.
+type UserIterator interface {
+	Next() bool
+	User() *entities.User
+	Err() error
+}
+
+// fetchPageFunc reads up to limit users sorting after the user after, or
+// from the start when after is nil
+type fetchPageFunc func(ctx context.Context, after *entities.User, limit int) ([]*entities.User, error)
+
+// pagedUserIterator implements UserIterator by reading keyset pages of
+// ExportPageSize users, so memory stays bounded and no connection is held
+// between pages. Users changed while the iteration runs may be seen in their
+// old or new position.
+type pagedUserIterator struct {
+	ctx      context.Context
+	fetch    fetchPageFunc
+	pageSize int
+	page     []*entities.User
+	pos      int
+	done     bool
+	err      error
+}
+
+func newPagedUserIterator(ctx context.Context, fetch fetchPageFunc) *pagedUserIterator {
+	return &pagedUserIterator{ctx: ctx, fetch: fetch, pageSize: ExportPageSize, pos: -1}
+}
+
+func (it *pagedUserIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if it.pos+1 < len(it.page) {
+		it.pos++
+		return true
+	}
+	if it.done {
+		return false
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+
+	var after *entities.User
+	if len(it.page) > 0 {
+		after = it.page[len(it.page)-1]
+	}
+	page, err := it.fetch(it.ctx, after, it.pageSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.page, it.pos = page, 0
+	it.done = len(page) < it.pageSize
+	return len(page) > 0
+}
+
+func (it *pagedUserIterator) User() *entities.User {
+	if it.pos < 0 || it.pos >= len(it.page) {
+		return nil
+	}
+	return it.page[it.pos]
+}
+
+func (it *pagedUserIterator) Err() error {
+	return it.err
+}
+
+// buildExportPageQuery renders the query for the page of users matching
+// filter that follows after in sort order
+func buildExportPageQuery(dialect sqlDialect, filter UserFilter, sort UserSort, after *entities.User, limit int) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	column, sort := resolveSort(sort)
+
+	var keyset []string
+	if after != nil {
+		var value interface{}
+		switch sort.Field {
+		case SortByName:
+			value = after.Name
+		case SortByEmail:
+			value = after.Email
+		default:
+			value = dialect.timeArg(after.CreatedAt)
+		}
+		comparison := ">"
+		if sort.Descending {
+			comparison = "<"
+		}
+		keyset = append(keyset, "("+column+", id) "+comparison+" ("+b.arg(value)+", "+b.arg(after.ID)+")")
+	}
+
+	query := clauses(
+		"SELECT id, name, email, role, version, created_at, updated_at FROM users",
+		b.where(filter, keyset...),
+		orderBy(sort),
+		"LIMIT "+b.arg(limit),
+	)
+	return query, b.args
+}
+
+// fetchExportPage runs the page query built by buildExportPageQuery
+func fetchExportPage(ctx context.Context, db dbtx, dialect sqlDialect, filter UserFilter, sort UserSort, after *entities.User, limit int) ([]*entities.User, error) {
+	query, args := buildExportPageQuery(dialect, filter, sort, after, limit)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to export users", err)
+	}
+	defer rows.Close()
+
+	users := make([]*entities.User, 0, limit)
+	for rows.Next() {
+		user := &entities.User{}
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.Role, &user.Version, &user.CreatedAt, &user.UpdatedAt); err != nil {
+			return nil, entities.NewInternalError("failed to scan user", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, entities.NewInternalError("error iterating rows", err)
+	}
+	return users, nil
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildExportPageQuery(t *testing.T) {
+	t.Run("should start from the beginning without a position", func(t *testing.T) {
+		query, args := buildExportPageQuery(postgresDialect, UserFilter{EmailDomain: "example.com"}, UserSort{}, nil, 1000)
+
+		assert.Equal(t, `SELECT id, name, email, role, version, created_at, updated_at FROM users WHERE deleted_at IS NULL AND email ILIKE $1 ESCAPE '\' ORDER BY created_at DESC, id DESC LIMIT $2`, query)
+		assert.Equal(t, []interface{}{"%@example.com", 1000}, args)
+	})
+
+	t.Run("should continue after the last user in sort order", func(t *testing.T) {
+		after := newTestUser("John Doe", "john@example.com", time.Now())
+
+		query, args := buildExportPageQuery(sqliteDialect, UserFilter{}, UserSort{Field: SortByName}, after, 500)
+
+		assert.Equal(t, "SELECT id, name, email, role, version, created_at, updated_at FROM users WHERE deleted_at IS NULL AND (name, id) > (?, ?) ORDER BY name ASC, id ASC LIMIT ?", query)
+		assert.Equal(t, []interface{}{"John Doe", after.ID, 500}, args)
+	})
+}
+
+func TestPagedUserIterator(t *testing.T) {
+	users := []*entities.User{
+		newTestUser("A", "a@example.com", time.Now()),
+		newTestUser("B", "b@example.com", time.Now()),
+		newTestUser("C", "c@example.com", time.Now()),
+	}
+	// fetch serves users in order, failing once failAt users have been read
+	fetch := func(failAt int) fetchPageFunc {
+		return func(ctx context.Context, after *entities.User, limit int) ([]*entities.User, error) {
+			start := 0
+			if after != nil {
+				for i, user := range users {
+					if user == after {
+						start = i + 1
+					}
+				}
+			}
+			if start >= failAt {
+				return nil, errors.New("boom")
+			}
+			return users[start:min(start+limit, len(users))], nil
+		}
+	}
+
+	t.Run("should read every user page by page", func(t *testing.T) {
+		it := newPagedUserIterator(context.Background(), fetch(len(users)+1))
+		it.pageSize = 2
+
+		var names []string
+		for it.Next() {
+			names = append(names, it.User().Name)
+		}
+		assert.NoError(t, it.Err())
+		assert.Equal(t, []string{"A", "B", "C"}, names)
+		assert.False(t, it.Next())
+	})
+
+	t.Run("should stop at a failing page", func(t *testing.T) {
+		it := newPagedUserIterator(context.Background(), fetch(2))
+		it.pageSize = 2
+
+		assert.True(t, it.Next())
+		assert.True(t, it.Next())
+		assert.False(t, it.Next())
+		assert.EqualError(t, it.Err(), "boom")
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		it := newPagedUserIterator(ctx, fetch(len(users)+1))
+		it.pageSize = 2
+
+		assert.True(t, it.Next())
+		cancel()
+		assert.True(t, it.Next(), "the fetched page is still served")
+		assert.False(t, it.Next())
+		assert.ErrorIs(t, it.Err(), context.Canceled)
+	})
+}
+
+// testExport runs the Export contract against a repository implementation
+func testExport(t *testing.T, repo UserRepositoryInterface) {
+	ctx := context.Background()
+	base := time.Now().UTC().Truncate(time.Millisecond)
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		user := newTestUser(fmt.Sprintf("User %d", i), fmt.Sprintf("user%d@example.com", i), base.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, repo.Create(ctx, user))
+		ids = append(ids, user.ID.String())
+	}
+	other := newTestUser("Other", "other@example.org", base)
+	require.NoError(t, repo.Create(ctx, other))
+	deleted := newTestUser("Deleted", "deleted@example.com", base)
+	require.NoError(t, repo.Create(ctx, deleted))
+	require.NoError(t, repo.Delete(ctx, deleted.ID, 0))
+
+	export := func(filter UserFilter, sort UserSort) []string {
+		it := repo.Export(ctx, filter, sort).(*pagedUserIterator)
+		it.pageSize = 2
+
+		var exported []string
+		for it.Next() {
+			exported = append(exported, it.User().ID.String())
+		}
+		require.NoError(t, it.Err())
+		return exported
+	}
+
+	t.Run("should export matching users newest first by default", func(t *testing.T) {
+		exported := export(UserFilter{EmailDomain: "example.com"}, DefaultUserSort)
+		assert.Equal(t, []string{ids[4], ids[3], ids[2], ids[1], ids[0]}, exported)
+	})
+
+	t.Run("should page through other sort orders", func(t *testing.T) {
+		exported := export(UserFilter{EmailDomain: "example.com"}, UserSort{Field: SortByName})
+		assert.Equal(t, ids, exported)
+	})
+
+	t.Run("should skip deleted users", func(t *testing.T) {
+		exported := export(UserFilter{}, UserSort{Field: SortByEmail, Descending: true})
+		assert.Len(t, exported, 6)
+		assert.NotContains(t, exported, deleted.ID.String())
+	})
+}
+
+func TestUserMemoryRepository_Export(t *testing.T) {
+	testExport(t, NewUserMemoryRepository())
+}
+
+func TestUserSQLiteRepository_Export(t *testing.T) {
+	testExport(t, newSQLiteTestRepository(t))
+}
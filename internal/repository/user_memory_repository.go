@@ -0,0 +1,554 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// UserMemoryRepository is an in-memory implementation of UserRepositoryInterface.
+// It mirrors the semantics of UserRepositoryImpl and is safe for concurrent use.
+// Soft-deleted users are moved from users to deleted and dropped from byEmail.
+// Audit records and outbox events are appended under the same lock as the
+// change they describe.
+type UserMemoryRepository struct {
+	mu      sync.RWMutex
+	users   map[uuid.UUID]*entities.User
+	deleted map[uuid.UUID]*entities.User
+	byEmail map[string]uuid.UUID
+	audit   []*entities.AuditRecord
+	outbox  []*outboxEntry
+	// refreshTokens is keyed by token hash
+	refreshTokens map[string]*entities.RefreshToken
+	apiKeys       []*entities.APIKey
+
+	repositoryOptions
+}
+
+// outboxEntry is an event that has not been published yet
+type outboxEntry struct {
+	event         *entities.UserEvent
+	attempts      int
+	nextAttemptAt time.Time
+}
+
+func NewUserMemoryRepository(opts ...RepositoryOption) *UserMemoryRepository {
+	r := &UserMemoryRepository{
+		users:   make(map[uuid.UUID]*entities.User),
+		deleted: make(map[uuid.UUID]*entities.User),
+		byEmail: make(map[string]uuid.UUID),
+
+		refreshTokens: make(map[string]*entities.RefreshToken),
+	}
+	for _, opt := range opts {
+		opt(&r.repositoryOptions)
+	}
+	return r
+}
+
+func (r *UserMemoryRepository) Create(ctx context.Context, user *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.users[user.ID]; exists {
+		return entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+	}
+	if _, exists := r.deleted[user.ID]; exists {
+		return entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+	}
+	if _, exists := r.byEmail[user.Email]; exists {
+		return entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+	}
+
+	r.journal(ctx, user.ID)
+	stored := *user
+	r.users[user.ID] = &stored
+	r.byEmail[user.Email] = user.ID
+	r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionCreate, user.ID, nil, &stored))
+
+	return nil
+}
+
+func (r *UserMemoryRepository) CreateBatch(ctx context.Context, users []*entities.User) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var created []uuid.UUID
+	for _, user := range users {
+		if _, exists := r.users[user.ID]; exists {
+			continue
+		}
+		if _, exists := r.deleted[user.ID]; exists {
+			continue
+		}
+		if _, exists := r.byEmail[user.Email]; exists {
+			continue
+		}
+
+		r.journal(ctx, user.ID)
+		stored := *user
+		r.users[user.ID] = &stored
+		r.byEmail[user.Email] = user.ID
+		r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionCreate, user.ID, nil, &stored))
+		created = append(created, user.ID)
+	}
+
+	return created, nil
+}
+
+func (r *UserMemoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return nil, entities.NewNotFoundError("user not found", entities.ErrUserNotFound)
+	}
+
+	found := *user
+	return &found, nil
+}
+
+func (r *UserMemoryRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, exists := r.byEmail[email]
+	if !exists {
+		return nil, entities.NewNotFoundError("user not found by email", entities.ErrUserNotFound)
+	}
+
+	found := *r.users[id]
+	return &found, nil
+}
+
+func (r *UserMemoryRepository) Update(ctx context.Context, user *entities.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.users[user.ID]
+	if !exists {
+		return entities.NewNotFoundError("user not found for update", entities.ErrUserNotFound)
+	}
+
+	if existing.Version != user.Version {
+		return entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+	}
+
+	if ownerID, taken := r.byEmail[user.Email]; taken && ownerID != user.ID {
+		return entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+	}
+
+	r.journal(ctx, user.ID)
+	before := *existing
+	delete(r.byEmail, existing.Email)
+	existing.Name = user.Name
+	existing.Email = user.Email
+	existing.Role = user.Role
+	existing.PasswordHash = user.PasswordHash
+	existing.UpdatedAt = user.UpdatedAt
+	existing.Version++
+	r.byEmail[existing.Email] = existing.ID
+	r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionUpdate, user.ID, &before, existing))
+
+	user.Version = existing.Version
+	return nil
+}
+
+func (r *UserMemoryRepository) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return entities.NewNotFoundError("user not found for deletion", entities.ErrUserNotFound)
+	}
+	if version != 0 && user.Version != version {
+		return entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+	}
+
+	r.journal(ctx, id)
+	r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionDelete, id, user, nil))
+	delete(r.byEmail, user.Email)
+	delete(r.users, id)
+	user.Version++
+	r.deleted[id] = user
+
+	return nil
+}
+
+func (r *UserMemoryRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.deleted[id]
+	if !exists {
+		if _, active := r.users[id]; active {
+			return entities.NewConflictError("user is not deleted", entities.ErrUserNotDeleted)
+		}
+		return entities.NewNotFoundError("user not found for restore", entities.ErrUserNotFound)
+	}
+	if _, taken := r.byEmail[user.Email]; taken {
+		return entities.NewConflictError("cannot restore user: its email is now used by another user", entities.ErrEmailAlreadyUsed)
+	}
+
+	r.journal(ctx, id)
+	before := *user
+	delete(r.deleted, id)
+	user.Version++
+	user.UpdatedAt = time.Now()
+	r.users[id] = user
+	r.byEmail[user.Email] = id
+	r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionRestore, id, &before, user))
+
+	return nil
+}
+
+func (r *UserMemoryRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.deleted[id]
+	if !exists {
+		if _, active := r.users[id]; active {
+			return entities.NewConflictError("user must be deleted before it can be purged", entities.ErrUserNotDeleted)
+		}
+		return entities.NewNotFoundError("user not found for purge", entities.ErrUserNotFound)
+	}
+
+	r.journal(ctx, id)
+	delete(r.deleted, id)
+	r.recordChange(ctx, entities.NewAuditRecord(ctx, entities.AuditActionPurge, id, user, nil))
+	return nil
+}
+
+// recordChange appends the audit record and outbox event for a change; the
+// caller holds mu
+func (r *UserMemoryRepository) recordChange(ctx context.Context, record *entities.AuditRecord) {
+	var entry *outboxEntry
+	if !r.skipEvents {
+		event := entities.NewUserEvent(record)
+		entry = &outboxEntry{event: event, nextAttemptAt: event.OccurredAt}
+		r.outbox = append(r.outbox, entry)
+	}
+	r.audit = append(r.audit, record)
+
+	if tx := memoryTxFromContext(ctx); tx != nil {
+		tx.onRollback(func() {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.audit = without(r.audit, record)
+			r.outbox = without(r.outbox, entry)
+		})
+	}
+}
+
+// journal lets the unit of work carried by ctx undo the coming change to the
+// user with id; the caller holds mu
+func (r *UserMemoryRepository) journal(ctx context.Context, id uuid.UUID) {
+	tx := memoryTxFromContext(ctx)
+	if tx == nil {
+		return
+	}
+
+	active, deleted := copyUser(r.users[id]), copyUser(r.deleted[id])
+	tx.onRollback(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if current, exists := r.users[id]; exists {
+			delete(r.byEmail, current.Email)
+		}
+		delete(r.users, id)
+		delete(r.deleted, id)
+		if active != nil {
+			r.users[id] = active
+			r.byEmail[active.Email] = id
+		}
+		if deleted != nil {
+			r.deleted[id] = deleted
+		}
+	})
+}
+
+func copyUser(user *entities.User) *entities.User {
+	if user == nil {
+		return nil
+	}
+	copied := *user
+	return &copied
+}
+
+// without returns items minus item, compared by identity
+func without[T any](items []*T, item *T) []*T {
+	for i, candidate := range items {
+		if candidate == item {
+			return append(items[:i:i], items[i+1:]...)
+		}
+	}
+	return items
+}
+
+// Dispatch delivers pending outbox events; see OutboxRepositoryInterface.
+// Like the SQL repositories it leases the events it claims, so deliveries
+// run without holding mu and writers are not blocked.
+func (r *UserMemoryRepository) Dispatch(ctx context.Context, limit int, lease time.Duration, deliver DeliverFunc, backoff BackoffFunc) (int, error) {
+	claimed := r.claimOutbox(time.Now(), limit, lease)
+
+	blocked := make(map[uuid.UUID]bool)
+	for _, entry := range claimed {
+		if blocked[entry.event.UserID] {
+			r.mu.Lock()
+			entry.nextAttemptAt = time.Now()
+			r.mu.Unlock()
+			continue
+		}
+
+		if err := deliver(ctx, entry.event); err != nil {
+			blocked[entry.event.UserID] = true
+			r.mu.Lock()
+			entry.attempts++
+			entry.nextAttemptAt = time.Now().Add(backoff(entry.attempts))
+			r.mu.Unlock()
+			continue
+		}
+
+		r.mu.Lock()
+		r.outbox = without(r.outbox, entry)
+		r.mu.Unlock()
+	}
+
+	return len(claimed), nil
+}
+
+// Prune is a no-op since published events are dropped as soon as they are
+// delivered; see OutboxRepositoryInterface
+func (r *UserMemoryRepository) Prune(ctx context.Context, publishedBefore time.Time) (int, error) {
+	return 0, nil
+}
+
+// claimOutbox mirrors the SQL claim query over the pending events and leases
+// the claimed ones until now plus lease
+func (r *UserMemoryRepository) claimOutbox(now time.Time, limit int, lease time.Duration) []*outboxEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []*outboxEntry
+	waiting := make(map[uuid.UUID]bool)
+	for _, entry := range r.outbox {
+		if len(claimed) == limit {
+			break
+		}
+		// Entries are in creation order, so a user's later events queue up
+		// behind one that is backing off or leased
+		if waiting[entry.event.UserID] || entry.nextAttemptAt.After(now) {
+			waiting[entry.event.UserID] = true
+			continue
+		}
+		claimed = append(claimed, entry)
+	}
+	for _, entry := range claimed {
+		entry.nextAttemptAt = now.Add(lease)
+	}
+	return claimed
+}
+
+func (r *UserMemoryRepository) List(ctx context.Context, params ListParams) ([]*entities.User, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*entities.User, 0, len(r.users))
+	for _, user := range r.users {
+		if matchesFilter(user, params.Filter) {
+			all = append(all, user)
+		}
+	}
+
+	start := params.Offset
+	if params.Cursor != nil {
+		// Keyset pages always follow the default newest-first ordering
+		sortUsers(all, DefaultUserSort)
+		start = sort.Search(len(all), func(i int) bool {
+			return isBefore(params.Cursor.CreatedAt, params.Cursor.ID, all[i].CreatedAt, all[i].ID)
+		})
+	} else {
+		sortUsers(all, params.Sort)
+	}
+
+	if start >= len(all) {
+		return nil, len(all), nil
+	}
+	end := start + params.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+
+	users := make([]*entities.User, 0, end-start)
+	for _, user := range all[start:end] {
+		u := *user
+		users = append(users, &u)
+	}
+
+	return users, len(all), nil
+}
+
+// Export pages through a sorted snapshot of the matching users taken for
+// every page
+func (r *UserMemoryRepository) Export(ctx context.Context, filter UserFilter, userSort UserSort) UserIterator {
+	_, userSort = resolveSort(userSort)
+	return newPagedUserIterator(ctx, func(ctx context.Context, after *entities.User, limit int) ([]*entities.User, error) {
+		r.mu.RLock()
+		defer r.mu.RUnlock()
+
+		var matched []*entities.User
+		for _, user := range r.users {
+			if matchesFilter(user, filter) && (after == nil || compareUsers(user, after, userSort) > 0) {
+				matched = append(matched, user)
+			}
+		}
+		sortUsers(matched, userSort)
+
+		page := make([]*entities.User, 0, min(limit, len(matched)))
+		for _, user := range matched[:min(limit, len(matched))] {
+			page = append(page, copyUser(user))
+		}
+		return page, nil
+	})
+}
+
+func (r *UserMemoryRepository) Search(ctx context.Context, query string, limit int) ([]*UserSearchResult, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var results []*UserSearchResult
+	for _, user := range r.users {
+		if score := scoreUser(query, user); score >= searchThreshold {
+			u := *user
+			results = append(results, &UserSearchResult{User: &u, Score: score})
+		}
+	}
+
+	return rankSearchResults(results, limit), nil
+}
+
+func (r *UserMemoryRepository) ListAudit(ctx context.Context, params AuditListParams) ([]*entities.AuditRecord, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Records are appended in order, so walking backwards yields newest first
+	var matched []*entities.AuditRecord
+	for i := len(r.audit) - 1; i >= 0; i-- {
+		if matchesAuditFilter(r.audit[i], params.Filter) {
+			matched = append(matched, r.audit[i])
+		}
+	}
+
+	if params.Offset >= len(matched) {
+		return nil, len(matched), nil
+	}
+	end := params.Offset + params.Limit
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	records := make([]*entities.AuditRecord, 0, end-params.Offset)
+	for _, record := range matched[params.Offset:end] {
+		copied := *record
+		records = append(records, &copied)
+	}
+
+	return records, len(matched), nil
+}
+
+// matchesAuditFilter mirrors the SQL WHERE clause built for filter
+func matchesAuditFilter(record *entities.AuditRecord, filter AuditFilter) bool {
+	if filter.UserID != uuid.Nil && record.UserID != filter.UserID {
+		return false
+	}
+	if filter.Actor != "" && record.Actor != filter.Actor {
+		return false
+	}
+	if filter.Action != "" && record.Action != filter.Action {
+		return false
+	}
+	if filter.RequestID != "" && record.RequestID != filter.RequestID {
+		return false
+	}
+	if !filter.From.IsZero() && record.CreatedAt.Before(filter.From) {
+		return false
+	}
+	if !filter.To.IsZero() && !record.CreatedAt.Before(filter.To) {
+		return false
+	}
+	return true
+}
+
+// matchesFilter mirrors the SQL WHERE clause built for filter
+func matchesFilter(user *entities.User, filter UserFilter) bool {
+	if filter.Email != "" && user.Email != filter.Email {
+		return false
+	}
+	if filter.NamePrefix != "" && !strings.HasPrefix(strings.ToLower(user.Name), strings.ToLower(filter.NamePrefix)) {
+		return false
+	}
+	if filter.NameContains != "" && !strings.Contains(strings.ToLower(user.Name), strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if filter.EmailDomain != "" && !strings.HasSuffix(strings.ToLower(user.Email), "@"+strings.ToLower(filter.EmailDomain)) {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && !user.CreatedAt.After(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !user.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if !filter.UpdatedSince.IsZero() && user.UpdatedAt.Before(filter.UpdatedSince) {
+		return false
+	}
+	return true
+}
+
+// sortUsers orders users like the SQL ORDER BY built for userSort
+func sortUsers(users []*entities.User, userSort UserSort) {
+	_, userSort = resolveSort(userSort)
+	sort.Slice(users, func(i, j int) bool {
+		return compareUsers(users[i], users[j], userSort) < 0
+	})
+}
+
+// compareUsers orders a and b by userSort, breaking ties by ID
+func compareUsers(a, b *entities.User, userSort UserSort) int {
+	var cmp int
+	switch userSort.Field {
+	case SortByName:
+		cmp = strings.Compare(a.Name, b.Name)
+	case SortByEmail:
+		cmp = strings.Compare(a.Email, b.Email)
+	default:
+		cmp = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if cmp == 0 {
+		cmp = bytes.Compare(a.ID[:], b.ID[:])
+	}
+
+	if userSort.Descending {
+		return -cmp
+	}
+	return cmp
+}
+
+// isBefore reports whether (aTime, aID) sorts before (bTime, bID) in the
+// created_at DESC, id DESC ordering
+func isBefore(aTime time.Time, aID uuid.UUID, bTime time.Time, bID uuid.UUID) bool {
+	if !aTime.Equal(bTime) {
+		return aTime.After(bTime)
+	}
+	return bytes.Compare(aID[:], bID[:]) > 0
+}
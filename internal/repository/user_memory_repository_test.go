@@ -0,0 +1,471 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestUser(name, email string, createdAt time.Time) *entities.User {
+	return &entities.User{
+		ID:        uuid.New(),
+		Name:      name,
+		Email:     email,
+		Role:      entities.RoleMember,
+		Version:   1,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestUserMemoryRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create user successfully", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+
+		err := repo.Create(ctx, user)
+		assert.NoError(t, err)
+
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+	})
+
+	t.Run("should return conflict error when email exists", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		require.NoError(t, repo.Create(ctx, newTestUser("John Doe", "john@example.com", time.Now())))
+
+		err := repo.Create(ctx, newTestUser("Other John", "john@example.com", time.Now()))
+		assert.True(t, entities.IsConflictError(err))
+	})
+
+	t.Run("should not share state with caller", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		user.Name = "Mutated"
+
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "John Doe", found.Name)
+	})
+}
+
+func TestUserMemoryRepository_GetByEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+
+	t.Run("should return user when exists", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, user.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, "missing@example.com")
+		assert.True(t, entities.IsNotFoundError(err))
+		assert.Nil(t, found)
+	})
+}
+
+func TestUserMemoryRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should update user and email index", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		user.Name = "John Smith"
+		user.Email = "john.smith@example.com"
+		assert.NoError(t, repo.Update(ctx, user))
+
+		_, err := repo.GetByEmail(ctx, "john@example.com")
+		assert.True(t, entities.IsNotFoundError(err))
+
+		found, err := repo.GetByEmail(ctx, "john.smith@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "John Smith", found.Name)
+	})
+
+	t.Run("should store the password hash", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		user.PasswordHash = "$2a$10$hash"
+		require.NoError(t, repo.Update(ctx, user))
+
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "$2a$10$hash", found.PasswordHash)
+	})
+
+	t.Run("should return conflict error when email used by another user", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		other := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Create(ctx, other))
+
+		user.Email = other.Email
+		err := repo.Update(ctx, user)
+		assert.True(t, entities.IsConflictError(err))
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		err := repo.Update(ctx, newTestUser("John Doe", "john@example.com", time.Now()))
+		assert.True(t, entities.IsNotFoundError(err))
+	})
+}
+
+func TestUserMemoryRepository_Versioning(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+
+	stale, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	user.Name = "John Smith"
+	require.NoError(t, repo.Update(ctx, user))
+	assert.Equal(t, int64(2), user.Version)
+
+	stale.Name = "Johnny"
+	err = repo.Update(ctx, stale)
+	assert.True(t, entities.IsPreconditionFailedError(err))
+
+	err = repo.Delete(ctx, user.ID, 1)
+	assert.True(t, entities.IsPreconditionFailedError(err))
+	assert.NoError(t, repo.Delete(ctx, user.ID, 2))
+}
+
+func TestUserMemoryRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should delete user and free email", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		assert.NoError(t, repo.Delete(ctx, user.ID, 0))
+		assert.NoError(t, repo.Create(ctx, newTestUser("John Again", "john@example.com", time.Now())))
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		err := repo.Delete(ctx, uuid.New(), 0)
+		assert.True(t, entities.IsNotFoundError(err))
+	})
+}
+
+func TestUserMemoryRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should hide deleted user and restore it", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+
+		_, err := repo.GetByID(ctx, user.ID)
+		assert.True(t, entities.IsNotFoundError(err))
+		users, total, err := repo.List(ctx, ListParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, users)
+		assert.Zero(t, total)
+
+		require.NoError(t, repo.Restore(ctx, user.ID))
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), found.Version)
+	})
+
+	t.Run("should refuse restore when email was reused", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+		require.NoError(t, repo.Create(ctx, newTestUser("John Again", "john@example.com", time.Now())))
+
+		err := repo.Restore(ctx, user.ID)
+		assert.True(t, entities.IsConflictError(err))
+		assert.ErrorIs(t, err, entities.ErrEmailAlreadyUsed)
+	})
+
+	t.Run("should refuse restore of active user", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		assert.ErrorIs(t, repo.Restore(ctx, user.ID), entities.ErrUserNotDeleted)
+		assert.True(t, entities.IsNotFoundError(repo.Restore(ctx, uuid.New())))
+	})
+
+	t.Run("should purge only deleted users", func(t *testing.T) {
+		repo := NewUserMemoryRepository()
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		assert.ErrorIs(t, repo.Purge(ctx, user.ID), entities.ErrUserNotDeleted)
+
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+		require.NoError(t, repo.Purge(ctx, user.ID))
+		assert.True(t, entities.IsNotFoundError(repo.Restore(ctx, user.ID)))
+	})
+}
+
+func TestUserMemoryRepository_Audit(t *testing.T) {
+	ctx := entities.ContextWithRequestID(entities.ContextWithActor(context.Background(), "admin"), "req-1")
+	repo := NewUserMemoryRepository()
+
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, user.UpdateName("John Smith"))
+	require.NoError(t, repo.Update(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID, 0))
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	require.NoError(t, repo.Delete(ctx, user.ID, 0))
+	require.NoError(t, repo.Purge(ctx, user.ID))
+
+	t.Run("should record every change newest first", func(t *testing.T) {
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: user.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 6, total)
+		require.Len(t, records, 6)
+
+		var actions []entities.AuditAction
+		for _, record := range records {
+			actions = append(actions, record.Action)
+			assert.Equal(t, "admin", record.Actor)
+			assert.Equal(t, "req-1", record.RequestID)
+		}
+		assert.Equal(t, []entities.AuditAction{
+			entities.AuditActionPurge,
+			entities.AuditActionDelete,
+			entities.AuditActionRestore,
+			entities.AuditActionDelete,
+			entities.AuditActionUpdate,
+			entities.AuditActionCreate,
+		}, actions)
+
+		created, updated, purged := records[5], records[4], records[0]
+		assert.Nil(t, created.Before)
+		assert.Equal(t, "John Doe", created.After.Name)
+		assert.Equal(t, "John Doe", updated.Before.Name)
+		assert.Equal(t, "John Smith", updated.After.Name)
+		assert.Equal(t, int64(2), updated.After.Version)
+		assert.NotNil(t, purged.Before)
+		assert.Nil(t, purged.After)
+	})
+
+	t.Run("should filter by action and page", func(t *testing.T) {
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 1, Offset: 1, Filter: AuditFilter{Action: entities.AuditActionDelete}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("should not record failed changes", func(t *testing.T) {
+		other := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(context.Background(), other))
+		other.Version = 5
+		require.Error(t, repo.Update(ctx, other))
+
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: other.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, entities.AnonymousActor, records[0].Actor)
+	})
+}
+
+func TestUserMemoryRepository_Dispatch(t *testing.T) {
+	testDispatch(t, func(t *testing.T) outboxTestRepository { return NewUserMemoryRepository() })
+}
+
+func TestUserMemoryRepository_WithoutEvents(t *testing.T) {
+	testWithoutEvents(t, NewUserMemoryRepository(WithoutEvents()))
+}
+
+func TestUserMemoryRepository_List(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+
+	base := time.Now()
+	oldest := newTestUser("Oldest", "oldest@example.com", base.Add(-2*time.Hour))
+	middle := newTestUser("Middle", "middle@example.com", base.Add(-time.Hour))
+	newest := newTestUser("Newest", "newest@example.com", base)
+	for _, u := range []*entities.User{middle, oldest, newest} {
+		require.NoError(t, repo.Create(ctx, u))
+	}
+
+	t.Run("should order by created_at descending", func(t *testing.T) {
+		users, total, err := repo.List(ctx, ListParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, users, 3)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, newest.ID, users[0].ID)
+		assert.Equal(t, middle.ID, users[1].ID)
+		assert.Equal(t, oldest.ID, users[2].ID)
+	})
+
+	t.Run("should apply limit and offset", func(t *testing.T) {
+		users, total, err := repo.List(ctx, ListParams{Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, users, 1)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, middle.ID, users[0].ID)
+	})
+
+	t.Run("should return empty result past the end", func(t *testing.T) {
+		users, total, err := repo.List(ctx, ListParams{Limit: 10, Offset: 5})
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+		assert.Equal(t, 3, total)
+	})
+	t.Run("should seek past cursor", func(t *testing.T) {
+		users, _, err := repo.List(ctx, ListParams{Limit: 10, Cursor: &Cursor{CreatedAt: newest.CreatedAt, ID: newest.ID}})
+		require.NoError(t, err)
+		require.Len(t, users, 2)
+		assert.Equal(t, middle.ID, users[0].ID)
+		assert.Equal(t, oldest.ID, users[1].ID)
+	})
+}
+
+func TestUserMemoryRepository_ListFilterAndSort(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+
+	base := time.Now()
+	alice := newTestUser("Alice Doe", "alice@example.com", base.Add(-2*time.Hour))
+	bob := newTestUser("Bob Doe", "bob@other.org", base.Add(-time.Hour))
+	carol := newTestUser("Carol Smith", "carol@example.com", base)
+	for _, u := range []*entities.User{alice, bob, carol} {
+		require.NoError(t, repo.Create(ctx, u))
+	}
+
+	t.Run("should filter by name substring case-insensitively", func(t *testing.T) {
+		users, total, err := repo.List(ctx, ListParams{Limit: 10, Filter: UserFilter{NameContains: "DOE"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, bob.ID, users[0].ID)
+		assert.Equal(t, alice.ID, users[1].ID)
+	})
+
+	t.Run("should filter by email domain and created range", func(t *testing.T) {
+		users, total, err := repo.List(ctx, ListParams{Limit: 10, Filter: UserFilter{
+			EmailDomain:   "example.com",
+			CreatedBefore: base.Add(-time.Minute),
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, alice.ID, users[0].ID)
+	})
+
+	t.Run("should sort by name ascending", func(t *testing.T) {
+		users, _, err := repo.List(ctx, ListParams{Limit: 10, Sort: UserSort{Field: SortByName}})
+		require.NoError(t, err)
+		require.Len(t, users, 3)
+		assert.Equal(t, []string{"Alice Doe", "Bob Doe", "Carol Smith"}, []string{users[0].Name, users[1].Name, users[2].Name})
+	})
+}
+
+func TestUserMemoryRepository_ListCursorTies(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+
+	// Users sharing a created_at must still page without gaps or duplicates
+	createdAt := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(ctx, newTestUser("Tied", uuid.NewString()+"@example.com", createdAt)))
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	var cursor *Cursor
+	for page := 0; page < 5; page++ {
+		users, _, err := repo.List(ctx, ListParams{Limit: 2, Cursor: cursor})
+		require.NoError(t, err)
+		if len(users) == 0 {
+			break
+		}
+		for _, u := range users {
+			assert.False(t, seen[u.ID], "user returned twice")
+			seen[u.ID] = true
+		}
+		last := users[len(users)-1]
+		cursor = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	assert.Len(t, seen, 5)
+}
+
+func TestUserMemoryRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+
+	john := newTestUser("John Doe", "john.doe@example.com", time.Now())
+	jane := newTestUser("Jane Smith", "jane.smith@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, john))
+	require.NoError(t, repo.Create(ctx, jane))
+
+	t.Run("should rank exact fragment first", func(t *testing.T) {
+		results, err := repo.Search(ctx, "smith", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.Equal(t, jane.ID, results[0].User.ID)
+		assert.Greater(t, results[0].Score, 0.0)
+	})
+
+	t.Run("should match misspelled email", func(t *testing.T) {
+		results, err := repo.Search(ctx, "jonh.doe@example.com", 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, john.ID, results[0].User.ID)
+	})
+
+	t.Run("should return nothing for unrelated query", func(t *testing.T) {
+		results, err := repo.Search(ctx, "zzzz", 10)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestUserMemoryRepository_ConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	repo := NewUserMemoryRepository()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.Create(ctx, newTestUser("Same Email", "same@example.com", time.Now()))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+		} else {
+			assert.True(t, entities.IsConflictError(err))
+		}
+	}
+	assert.Equal(t, 1, created)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UserFilter narrows the users returned by List. Zero-valued fields are ignored.
+type UserFilter struct {
+	Email         string
+	NamePrefix    string
+	NameContains  string
+	EmailDomain   string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedSince  time.Time
+}
+
+// SortField is a column users can be ordered by
+type SortField string
+
+const (
+	SortByCreatedAt SortField = "created_at"
+	SortByName      SortField = "name"
+	SortByEmail     SortField = "email"
+)
+
+// UserSort orders List results. Ties are always broken by ID in the same direction.
+type UserSort struct {
+	Field      SortField
+	Descending bool
+}
+
+// DefaultUserSort is the newest-first ordering used when no sort is requested
+var DefaultUserSort = UserSort{Field: SortByCreatedAt, Descending: true}
+
+// sortColumns whitelists the columns that may appear in ORDER BY
+var sortColumns = map[SortField]string{
+	SortByCreatedAt: "created_at",
+	SortByName:      "name",
+	SortByEmail:     "email",
+}
+
+// sqlDialect captures the differences between the SQL backends
+type sqlDialect struct {
+	placeholder func(n int) string
+	like        string
+	timeArg     func(t time.Time) interface{}
+	// skipLocked is appended to queries claiming rows other workers may hold
+	skipLocked string
+}
+
+var postgresDialect = sqlDialect{
+	placeholder: func(n int) string { return "$" + strconv.Itoa(n) },
+	like:        "ILIKE",
+	timeArg:     func(t time.Time) interface{} { return t },
+	skipLocked:  "\n\t\tFOR UPDATE OF o SKIP LOCKED",
+}
+
+// SQLite has no row locks; its single connection serializes claims instead
+var sqliteDialect = sqlDialect{
+	placeholder: func(int) string { return "?" },
+	like:        "LIKE",
+	timeArg:     func(t time.Time) interface{} { return t.UTC() },
+}
+
+// queryBuilder accumulates positional arguments for a single statement
+type queryBuilder struct {
+	dialect sqlDialect
+	args    []interface{}
+}
+
+func (b *queryBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return b.dialect.placeholder(len(b.args))
+}
+
+// where renders the WHERE clause for filter. Soft-deleted users are always excluded.
+func (b *queryBuilder) where(filter UserFilter, extra ...string) string {
+	conditions := append([]string{"deleted_at IS NULL"}, extra...)
+
+	if filter.Email != "" {
+		conditions = append(conditions, "email = "+b.arg(filter.Email))
+	}
+	if filter.NamePrefix != "" {
+		conditions = append(conditions, "name "+b.dialect.like+" "+b.arg(escapeLike(filter.NamePrefix)+"%")+` ESCAPE '\'`)
+	}
+	if filter.NameContains != "" {
+		conditions = append(conditions, "name "+b.dialect.like+" "+b.arg("%"+escapeLike(filter.NameContains)+"%")+` ESCAPE '\'`)
+	}
+	if filter.EmailDomain != "" {
+		conditions = append(conditions, "email "+b.dialect.like+" "+b.arg("%@"+escapeLike(filter.EmailDomain))+` ESCAPE '\'`)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		conditions = append(conditions, "created_at > "+b.arg(b.dialect.timeArg(filter.CreatedAfter)))
+	}
+	if !filter.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created_at < "+b.arg(b.dialect.timeArg(filter.CreatedBefore)))
+	}
+	if !filter.UpdatedSince.IsZero() {
+		conditions = append(conditions, "updated_at >= "+b.arg(b.dialect.timeArg(filter.UpdatedSince)))
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// resolveSort returns the column sort orders by, falling back to DefaultUserSort
+func resolveSort(sort UserSort) (string, UserSort) {
+	if column, ok := sortColumns[sort.Field]; ok {
+		return column, sort
+	}
+	return sortColumns[DefaultUserSort.Field], DefaultUserSort
+}
+
+// orderBy renders the ORDER BY clause, falling back to DefaultUserSort
+func orderBy(sort UserSort) string {
+	column, sort := resolveSort(sort)
+
+	direction := "ASC"
+	if sort.Descending {
+		direction = "DESC"
+	}
+	return "ORDER BY " + column + " " + direction + ", id " + direction
+}
+
+// buildListQuery renders the offset or keyset page query for params
+func buildListQuery(dialect sqlDialect, params ListParams) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+
+	if params.Cursor != nil {
+		countWhere := b.where(params.Filter)
+		cursorCondition := "(created_at, id) < (" + b.arg(dialect.timeArg(params.Cursor.CreatedAt)) + ", " + b.arg(params.Cursor.ID) + ")"
+		query := `
+		SELECT id, name, email, role, version, created_at, updated_at, (` + clauses("SELECT COUNT(*) FROM users", countWhere) + `) AS total_count
+		FROM users
+		` + b.where(params.Filter, cursorCondition) + `
+		` + orderBy(DefaultUserSort) + `
+		LIMIT ` + b.arg(params.Limit)
+		return query, b.args
+	}
+
+	query := `
+		SELECT id, name, email, role, version, created_at, updated_at, COUNT(*) OVER() AS total_count
+		FROM users
+		` + b.where(params.Filter) + `
+		` + orderBy(params.Sort) + `
+		LIMIT ` + b.arg(params.Limit) + ` OFFSET ` + b.arg(params.Offset)
+	return query, b.args
+}
+
+// buildCountQuery renders a query counting the users matching filter
+func buildCountQuery(dialect sqlDialect, filter UserFilter) (string, []interface{}) {
+	b := &queryBuilder{dialect: dialect}
+	return clauses("SELECT COUNT(*) FROM users", b.where(filter)), b.args
+}
+
+// clauses joins the non-empty parts of a statement with spaces
+func clauses(parts ...string) string {
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
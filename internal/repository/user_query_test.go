@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildListQuery(t *testing.T) {
+	createdAfter := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should build filtered and sorted postgres query", func(t *testing.T) {
+		query, args := buildListQuery(postgresDialect, ListParams{
+			Limit:  10,
+			Offset: 20,
+			Filter: UserFilter{NamePrefix: "jo", EmailDomain: "example.com", CreatedAfter: createdAfter},
+			Sort:   UserSort{Field: SortByName},
+		})
+
+		assert.Contains(t, query, `WHERE deleted_at IS NULL AND name ILIKE $1 ESCAPE '\' AND email ILIKE $2 ESCAPE '\' AND created_at > $3`)
+		assert.Contains(t, query, "ORDER BY name ASC, id ASC")
+		assert.Contains(t, query, "LIMIT $4 OFFSET $5")
+		assert.Equal(t, []interface{}{"jo%", "%@example.com", createdAfter, 10, 20}, args)
+	})
+
+	t.Run("should use question mark placeholders for sqlite", func(t *testing.T) {
+		query, args := buildListQuery(sqliteDialect, ListParams{
+			Limit:  10,
+			Filter: UserFilter{Email: "john@example.com"},
+		})
+
+		assert.Contains(t, query, "WHERE deleted_at IS NULL AND email = ?")
+		assert.Contains(t, query, "LIMIT ? OFFSET ?")
+		assert.Len(t, args, 3)
+	})
+
+	t.Run("should fall back to default order for unknown sort field", func(t *testing.T) {
+		query, _ := buildListQuery(postgresDialect, ListParams{Limit: 10, Sort: UserSort{Field: "password"}})
+
+		assert.Contains(t, query, "ORDER BY created_at DESC, id DESC")
+		assert.NotContains(t, query, "password")
+	})
+
+	t.Run("should escape like wildcards in user input", func(t *testing.T) {
+		_, args := buildListQuery(postgresDialect, ListParams{Limit: 10, Filter: UserFilter{NameContains: `50%_off\`}})
+
+		assert.Equal(t, `%50\%\_off\\%`, args[0])
+	})
+}
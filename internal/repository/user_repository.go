@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"go-clean-code/internal/entities"
 
@@ -10,52 +11,110 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// UserRepositoryInterface persists users. Create, CreateBatch, Update, Delete,
+// Restore and Purge write an entities.AuditRecord and an entities.UserEvent
+// atomically with every change, taking the actor and request ID from the
+// context.
 type UserRepositoryInterface interface {
 	Create(ctx context.Context, user *entities.User) error
+	// CreateBatch inserts users, skipping any whose ID or email is already
+	// taken, and returns the IDs of those it inserted in input order
+	CreateBatch(ctx context.Context, users []*entities.User) ([]uuid.UUID, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error)
 	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	// Update saves user if its Version is still current and increments Version
 	Update(ctx context.Context, user *entities.User) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(ctx context.Context, limit, offset int) ([]*entities.User, error)
+	// Delete soft-deletes the user; a non-zero version must match the stored one.
+	// Soft-deleted users are hidden from every other read.
+	Delete(ctx context.Context, id uuid.UUID, version int64) error
+	// Restore undoes Delete
+	Restore(ctx context.Context, id uuid.UUID) error
+	// Purge permanently removes a soft-deleted user
+	Purge(ctx context.Context, id uuid.UUID) error
+	// List returns a page of users together with the total number of users
+	List(ctx context.Context, params ListParams) ([]*entities.User, int, error)
+	// Export iterates over every user matching filter in sort order
+	Export(ctx context.Context, filter UserFilter, sort UserSort) UserIterator
+	// Search returns up to limit users matching query by name or email, best match first
+	Search(ctx context.Context, query string, limit int) ([]*UserSearchResult, error)
+	// ListAudit returns a newest-first page of audit records together with
+	// the total number of matching records
+	ListAudit(ctx context.Context, params AuditListParams) ([]*entities.AuditRecord, int, error)
+}
+
+// Cursor is a keyset position in the created_at DESC, id DESC ordering
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// ListParams selects a page of users. When Cursor is set the page starts
+// right after the cursor position in the default ordering, and Offset and
+// Sort are ignored.
+type ListParams struct {
+	Limit  int
+	Offset int
+	Cursor *Cursor
+	Filter UserFilter
+	Sort   UserSort
 }
 
 type UserRepositoryImpl struct {
 	db *sql.DB
+	repositoryOptions
 }
 
-func NewUserRepository(db *sql.DB) *UserRepositoryImpl {
-	return &UserRepositoryImpl{
+func NewUserRepository(db *sql.DB, opts ...RepositoryOption) *UserRepositoryImpl {
+	r := &UserRepositoryImpl{
 		db: db,
 	}
+	for _, opt := range opts {
+		opt(&r.repositoryOptions)
+	}
+	return r
+}
+
+func (r *UserRepositoryImpl) changes() changeLog {
+	return changeLog{dialect: postgresDialect, skipEvents: r.skipEvents}
 }
 
 func (r *UserRepositoryImpl) Create(ctx context.Context, user *entities.User) error {
-	query := `
-		INSERT INTO users (id, name, email, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)`
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		query := `
+			INSERT INTO users (id, name, email, role, version, password_hash, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt)
-	if err != nil {
-		if isUniqueConstraintError(err) {
-			return entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+		_, err := tx.ExecContext(ctx, query, user.ID, user.Name, user.Email, userRole(user), user.Version, passwordHash(user), user.CreatedAt, user.UpdatedAt)
+		if err != nil {
+			if isUniqueConstraintError(err) {
+				return nil, entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+			}
+			return nil, entities.NewInternalError("failed to create user", err)
 		}
-		return entities.NewInternalError("failed to create user", err)
-	}
 
-	return nil
+		return entities.NewAuditRecord(ctx, entities.AuditActionCreate, user.ID, nil, user), nil
+	})
+}
+
+// CreateBatch inserts users with one multi-row INSERT; see UserRepositoryInterface
+func (r *UserRepositoryImpl) CreateBatch(ctx context.Context, users []*entities.User) ([]uuid.UUID, error) {
+	return createBatch(ctx, r.db, r.changes(), users)
 }
 
 func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
 	query := `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, role, version, COALESCE(password_hash, ''), created_at, updated_at
 		FROM users
-		WHERE id = $1`
+		WHERE id = $1 AND deleted_at IS NULL`
 
 	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.PasswordHash,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -72,15 +131,18 @@ func (r *UserRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*entiti
 
 func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
 	query := `
-		SELECT id, name, email, created_at, updated_at
+		SELECT id, name, email, role, version, COALESCE(password_hash, ''), created_at, updated_at
 		FROM users
-		WHERE email = $1`
+		WHERE email = $1 AND deleted_at IS NULL`
 
 	user := &entities.User{}
-	err := r.db.QueryRowContext(ctx, query, email).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
 		&user.ID,
 		&user.Name,
 		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.PasswordHash,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -96,85 +158,287 @@ func (r *UserRepositoryImpl) GetByEmail(ctx context.Context, email string) (*ent
 }
 
 func (r *UserRepositoryImpl) Update(ctx context.Context, user *entities.User) error {
-	query := `
-		UPDATE users
-		SET name = $2, email = $3, updated_at = $4
-		WHERE id = $1`
+	err := withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.lockUser(ctx, tx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil || deleted {
+			return nil, entities.NewNotFoundError("user not found for update", entities.ErrUserNotFound)
+		}
+		if before.Version != user.Version {
+			return nil, entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+		}
+
+		query := `
+			UPDATE users
+			SET name = $2, email = $3, role = $4, password_hash = $5, updated_at = $6, version = version + 1
+			WHERE id = $1`
+
+		if _, err := tx.ExecContext(ctx, query, user.ID, user.Name, user.Email, userRole(user), passwordHash(user), user.UpdatedAt); err != nil {
+			if isUniqueConstraintError(err) {
+				return nil, entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+			}
+			return nil, entities.NewInternalError("failed to update user", err)
+		}
 
-	result, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Email, user.UpdatedAt)
+		after := *user
+		after.Version++
+		return entities.NewAuditRecord(ctx, entities.AuditActionUpdate, user.ID, before, &after), nil
+	})
 	if err != nil {
-		if isUniqueConstraintError(err) {
-			return entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+		return err
+	}
+
+	user.Version++
+	return nil
+}
+
+// Delete soft-deletes the user by setting deleted_at
+func (r *UserRepositoryImpl) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.lockUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
 		}
-		return entities.NewInternalError("failed to update user", err)
+		if before == nil || deleted {
+			return nil, entities.NewNotFoundError("user not found for deletion", entities.ErrUserNotFound)
+		}
+		if version != 0 && before.Version != version {
+			return nil, entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+		}
+
+		query := `UPDATE users SET deleted_at = $2, version = version + 1 WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, time.Now()); err != nil {
+			return nil, entities.NewInternalError("failed to delete user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionDelete, id, before, nil), nil
+	})
+}
+
+func (r *UserRepositoryImpl) Restore(ctx context.Context, id uuid.UUID) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.lockUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, entities.NewNotFoundError("user not found for restore", entities.ErrUserNotFound)
+		}
+		if !deleted {
+			return nil, entities.NewConflictError("user is not deleted", entities.ErrUserNotDeleted)
+		}
+
+		after := *before
+		after.Version++
+		after.UpdatedAt = time.Now()
+
+		query := `UPDATE users SET deleted_at = NULL, updated_at = $2, version = version + 1 WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, id, after.UpdatedAt); err != nil {
+			if isUniqueConstraintError(err) {
+				return nil, entities.NewConflictError("cannot restore user: its email is now used by another user", entities.ErrEmailAlreadyUsed)
+			}
+			return nil, entities.NewInternalError("failed to restore user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionRestore, id, before, &after), nil
+	})
+}
+
+func (r *UserRepositoryImpl) Purge(ctx context.Context, id uuid.UUID) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.lockUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, entities.NewNotFoundError("user not found for purge", entities.ErrUserNotFound)
+		}
+		if !deleted {
+			return nil, entities.NewConflictError("user must be deleted before it can be purged", entities.ErrUserNotDeleted)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id); err != nil {
+			return nil, entities.NewInternalError("failed to purge user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionPurge, id, before, nil), nil
+	})
+}
+
+// lockUser reads the stored user, soft-deleted or not, and locks its row
+// until tx ends. user is nil when no row exists.
+func (r *UserRepositoryImpl) lockUser(ctx context.Context, tx dbtx, id uuid.UUID) (user *entities.User, deleted bool, err error) {
+	query := `
+		SELECT id, name, email, role, version, created_at, updated_at, deleted_at IS NOT NULL
+		FROM users
+		WHERE id = $1
+		FOR UPDATE`
+
+	user = &entities.User{}
+	err = tx.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&deleted,
+	)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, entities.NewInternalError("failed to read user", err)
+	}
+	return user, deleted, nil
+}
+
+func (r *UserRepositoryImpl) List(ctx context.Context, params ListParams) ([]*entities.User, int, error) {
+	query, args := buildListQuery(postgresDialect, params)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, entities.NewInternalError("failed to list users", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	users, total, err := scanUserPage(rows)
 	if err != nil {
-		return entities.NewInternalError("failed to get rows affected", err)
+		return nil, 0, err
 	}
 
-	if rowsAffected == 0 {
-		return entities.NewNotFoundError("user not found for update", entities.ErrUserNotFound)
+	// The page count is unavailable when no row comes back
+	if len(users) == 0 && (params.Offset > 0 || params.Cursor != nil) {
+		countQuery, countArgs := buildCountQuery(postgresDialect, params.Filter)
+		if err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, entities.NewInternalError("failed to count users", err)
+		}
 	}
 
-	return nil
+	return users, total, nil
 }
 
-func (r *UserRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM users WHERE id = $1`
+// Export reads the matching users in pages of ExportPageSize
+func (r *UserRepositoryImpl) Export(ctx context.Context, filter UserFilter, sort UserSort) UserIterator {
+	return newPagedUserIterator(ctx, func(ctx context.Context, after *entities.User, limit int) ([]*entities.User, error) {
+		return fetchExportPage(ctx, conn(ctx, r.db), postgresDialect, filter, sort, after, limit)
+	})
+}
+
+// ListAudit returns a newest-first page of audit records with their total count
+func (r *UserRepositoryImpl) ListAudit(ctx context.Context, params AuditListParams) ([]*entities.AuditRecord, int, error) {
+	query, args := buildAuditListQuery(postgresDialect, params)
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return entities.NewInternalError("failed to delete user", err)
+		return nil, 0, entities.NewInternalError("failed to list audit records", err)
 	}
+	defer rows.Close()
 
-	rowsAffected, err := result.RowsAffected()
+	records, total, err := scanAuditPage(rows)
 	if err != nil {
-		return entities.NewInternalError("failed to get rows affected", err)
+		return nil, 0, err
 	}
 
-	if rowsAffected == 0 {
-		return entities.NewNotFoundError("user not found for deletion", entities.ErrUserNotFound)
+	if len(records) == 0 && params.Offset > 0 {
+		countQuery, countArgs := buildAuditCountQuery(postgresDialect, params.Filter)
+		if err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, entities.NewInternalError("failed to count audit records", err)
+		}
 	}
 
-	return nil
+	return records, total, nil
 }
 
-func (r *UserRepositoryImpl) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
-	query := `
-		SELECT id, name, email, created_at, updated_at
-		FROM users
-		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+// Dispatch delivers pending outbox events; see OutboxRepositoryInterface
+func (r *UserRepositoryImpl) Dispatch(ctx context.Context, limit int, lease time.Duration, deliver DeliverFunc, backoff BackoffFunc) (int, error) {
+	return dispatchOutbox(ctx, r.db, postgresDialect, limit, lease, deliver, backoff)
+}
+
+// Prune deletes published outbox events; see OutboxRepositoryInterface
+func (r *UserRepositoryImpl) Prune(ctx context.Context, publishedBefore time.Time) (int, error) {
+	return pruneOutbox(ctx, r.db, postgresDialect, publishedBefore)
+}
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+// Search ranks users by trigram similarity and full-text match over name and email
+func (r *UserRepositoryImpl) Search(ctx context.Context, query string, limit int) ([]*UserSearchResult, error) {
+	searchQuery := `
+		SELECT id, name, email, role, version, created_at, updated_at,
+			GREATEST(word_similarity($1, name), word_similarity($1, email))
+				+ ts_rank(to_tsvector('simple', name || ' ' || email), plainto_tsquery('simple', $1)) AS score
+		FROM users
+		WHERE deleted_at IS NULL
+			AND ($1 <% name
+				OR $1 <% email
+				OR to_tsvector('simple', name || ' ' || email) @@ plainto_tsquery('simple', $1))
+		ORDER BY score DESC, name ASC
+		LIMIT $2`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, searchQuery, query, limit)
 	if err != nil {
-		return nil, entities.NewInternalError("failed to list users", err)
+		return nil, entities.NewInternalError("failed to search users", err)
 	}
 	defer rows.Close()
 
-	var users []*entities.User
+	var results []*UserSearchResult
 	for rows.Next() {
 		user := &entities.User{}
+		result := &UserSearchResult{User: user}
 		err := rows.Scan(
 			&user.ID,
 			&user.Name,
 			&user.Email,
+			&user.Role,
+			&user.Version,
 			&user.CreatedAt,
 			&user.UpdatedAt,
+			&result.Score,
 		)
 		if err != nil {
 			return nil, entities.NewInternalError("failed to scan user", err)
 		}
-		users = append(users, user)
+		results = append(results, result)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, entities.NewInternalError("error iterating rows", err)
 	}
 
-	return users, nil
+	return results, nil
+}
+
+// scanUserPage scans user rows followed by a total_count column
+func scanUserPage(rows *sql.Rows) ([]*entities.User, int, error) {
+	var (
+		users []*entities.User
+		total int
+	)
+	for rows.Next() {
+		user := &entities.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Role,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&total,
+		)
+		if err != nil {
+			return nil, 0, entities.NewInternalError("failed to scan user", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, entities.NewInternalError("error iterating rows", err)
+	}
+
+	return users, total, nil
 }
 
 func isUniqueConstraintError(err error) bool {
@@ -200,3 +464,19 @@ func containsSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+// passwordHash stores the hash of a user without a password as NULL
+func passwordHash(user *entities.User) interface{} {
+	if user.PasswordHash == "" {
+		return nil
+	}
+	return user.PasswordHash
+}
+
+// userRole stores users built without NewUser with the default role
+func userRole(user *entities.User) string {
+	if user.Role == "" {
+		return string(entities.DefaultRole)
+	}
+	return string(user.Role)
+}
@@ -28,7 +28,7 @@ func TestUserRepositoryImpl_Create(t *testing.T) {
 	defer db.Close()
 
 	repo := &UserRepositoryImpl{db: db.DB}
-	ctx := context.Background()
+	ctx := entities.ContextWithActor(context.Background(), "admin")
 
 	user := &entities.User{
 		ID:        uuid.New(),
@@ -38,10 +38,18 @@ func TestUserRepositoryImpl_Create(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	t.Run("should create user successfully", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO users \(id, name, email, created_at, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`).
-			WithArgs(user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt).
+	t.Run("should create user, audit record and event in one transaction", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users \(id, name, email, role, version, password_hash, created_at, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`).
+			WithArgs(user.ID, user.Name, user.Email, entities.RoleMember, user.Version, nil, user.CreatedAt, user.UpdatedAt).
+			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_audit_log \(id, user_id, action, actor, request_id, before, after, created_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`).
+			WithArgs(sqlxmock.AnyArg(), user.ID, "create", "admin", "", nil, sqlxmock.AnyArg(), sqlxmock.AnyArg()).
+			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_outbox \(id, event_type, user_id, payload, created_at, next_attempt_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
+			WithArgs(sqlxmock.AnyArg(), "UserCreated", user.ID, sqlxmock.AnyArg(), sqlxmock.AnyArg(), sqlxmock.AnyArg()).
 			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		err := repo.Create(ctx, user)
 		assert.NoError(t, err)
@@ -49,14 +57,66 @@ func TestUserRepositoryImpl_Create(t *testing.T) {
 	})
 
 	t.Run("should return error when email exists", func(t *testing.T) {
-		mock.ExpectExec(`INSERT INTO users \(id, name, email, created_at, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`).
-			WithArgs(user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt).
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users \(id, name, email, role, version, password_hash, created_at, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\)`).
+			WithArgs(user.ID, user.Name, user.Email, entities.RoleMember, user.Version, nil, user.CreatedAt, user.UpdatedAt).
 			WillReturnError(&testError{msg: "duplicate key value violates unique constraint"})
+		mock.ExpectRollback()
 
 		err := repo.Create(ctx, user)
 		assert.True(t, entities.IsConflictError(err))
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("should roll back when audit record cannot be written", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).
+			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_audit_log`).
+			WillReturnError(&testError{msg: "relation \"user_audit_log\" does not exist"})
+		mock.ExpectRollback()
+
+		err := repo.Create(ctx, user)
+		assert.Equal(t, entities.InternalError, err.(*entities.DomainError).Type)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when event cannot be written", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO users`).
+			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_audit_log`).
+			WillReturnResult(sqlxmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO user_outbox`).
+			WillReturnError(&testError{msg: "relation \"user_outbox\" does not exist"})
+		mock.ExpectRollback()
+
+		err := repo.Create(ctx, user)
+		assert.Equal(t, entities.InternalError, err.(*entities.DomainError).Type)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// expectLockUser expects the locking read of user; a nil user means no row
+func expectLockUser(mock sqlxmock.Sqlmock, id uuid.UUID, user *entities.User, deleted bool) {
+	rows := sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "created_at", "updated_at", "deleted"})
+	if user != nil {
+		rows.AddRow(user.ID, user.Name, user.Email, user.Role, user.Version, user.CreatedAt, user.UpdatedAt, deleted)
+	}
+	mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at, deleted_at IS NOT NULL FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(id).
+		WillReturnRows(rows)
+}
+
+// expectChangeRecorded expects the audit record of action on userID and the
+// event announcing it
+func expectChangeRecorded(mock sqlxmock.Sqlmock, userID uuid.UUID, action entities.AuditAction, eventType entities.UserEventType) {
+	mock.ExpectExec(`INSERT INTO user_audit_log`).
+		WithArgs(sqlxmock.AnyArg(), userID, string(action), entities.AnonymousActor, "", sqlxmock.AnyArg(), sqlxmock.AnyArg(), sqlxmock.AnyArg()).
+		WillReturnResult(sqlxmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO user_outbox`).
+		WithArgs(sqlxmock.AnyArg(), string(eventType), userID, sqlxmock.AnyArg(), sqlxmock.AnyArg(), sqlxmock.AnyArg()).
+		WillReturnResult(sqlxmock.NewResult(1, 1))
 }
 
 func TestUserRepositoryImpl_GetByID(t *testing.T) {
@@ -77,10 +137,10 @@ func TestUserRepositoryImpl_GetByID(t *testing.T) {
 	}
 
 	t.Run("should return user when exists", func(t *testing.T) {
-		rows := sqlxmock.NewRows([]string{"id", "name", "email", "created_at", "updated_at"}).
-			AddRow(user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt)
+		rows := sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "password_hash", "created_at", "updated_at"}).
+			AddRow(user.ID, user.Name, user.Email, user.Role, user.Version, "$2a$10$hash", user.CreatedAt, user.UpdatedAt)
 
-		mock.ExpectQuery(`SELECT id, name, email, created_at, updated_at FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, email, role, version, COALESCE\(password_hash, ''\), created_at, updated_at FROM users WHERE id = \$1`).
 			WithArgs(userID).
 			WillReturnRows(rows)
 
@@ -89,11 +149,12 @@ func TestUserRepositoryImpl_GetByID(t *testing.T) {
 		assert.Equal(t, user.ID, foundUser.ID)
 		assert.Equal(t, user.Name, foundUser.Name)
 		assert.Equal(t, user.Email, foundUser.Email)
+		assert.Equal(t, "$2a$10$hash", foundUser.PasswordHash)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("should return error when user not found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, name, email, created_at, updated_at FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, name, email, role, version, COALESCE\(password_hash, ''\), created_at, updated_at FROM users WHERE id = \$1`).
 			WithArgs(userID).
 			WillReturnError(sql.ErrNoRows)
 
@@ -112,32 +173,75 @@ func TestUserRepositoryImpl_Update(t *testing.T) {
 	repo := &UserRepositoryImpl{db: db.DB}
 	ctx := context.Background()
 
-	user := &entities.User{
+	stored := &entities.User{
 		ID:        uuid.New(),
-		Name:      "John Smith",
-		Email:     "john.smith@example.com",
+		Name:      "John Doe",
+		Email:     "john@example.com",
+		Version:   3,
+		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	newUser := func() *entities.User {
+		return &entities.User{
+			ID:        stored.ID,
+			Name:      "John Smith",
+			Email:     "john.smith@example.com",
+			Version:   3,
+			UpdatedAt: time.Now(),
+		}
+	}
 
-	t.Run("should update user successfully", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE users SET name = \$2, email = \$3, updated_at = \$4 WHERE id = \$1`).
-			WithArgs(user.ID, user.Name, user.Email, user.UpdatedAt).
+	t.Run("should update user and bump version", func(t *testing.T) {
+		user := newUser()
+		mock.ExpectBegin()
+		expectLockUser(mock, user.ID, stored, false)
+		mock.ExpectExec(`UPDATE users SET name = \$2, email = \$3, role = \$4, password_hash = \$5, updated_at = \$6, version = version \+ 1 WHERE id = \$1`).
+			WithArgs(user.ID, user.Name, user.Email, entities.RoleMember, nil, user.UpdatedAt).
 			WillReturnResult(sqlxmock.NewResult(0, 1))
+		expectChangeRecorded(mock, user.ID, entities.AuditActionUpdate, entities.UserUpdated)
+		mock.ExpectCommit()
 
 		err := repo.Update(ctx, user)
 		assert.NoError(t, err)
+		assert.Equal(t, int64(4), user.Version)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("should return error when user not found", func(t *testing.T) {
-		mock.ExpectExec(`UPDATE users SET name = \$2, email = \$3, updated_at = \$4 WHERE id = \$1`).
-			WithArgs(user.ID, user.Name, user.Email, user.UpdatedAt).
-			WillReturnResult(sqlxmock.NewResult(0, 0))
+		user := newUser()
+		mock.ExpectBegin()
+		expectLockUser(mock, user.ID, nil, false)
+		mock.ExpectRollback()
+
+		err := repo.Update(ctx, user)
+		assert.True(t, entities.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when user is deleted", func(t *testing.T) {
+		user := newUser()
+		mock.ExpectBegin()
+		expectLockUser(mock, user.ID, stored, true)
+		mock.ExpectRollback()
 
 		err := repo.Update(ctx, user)
 		assert.True(t, entities.IsNotFoundError(err))
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("should return precondition failed when version is stale", func(t *testing.T) {
+		user := newUser()
+		user.Version = 2
+		mock.ExpectBegin()
+		expectLockUser(mock, user.ID, stored, false)
+		mock.ExpectRollback()
+
+		err := repo.Update(ctx, user)
+		assert.True(t, entities.IsPreconditionFailedError(err))
+		assert.ErrorIs(t, err, entities.ErrVersionMismatch)
+		assert.Equal(t, int64(2), user.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestUserRepositoryImpl_Delete(t *testing.T) {
@@ -148,29 +252,352 @@ func TestUserRepositoryImpl_Delete(t *testing.T) {
 	repo := &UserRepositoryImpl{db: db.DB}
 	ctx := context.Background()
 
-	userID := uuid.New()
+	stored := &entities.User{ID: uuid.New(), Name: "John Doe", Email: "john@example.com", Version: 3}
 
-	t.Run("should delete user successfully", func(t *testing.T) {
-		mock.ExpectExec(`DELETE FROM users WHERE id = \$1`).
-			WithArgs(userID).
+	t.Run("should soft-delete user successfully", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, false)
+		mock.ExpectExec(`UPDATE users SET deleted_at = \$2, version = version \+ 1 WHERE id = \$1`).
+			WithArgs(stored.ID, sqlxmock.AnyArg()).
 			WillReturnResult(sqlxmock.NewResult(0, 1))
+		expectChangeRecorded(mock, stored.ID, entities.AuditActionDelete, entities.UserDeleted)
+		mock.ExpectCommit()
 
-		err := repo.Delete(ctx, userID)
+		err := repo.Delete(ctx, stored.ID, 0)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("should delete only the expected version", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, false)
+		mock.ExpectRollback()
+
+		err := repo.Delete(ctx, stored.ID, 2)
+		assert.True(t, entities.IsPreconditionFailedError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("should return error when user not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, nil, false)
+		mock.ExpectRollback()
+
+		err := repo.Delete(ctx, stored.ID, 0)
+		assert.True(t, entities.IsNotFoundError(err))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepositoryImpl_Restore(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+
+	stored := &entities.User{ID: uuid.New(), Name: "John Doe", Email: "john@example.com", Version: 4}
+
+	t.Run("should restore deleted user", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, true)
+		mock.ExpectExec(`UPDATE users SET deleted_at = NULL, updated_at = \$2, version = version \+ 1 WHERE id = \$1`).
+			WithArgs(stored.ID, sqlxmock.AnyArg()).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+		expectChangeRecorded(mock, stored.ID, entities.AuditActionRestore, entities.UserRestored)
+		mock.ExpectCommit()
+
+		err := repo.Restore(ctx, stored.ID)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return conflict when email was reused", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, true)
+		mock.ExpectExec(`UPDATE users SET deleted_at = NULL`).
+			WithArgs(stored.ID, sqlxmock.AnyArg()).
+			WillReturnError(&testError{msg: `duplicate key value violates unique constraint "idx_users_email_active"`})
+		mock.ExpectRollback()
+
+		err := repo.Restore(ctx, stored.ID)
+		assert.True(t, entities.IsConflictError(err))
+		assert.ErrorIs(t, err, entities.ErrEmailAlreadyUsed)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return conflict when user is not deleted", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, false)
+		mock.ExpectRollback()
+
+		err := repo.Restore(ctx, stored.ID)
+		assert.ErrorIs(t, err, entities.ErrUserNotDeleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepositoryImpl_Purge(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+
+	stored := &entities.User{ID: uuid.New(), Name: "John Doe", Email: "john@example.com", Version: 4}
+
+	t.Run("should purge deleted user", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, stored, true)
 		mock.ExpectExec(`DELETE FROM users WHERE id = \$1`).
-			WithArgs(userID).
-			WillReturnResult(sqlxmock.NewResult(0, 0))
+			WithArgs(stored.ID).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+		expectChangeRecorded(mock, stored.ID, entities.AuditActionPurge, entities.UserPurged)
+		mock.ExpectCommit()
 
-		err := repo.Delete(ctx, userID)
+		err := repo.Purge(ctx, stored.ID)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectLockUser(mock, stored.ID, nil, false)
+		mock.ExpectRollback()
+
+		err := repo.Purge(ctx, stored.ID)
 		assert.True(t, entities.IsNotFoundError(err))
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
+func TestUserRepositoryImpl_List(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+
+	t.Run("should return users with window total", func(t *testing.T) {
+		rows := sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "created_at", "updated_at", "total_count"}).
+			AddRow(uuid.New(), "John Doe", "john@example.com", "member", 1, time.Now(), time.Now(), 42).
+			AddRow(uuid.New(), "Jane Doe", "jane@example.com", "member", 1, time.Now(), time.Now(), 42)
+
+		mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at, COUNT\(\*\) OVER\(\) AS total_count FROM users WHERE deleted_at IS NULL ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(2, 0).
+			WillReturnRows(rows)
+
+		users, total, err := repo.List(ctx, ListParams{Limit: 2})
+		assert.NoError(t, err)
+		assert.Len(t, users, 2)
+		assert.Equal(t, 42, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should count separately when page is past the end", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at, COUNT\(\*\) OVER\(\) AS total_count FROM users`).
+			WithArgs(10, 50).
+			WillReturnRows(sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "created_at", "updated_at", "total_count"}))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM users WHERE deleted_at IS NULL`).
+			WillReturnRows(sqlxmock.NewRows([]string{"count"}).AddRow(42))
+
+		users, total, err := repo.List(ctx, ListParams{Limit: 10, Offset: 50})
+		assert.NoError(t, err)
+		assert.Empty(t, users)
+		assert.Equal(t, 42, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+	t.Run("should seek past cursor", func(t *testing.T) {
+		cursor := &Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+		rows := sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "created_at", "updated_at", "total_count"}).
+			AddRow(uuid.New(), "John Doe", "john@example.com", "member", 1, time.Now(), time.Now(), 42)
+
+		mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at, \(SELECT COUNT\(\*\) FROM users WHERE deleted_at IS NULL\) AS total_count FROM users WHERE deleted_at IS NULL AND \(created_at, id\) < \(\$1, \$2\) ORDER BY created_at DESC, id DESC LIMIT \$3`).
+			WithArgs(cursor.CreatedAt, cursor.ID, 10).
+			WillReturnRows(rows)
+
+		users, total, err := repo.List(ctx, ListParams{Limit: 10, Cursor: cursor})
+		assert.NoError(t, err)
+		assert.Len(t, users, 1)
+		assert.Equal(t, 42, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepositoryImpl_Export(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+	columns := []string{"id", "name", "email", "role", "version", "created_at", "updated_at"}
+	last := uuid.New()
+
+	mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at FROM users WHERE deleted_at IS NULL ORDER BY email ASC, id ASC LIMIT \$1`).
+		WithArgs(2).
+		WillReturnRows(sqlxmock.NewRows(columns).
+			AddRow(uuid.New(), "Jane Doe", "jane@example.com", "member", 1, time.Now(), time.Now()).
+			AddRow(last, "John Doe", "john@example.com", "member", 1, time.Now(), time.Now()))
+	mock.ExpectQuery(`WHERE deleted_at IS NULL AND \(email, id\) > \(\$1, \$2\) ORDER BY email ASC, id ASC LIMIT \$3`).
+		WithArgs("john@example.com", last, 2).
+		WillReturnRows(sqlxmock.NewRows(columns))
+
+	it := repo.Export(ctx, UserFilter{}, UserSort{Field: SortByEmail}).(*pagedUserIterator)
+	it.pageSize = 2
+
+	var emails []string
+	for it.Next() {
+		emails = append(emails, it.User().Email)
+	}
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"jane@example.com", "john@example.com"}, emails)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserRepositoryImpl_ListAudit(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+
+	t.Run("should decode snapshots", func(t *testing.T) {
+		userID := uuid.New()
+		rows := sqlxmock.NewRows([]string{"id", "user_id", "action", "actor", "request_id", "before", "after", "created_at", "total_count"}).
+			AddRow(uuid.New(), userID, "update", "admin", "req-1", `{"name":"John Doe"}`, `{"name":"John Smith"}`, time.Now(), 3)
+
+		mock.ExpectQuery(`SELECT id, user_id, action, actor, request_id, before, after, created_at, COUNT\(\*\) OVER\(\) AS total_count FROM user_audit_log WHERE user_id = \$1 AND action = \$2 ORDER BY created_at DESC, id DESC LIMIT \$3 OFFSET \$4`).
+			WithArgs(userID, "update", 10, 0).
+			WillReturnRows(rows)
+
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: userID, Action: entities.AuditActionUpdate}})
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, 3, total)
+		assert.Equal(t, entities.AuditActionUpdate, records[0].Action)
+		assert.Equal(t, "John Doe", records[0].Before.Name)
+		assert.Equal(t, "John Smith", records[0].After.Name)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should count separately when page is past the end", func(t *testing.T) {
+		mock.ExpectQuery(`FROM user_audit_log ORDER BY created_at DESC, id DESC LIMIT \$1 OFFSET \$2`).
+			WithArgs(10, 50).
+			WillReturnRows(sqlxmock.NewRows([]string{"id", "user_id", "action", "actor", "request_id", "before", "after", "created_at", "total_count"}))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM user_audit_log`).
+			WillReturnRows(sqlxmock.NewRows([]string{"count"}).AddRow(7))
+
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Offset: 50})
+		assert.NoError(t, err)
+		assert.Empty(t, records)
+		assert.Equal(t, 7, total)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepositoryImpl_Dispatch(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+	userID := uuid.New()
+	delivered, failed := uuid.New(), uuid.New()
+	payload := func(id uuid.UUID, eventType entities.UserEventType) string {
+		return `{"id":"` + id.String() + `","type":"` + string(eventType) + `","user_id":"` + userID.String() + `","user":null,"occurred_at":"2024-01-01T00:00:00Z"}`
+	}
+
+	t.Run("should lease claimed events and record each outcome after committing", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT o.id, o.payload, o.attempts FROM user_outbox o .* LIMIT \$3 FOR UPDATE OF o SKIP LOCKED`).
+			WithArgs(sqlxmock.AnyArg(), sqlxmock.AnyArg(), 10).
+			WillReturnRows(sqlxmock.NewRows([]string{"id", "payload", "attempts"}).
+				AddRow(delivered, payload(delivered, entities.UserCreated), 0).
+				AddRow(failed, payload(failed, entities.UserUpdated), 2))
+		mock.ExpectExec(`UPDATE user_outbox SET next_attempt_at = \$1 WHERE id IN \(\$2, \$3\)`).
+			WithArgs(sqlxmock.AnyArg(), delivered, failed).
+			WillReturnResult(sqlxmock.NewResult(0, 2))
+		mock.ExpectCommit()
+		mock.ExpectExec(`UPDATE user_outbox SET published_at = \$1, attempts = attempts \+ 1 WHERE id = \$2`).
+			WithArgs(sqlxmock.AnyArg(), delivered).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE user_outbox SET next_attempt_at = \$1, attempts = attempts \+ 1, last_error = \$2 WHERE id = \$3`).
+			WithArgs(sqlxmock.AnyArg(), "subscriber unavailable", failed).
+			WillReturnResult(sqlxmock.NewResult(0, 1))
+
+		var attempts int
+		deliver := func(ctx context.Context, event *entities.UserEvent) error {
+			if event.ID == failed {
+				return &testError{msg: "subscriber unavailable"}
+			}
+			return nil
+		}
+		backoff := func(n int) time.Duration {
+			attempts = n
+			return time.Minute
+		}
+
+		claimed, err := repo.Dispatch(ctx, 10, time.Minute, deliver, backoff)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, claimed)
+		assert.Equal(t, 3, attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should roll back when claiming fails", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`FROM user_outbox o`).
+			WillReturnError(&testError{msg: "relation \"user_outbox\" does not exist"})
+		mock.ExpectRollback()
+
+		_, err := repo.Dispatch(ctx, 10, time.Minute, nil, nil)
+		assert.Equal(t, entities.InternalError, err.(*entities.DomainError).Type)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUserRepositoryImpl_Search(t *testing.T) {
+	db, mock, err := sqlxmock.Newx()
+	require.NoError(t, err)
+	defer db.Close()
+
+	repo := &UserRepositoryImpl{db: db.DB}
+	ctx := context.Background()
+
+	t.Run("should return scored users", func(t *testing.T) {
+		rows := sqlxmock.NewRows([]string{"id", "name", "email", "role", "version", "created_at", "updated_at", "score"}).
+			AddRow(uuid.New(), "John Doe", "john@example.com", "member", 1, time.Now(), time.Now(), 0.8)
+
+		mock.ExpectQuery(`SELECT id, name, email, role, version, created_at, updated_at, GREATEST\(word_similarity\(\$1, name\), word_similarity\(\$1, email\)\) .* FROM users WHERE deleted_at IS NULL AND \(\$1 <% name .* ORDER BY score DESC, name ASC LIMIT \$2`).
+			WithArgs("jonh", 10).
+			WillReturnRows(rows)
+
+		results, err := repo.Search(ctx, "jonh", 10)
+		assert.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "John Doe", results[0].User.Name)
+		assert.Equal(t, 0.8, results[0].Score)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("should wrap query errors", func(t *testing.T) {
+		mock.ExpectQuery(`FROM users WHERE deleted_at IS NULL AND \(\$1 <% name`).
+			WithArgs("jonh", 10).
+			WillReturnError(&testError{msg: "connection failed"})
+
+		results, err := repo.Search(ctx, "jonh", 10)
+		assert.True(t, entities.IsInternalError(err))
+		assert.Nil(t, results)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestIsUniqueConstraintError(t *testing.T) {
 	tests := []struct {
 		name     string
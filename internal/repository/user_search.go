@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"go-clean-code/internal/entities"
+)
+
+// UserSearchResult is a user matched by Search with its relevance score.
+// Higher scores are better matches.
+type UserSearchResult struct {
+	User  *entities.User
+	Score float64
+}
+
+// searchThreshold is the minimum fallback score for a user to match,
+// mirroring the pg_trgm default similarity threshold
+const searchThreshold = 0.3
+
+// scoreUser is the fallback relevance scoring used by backends without
+// full-text search: the best match of query against name and email
+func scoreUser(query string, user *entities.User) float64 {
+	nameScore := matchScore(query, user.Name)
+	emailScore := matchScore(query, user.Email)
+	if emailScore > nameScore {
+		return emailScore
+	}
+	return nameScore
+}
+
+// matchScore ranks exact, prefix and substring matches above fuzzy trigram matches
+func matchScore(query, value string) float64 {
+	q := strings.ToLower(strings.TrimSpace(query))
+	v := strings.ToLower(value)
+
+	switch {
+	case q == "":
+		return 0
+	case v == q:
+		return 1
+	case strings.HasPrefix(v, q):
+		return 0.9
+	case strings.Contains(v, q):
+		return 0.75
+	}
+	return trigramSimilarity(q, v)
+}
+
+// trigramSimilarity approximates pg_trgm similarity(): the Jaccard index of
+// the padded word trigrams of a and b
+func trigramSimilarity(a, b string) float64 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+func trigrams(s string) map[string]bool {
+	set := make(map[string]bool)
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// rankSearchResults orders results by descending score, breaking ties by
+// name, and keeps at most limit entries
+func rankSearchResults(results []*UserSearchResult, limit int) []*UserSearchResult {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].User.Name < results[j].User.Name
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
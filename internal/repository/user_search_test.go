@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		value    string
+		expected float64
+	}{
+		{"should score exact match highest", "John Doe", "john doe", 1},
+		{"should score prefix match", "john", "John Doe", 0.9},
+		{"should score substring match", "doe", "John Doe", 0.75},
+		{"should ignore blank query", "  ", "John Doe", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchScore(tt.query, tt.value))
+		})
+	}
+}
+
+func TestTrigramSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, trigramSimilarity("john", "JOHN"))
+	assert.Greater(t, trigramSimilarity("jonh@example.com", "john@example.com"), searchThreshold)
+	assert.Less(t, trigramSimilarity("zzzz", "john@example.com"), searchThreshold)
+	assert.Zero(t, trigramSimilarity("", "john"))
+}
@@ -0,0 +1,366 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// UserSQLiteRepository is a SQLite implementation of UserRepositoryInterface
+// for deployments where PostgreSQL is not available.
+type UserSQLiteRepository struct {
+	db *sql.DB
+	repositoryOptions
+}
+
+func NewUserSQLiteRepository(db *sql.DB, opts ...RepositoryOption) *UserSQLiteRepository {
+	r := &UserSQLiteRepository{
+		db: db,
+	}
+	for _, opt := range opts {
+		opt(&r.repositoryOptions)
+	}
+	return r
+}
+
+func (r *UserSQLiteRepository) changes() changeLog {
+	return changeLog{dialect: sqliteDialect, skipEvents: r.skipEvents}
+}
+
+func (r *UserSQLiteRepository) Create(ctx context.Context, user *entities.User) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		query := `
+			INSERT INTO users (id, name, email, role, version, password_hash, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+		_, err := tx.ExecContext(ctx, query, user.ID, user.Name, user.Email, userRole(user), user.Version, passwordHash(user), user.CreatedAt.UTC(), user.UpdatedAt.UTC())
+		if err != nil {
+			if isSQLiteConstraintError(err) {
+				return nil, entities.NewConflictError("user already exists", entities.ErrUserAlreadyExists)
+			}
+			return nil, entities.NewInternalError("failed to create user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionCreate, user.ID, nil, user), nil
+	})
+}
+
+// CreateBatch inserts users with one multi-row INSERT; see UserRepositoryInterface
+func (r *UserSQLiteRepository) CreateBatch(ctx context.Context, users []*entities.User) ([]uuid.UUID, error) {
+	return createBatch(ctx, r.db, r.changes(), users)
+}
+
+func (r *UserSQLiteRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
+	query := `
+		SELECT id, name, email, role, version, COALESCE(password_hash, ''), created_at, updated_at
+		FROM users
+		WHERE id = ? AND deleted_at IS NULL`
+
+	user := &entities.User{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.PasswordHash,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.NewNotFoundError("user not found", entities.ErrUserNotFound)
+		}
+		return nil, entities.NewInternalError("failed to get user by ID", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserSQLiteRepository) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	query := `
+		SELECT id, name, email, role, version, COALESCE(password_hash, ''), created_at, updated_at
+		FROM users
+		WHERE email = ? AND deleted_at IS NULL`
+
+	user := &entities.User{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.PasswordHash,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, entities.NewNotFoundError("user not found by email", entities.ErrUserNotFound)
+		}
+		return nil, entities.NewInternalError("failed to get user by email", err)
+	}
+
+	return user, nil
+}
+
+func (r *UserSQLiteRepository) Update(ctx context.Context, user *entities.User) error {
+	err := withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.readUser(ctx, tx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil || deleted {
+			return nil, entities.NewNotFoundError("user not found for update", entities.ErrUserNotFound)
+		}
+		if before.Version != user.Version {
+			return nil, entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+		}
+
+		query := `
+			UPDATE users
+			SET name = ?, email = ?, role = ?, password_hash = ?, updated_at = ?, version = version + 1
+			WHERE id = ?`
+
+		if _, err := tx.ExecContext(ctx, query, user.Name, user.Email, userRole(user), passwordHash(user), user.UpdatedAt.UTC(), user.ID); err != nil {
+			if isSQLiteConstraintError(err) {
+				return nil, entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+			}
+			return nil, entities.NewInternalError("failed to update user", err)
+		}
+
+		after := *user
+		after.Version++
+		return entities.NewAuditRecord(ctx, entities.AuditActionUpdate, user.ID, before, &after), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	user.Version++
+	return nil
+}
+
+// Delete soft-deletes the user by setting deleted_at
+func (r *UserSQLiteRepository) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.readUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil || deleted {
+			return nil, entities.NewNotFoundError("user not found for deletion", entities.ErrUserNotFound)
+		}
+		if version != 0 && before.Version != version {
+			return nil, entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+		}
+
+		query := `UPDATE users SET deleted_at = ?, version = version + 1 WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, time.Now().UTC(), id); err != nil {
+			return nil, entities.NewInternalError("failed to delete user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionDelete, id, before, nil), nil
+	})
+}
+
+func (r *UserSQLiteRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.readUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, entities.NewNotFoundError("user not found for restore", entities.ErrUserNotFound)
+		}
+		if !deleted {
+			return nil, entities.NewConflictError("user is not deleted", entities.ErrUserNotDeleted)
+		}
+
+		after := *before
+		after.Version++
+		after.UpdatedAt = time.Now().UTC()
+
+		query := `UPDATE users SET deleted_at = NULL, updated_at = ?, version = version + 1 WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, after.UpdatedAt, id); err != nil {
+			if isSQLiteConstraintError(err) {
+				return nil, entities.NewConflictError("cannot restore user: its email is now used by another user", entities.ErrEmailAlreadyUsed)
+			}
+			return nil, entities.NewInternalError("failed to restore user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionRestore, id, before, &after), nil
+	})
+}
+
+func (r *UserSQLiteRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	return withChangeTx(ctx, r.db, r.changes(), func(tx dbtx) (*entities.AuditRecord, error) {
+		before, deleted, err := r.readUser(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		if before == nil {
+			return nil, entities.NewNotFoundError("user not found for purge", entities.ErrUserNotFound)
+		}
+		if !deleted {
+			return nil, entities.NewConflictError("user must be deleted before it can be purged", entities.ErrUserNotDeleted)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id); err != nil {
+			return nil, entities.NewInternalError("failed to purge user", err)
+		}
+
+		return entities.NewAuditRecord(ctx, entities.AuditActionPurge, id, before, nil), nil
+	})
+}
+
+// readUser reads the stored user, soft-deleted or not, inside tx. SQLite has
+// no row locks; the single connection already serializes transactions.
+// user is nil when no row exists.
+func (r *UserSQLiteRepository) readUser(ctx context.Context, tx dbtx, id uuid.UUID) (user *entities.User, deleted bool, err error) {
+	query := `
+		SELECT id, name, email, role, version, created_at, updated_at, deleted_at IS NOT NULL
+		FROM users
+		WHERE id = ?`
+
+	user = &entities.User{}
+	err = tx.QueryRowContext(ctx, query, id).Scan(
+		&user.ID,
+		&user.Name,
+		&user.Email,
+		&user.Role,
+		&user.Version,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&deleted,
+	)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, entities.NewInternalError("failed to read user", err)
+	}
+	return user, deleted, nil
+}
+
+func (r *UserSQLiteRepository) List(ctx context.Context, params ListParams) ([]*entities.User, int, error) {
+	query, args := buildListQuery(sqliteDialect, params)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, entities.NewInternalError("failed to list users", err)
+	}
+	defer rows.Close()
+
+	users, total, err := scanUserPage(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The page count is unavailable when no row comes back
+	if len(users) == 0 && (params.Offset > 0 || params.Cursor != nil) {
+		countQuery, countArgs := buildCountQuery(sqliteDialect, params.Filter)
+		if err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, entities.NewInternalError("failed to count users", err)
+		}
+	}
+
+	return users, total, nil
+}
+
+// Export reads the matching users in pages of ExportPageSize, releasing the
+// single connection between pages
+func (r *UserSQLiteRepository) Export(ctx context.Context, filter UserFilter, sort UserSort) UserIterator {
+	return newPagedUserIterator(ctx, func(ctx context.Context, after *entities.User, limit int) ([]*entities.User, error) {
+		return fetchExportPage(ctx, conn(ctx, r.db), sqliteDialect, filter, sort, after, limit)
+	})
+}
+
+// ListAudit returns a newest-first page of audit records with their total count
+func (r *UserSQLiteRepository) ListAudit(ctx context.Context, params AuditListParams) ([]*entities.AuditRecord, int, error) {
+	query, args := buildAuditListQuery(sqliteDialect, params)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, entities.NewInternalError("failed to list audit records", err)
+	}
+	defer rows.Close()
+
+	records, total, err := scanAuditPage(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(records) == 0 && params.Offset > 0 {
+		countQuery, countArgs := buildAuditCountQuery(sqliteDialect, params.Filter)
+		if err := conn(ctx, r.db).QueryRowContext(ctx, countQuery, countArgs...).Scan(&total); err != nil {
+			return nil, 0, entities.NewInternalError("failed to count audit records", err)
+		}
+	}
+
+	return records, total, nil
+}
+
+// Dispatch delivers pending outbox events; see OutboxRepositoryInterface
+func (r *UserSQLiteRepository) Dispatch(ctx context.Context, limit int, lease time.Duration, deliver DeliverFunc, backoff BackoffFunc) (int, error) {
+	return dispatchOutbox(ctx, r.db, sqliteDialect, limit, lease, deliver, backoff)
+}
+
+// Prune deletes published outbox events; see OutboxRepositoryInterface
+func (r *UserSQLiteRepository) Prune(ctx context.Context, publishedBefore time.Time) (int, error) {
+	return pruneOutbox(ctx, r.db, sqliteDialect, publishedBefore)
+}
+
+// Search scores every user in Go since SQLite lacks trigram and full-text
+// ranking without extensions
+func (r *UserSQLiteRepository) Search(ctx context.Context, query string, limit int) ([]*UserSearchResult, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `SELECT id, name, email, role, version, created_at, updated_at FROM users WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to search users", err)
+	}
+	defer rows.Close()
+
+	var results []*UserSearchResult
+	for rows.Next() {
+		user := &entities.User{}
+		err := rows.Scan(
+			&user.ID,
+			&user.Name,
+			&user.Email,
+			&user.Role,
+			&user.Version,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return nil, entities.NewInternalError("failed to scan user", err)
+		}
+		if score := scoreUser(query, user); score >= searchThreshold {
+			results = append(results, &UserSearchResult{User: user, Score: score})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, entities.NewInternalError("error iterating rows", err)
+	}
+
+	return rankSearchResults(results, limit), nil
+}
+
+// isSQLiteConstraintError reports whether err is a SQLite UNIQUE or PRIMARY KEY violation
+func isSQLiteConstraintError(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
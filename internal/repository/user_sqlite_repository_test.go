@@ -0,0 +1,393 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteTestRepository(t *testing.T) *UserSQLiteRepository {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrations, err := filepath.Glob("../../migrations/sqlite/*.up.sql")
+	require.NoError(t, err)
+	sort.Strings(migrations)
+	for _, migration := range migrations {
+		schema, err := os.ReadFile(migration)
+		require.NoError(t, err)
+		_, err = db.Exec(string(schema))
+		require.NoError(t, err)
+	}
+
+	return NewUserSQLiteRepository(db)
+}
+
+func TestUserSQLiteRepository_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create user successfully", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+
+		assert.NoError(t, repo.Create(ctx, user))
+
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+		assert.Equal(t, user.Email, found.Email)
+		assert.True(t, user.CreatedAt.Equal(found.CreatedAt))
+	})
+
+	t.Run("should return conflict error when email exists", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		require.NoError(t, repo.Create(ctx, newTestUser("John Doe", "john@example.com", time.Now())))
+
+		err := repo.Create(ctx, newTestUser("Other John", "john@example.com", time.Now()))
+		assert.True(t, entities.IsConflictError(err))
+	})
+}
+
+func TestUserSQLiteRepository_GetByEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+
+	t.Run("should return user when exists", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, user.Email)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, found.ID)
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		found, err := repo.GetByEmail(ctx, "missing@example.com")
+		assert.True(t, entities.IsNotFoundError(err))
+		assert.Nil(t, found)
+	})
+}
+
+func TestUserSQLiteRepository_Update(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should update user successfully", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		user.Name = "John Smith"
+		assert.NoError(t, repo.Update(ctx, user))
+
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "John Smith", found.Name)
+	})
+
+	t.Run("should return conflict error when email used by another user", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		other := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Create(ctx, other))
+
+		user.Email = other.Email
+		err := repo.Update(ctx, user)
+		assert.True(t, entities.IsConflictError(err))
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		err := repo.Update(ctx, newTestUser("John Doe", "john@example.com", time.Now()))
+		assert.True(t, entities.IsNotFoundError(err))
+	})
+}
+
+func TestUserSQLiteRepository_Versioning(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+
+	stale, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stale.Version)
+
+	user.Name = "John Smith"
+	require.NoError(t, repo.Update(ctx, user))
+	assert.Equal(t, int64(2), user.Version)
+
+	stale.Name = "Johnny"
+	err = repo.Update(ctx, stale)
+	assert.True(t, entities.IsPreconditionFailedError(err))
+
+	found, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "John Smith", found.Name)
+	assert.Equal(t, int64(2), found.Version)
+
+	err = repo.Delete(ctx, user.ID, 1)
+	assert.True(t, entities.IsPreconditionFailedError(err))
+	assert.NoError(t, repo.Delete(ctx, user.ID, 2))
+
+	err = repo.Delete(ctx, user.ID, 2)
+	assert.True(t, entities.IsNotFoundError(err))
+}
+
+func TestUserSQLiteRepository_Delete(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+
+	t.Run("should delete user successfully", func(t *testing.T) {
+		assert.NoError(t, repo.Delete(ctx, user.ID, 0))
+	})
+
+	t.Run("should return error when user not found", func(t *testing.T) {
+		err := repo.Delete(ctx, uuid.New(), 0)
+		assert.True(t, entities.IsNotFoundError(err))
+	})
+}
+
+func TestUserSQLiteRepository_SoftDelete(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should hide deleted user and restore it", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+
+		_, err := repo.GetByID(ctx, user.ID)
+		assert.True(t, entities.IsNotFoundError(err))
+		users, total, err := repo.List(ctx, ListParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, users)
+		assert.Zero(t, total)
+
+		require.NoError(t, repo.Restore(ctx, user.ID))
+		found, err := repo.GetByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), found.Version)
+	})
+
+	t.Run("should refuse restore when email was reused", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+		require.NoError(t, repo.Create(ctx, newTestUser("John Again", "john@example.com", time.Now())))
+
+		err := repo.Restore(ctx, user.ID)
+		assert.True(t, entities.IsConflictError(err))
+		assert.ErrorIs(t, err, entities.ErrEmailAlreadyUsed)
+	})
+
+	t.Run("should refuse restore of active user", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		assert.ErrorIs(t, repo.Restore(ctx, user.ID), entities.ErrUserNotDeleted)
+		assert.True(t, entities.IsNotFoundError(repo.Restore(ctx, uuid.New())))
+	})
+
+	t.Run("should purge only deleted users", func(t *testing.T) {
+		repo := newSQLiteTestRepository(t)
+		user := newTestUser("John Doe", "john@example.com", time.Now())
+		require.NoError(t, repo.Create(ctx, user))
+
+		assert.ErrorIs(t, repo.Purge(ctx, user.ID), entities.ErrUserNotDeleted)
+
+		require.NoError(t, repo.Delete(ctx, user.ID, 0))
+		require.NoError(t, repo.Purge(ctx, user.ID))
+		assert.True(t, entities.IsNotFoundError(repo.Restore(ctx, user.ID)))
+	})
+}
+
+func TestUserSQLiteRepository_Audit(t *testing.T) {
+	ctx := entities.ContextWithRequestID(entities.ContextWithActor(context.Background(), "admin"), "req-1")
+	repo := newSQLiteTestRepository(t)
+
+	user := newTestUser("John Doe", "john@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, user))
+	require.NoError(t, user.UpdateName("John Smith"))
+	require.NoError(t, repo.Update(ctx, user))
+	require.NoError(t, repo.Delete(ctx, user.ID, 0))
+	require.NoError(t, repo.Restore(ctx, user.ID))
+	require.NoError(t, repo.Delete(ctx, user.ID, 0))
+	require.NoError(t, repo.Purge(ctx, user.ID))
+
+	t.Run("should record every change newest first", func(t *testing.T) {
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: user.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 6, total)
+		require.Len(t, records, 6)
+
+		var actions []entities.AuditAction
+		for _, record := range records {
+			actions = append(actions, record.Action)
+			assert.Equal(t, "admin", record.Actor)
+			assert.Equal(t, "req-1", record.RequestID)
+		}
+		assert.Equal(t, []entities.AuditAction{
+			entities.AuditActionPurge,
+			entities.AuditActionDelete,
+			entities.AuditActionRestore,
+			entities.AuditActionDelete,
+			entities.AuditActionUpdate,
+			entities.AuditActionCreate,
+		}, actions)
+
+		created, updated, purged := records[5], records[4], records[0]
+		assert.Nil(t, created.Before)
+		assert.Equal(t, "John Doe", created.After.Name)
+		assert.Equal(t, "John Doe", updated.Before.Name)
+		assert.Equal(t, "John Smith", updated.After.Name)
+		assert.Equal(t, int64(2), updated.After.Version)
+		assert.NotNil(t, purged.Before)
+		assert.Nil(t, purged.After)
+	})
+
+	t.Run("should filter by action and page", func(t *testing.T) {
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 1, Offset: 1, Filter: AuditFilter{Action: entities.AuditActionDelete}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("should not record failed changes", func(t *testing.T) {
+		other := newTestUser("Jane Doe", "jane@example.com", time.Now())
+		require.NoError(t, repo.Create(context.Background(), other))
+		other.Version = 5
+		require.Error(t, repo.Update(ctx, other))
+
+		records, total, err := repo.ListAudit(ctx, AuditListParams{Limit: 10, Filter: AuditFilter{UserID: other.ID}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, entities.AnonymousActor, records[0].Actor)
+	})
+}
+
+func TestUserSQLiteRepository_Dispatch(t *testing.T) {
+	testDispatch(t, func(t *testing.T) outboxTestRepository { return newSQLiteTestRepository(t) })
+}
+
+func TestUserSQLiteRepository_Prune(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+	deliver := func(context.Context, *entities.UserEvent) error { return nil }
+
+	require.NoError(t, repo.Create(ctx, newTestUser("John Doe", "john@example.com", time.Now())))
+	require.NoError(t, repo.Create(ctx, newTestUser("Jane Doe", "jane@example.com", time.Now())))
+	claimed, err := repo.Dispatch(ctx, 10, time.Minute, deliver, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, claimed)
+	require.NoError(t, repo.Create(ctx, newTestUser("Jim Doe", "jim@example.com", time.Now())))
+
+	deleted, err := repo.Prune(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted, "recently published events should be kept")
+
+	deleted, err = repo.Prune(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	claimed, err = repo.Dispatch(ctx, 10, time.Minute, deliver, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed, "pending events should never be pruned")
+}
+
+func TestUserSQLiteRepository_WithoutEvents(t *testing.T) {
+	repo := newSQLiteTestRepository(t)
+	WithoutEvents()(&repo.repositoryOptions)
+	testWithoutEvents(t, repo)
+}
+
+func TestUserSQLiteRepository_List(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+
+	base := time.Now()
+	oldest := newTestUser("Oldest", "oldest@example.com", base.Add(-2*time.Hour))
+	newest := newTestUser("Newest", "newest@example.com", base)
+	require.NoError(t, repo.Create(ctx, oldest))
+	require.NoError(t, repo.Create(ctx, newest))
+
+	users, total, err := repo.List(ctx, ListParams{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, 2, total)
+	assert.Equal(t, newest.ID, users[0].ID)
+	assert.Equal(t, oldest.ID, users[1].ID)
+
+	users, total, err = repo.List(ctx, ListParams{Limit: 10, Offset: 5})
+	require.NoError(t, err)
+	assert.Empty(t, users)
+	assert.Equal(t, 2, total)
+	users, total, err = repo.List(ctx, ListParams{Limit: 10, Cursor: &Cursor{CreatedAt: newest.CreatedAt, ID: newest.ID}})
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, oldest.ID, users[0].ID)
+	assert.Equal(t, 2, total)
+}
+
+func TestUserSQLiteRepository_ListFilterAndSort(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+
+	base := time.Now()
+	alice := newTestUser("Alice Doe", "alice@example.com", base.Add(-2*time.Hour))
+	bob := newTestUser("Bob Doe", "bob@other.org", base.Add(-time.Hour))
+	carol := newTestUser("Carol Smith", "carol@example.com", base)
+	for _, u := range []*entities.User{alice, bob, carol} {
+		require.NoError(t, repo.Create(ctx, u))
+	}
+
+	users, total, err := repo.List(ctx, ListParams{
+		Limit:  10,
+		Filter: UserFilter{EmailDomain: "example.com", CreatedAfter: base.Add(-3 * time.Hour)},
+		Sort:   UserSort{Field: SortByEmail, Descending: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, users, 2)
+	assert.Equal(t, carol.ID, users[0].ID)
+	assert.Equal(t, alice.ID, users[1].ID)
+
+	users, total, err = repo.List(ctx, ListParams{Limit: 10, Filter: UserFilter{NamePrefix: "bo"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, users, 1)
+	assert.Equal(t, bob.ID, users[0].ID)
+}
+
+func TestUserSQLiteRepository_Search(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLiteTestRepository(t)
+
+	john := newTestUser("John Doe", "john.doe@example.com", time.Now())
+	jane := newTestUser("Jane Smith", "jane.smith@example.com", time.Now())
+	require.NoError(t, repo.Create(ctx, john))
+	require.NoError(t, repo.Create(ctx, jane))
+
+	results, err := repo.Search(ctx, "Jon Doe", 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, results)
+	assert.Equal(t, john.ID, results[0].User.ID)
+	assert.True(t, john.CreatedAt.Equal(results[0].User.CreatedAt))
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"errors"
+
+	"go-clean-code/internal/entities"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UnknownErrorType labels errors that are not a *entities.DomainError
+const UnknownErrorType = "UNKNOWN"
+
+// UserID is the attribute naming the user an operation acts on
+func UserID(id string) attribute.KeyValue {
+	return semconv.UserID(id)
+}
+
+// ErrorType is the attribute naming the kind of error an operation failed with
+func ErrorType(errorType entities.ErrorType) attribute.KeyValue {
+	return semconv.ErrorTypeKey.String(string(errorType))
+}
+
+// RecordError marks span as failed with err, labelled with the
+// entities.ErrorType it carries; nil errors are ignored
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	errorType := entities.ErrorType(UnknownErrorType)
+	var domainErr *entities.DomainError
+	if errors.As(err, &domainErr) {
+		errorType = domainErr.Type
+	}
+	span.SetAttributes(ErrorType(errorType))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
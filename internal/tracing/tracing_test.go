@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/tracing/tracingtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestRecordError(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	tracer := otel.Tracer("test")
+
+	for name, err := range map[string]error{
+		"ok":        nil,
+		"not found": entities.NewNotFoundError("user not found", entities.ErrUserNotFound),
+		"unknown":   errors.New("connection reset"),
+	} {
+		_, span := tracer.Start(context.Background(), name)
+		RecordError(span, err)
+		span.End()
+	}
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	ok := tracingtest.Find(spans, "ok")
+	require.NotNil(t, ok)
+	assert.Equal(t, codes.Unset, ok.Status.Code)
+	assert.NotContains(t, tracingtest.Attributes(ok), "error.type")
+	assert.Empty(t, ok.Events)
+
+	notFound := tracingtest.Find(spans, "not found")
+	require.NotNil(t, notFound)
+	assert.Equal(t, codes.Error, notFound.Status.Code)
+	assert.Equal(t, string(entities.NotFoundError), tracingtest.Attributes(notFound)["error.type"])
+	require.Len(t, notFound.Events, 1)
+	assert.Equal(t, "exception", notFound.Events[0].Name)
+
+	unknown := tracingtest.Find(spans, "unknown")
+	require.NotNil(t, unknown)
+	assert.Equal(t, codes.Error, unknown.Status.Code)
+	assert.Equal(t, UnknownErrorType, tracingtest.Attributes(unknown)["error.type"])
+}
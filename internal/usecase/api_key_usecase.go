@@ -0,0 +1,112 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyUsecaseInterface interface {
+	CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context) (*dto.ListAPIKeysResponse, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// AuthenticateAPIKey returns the active key matching key and records
+	// that it was used
+	AuthenticateAPIKey(ctx context.Context, key string) (*entities.APIKey, error)
+}
+
+// apiKeyLastUsedResolution bounds how often use of a key is written back,
+// so busy keys do not cost a write per request
+const apiKeyLastUsedResolution = time.Minute
+
+type APIKeyUsecase struct {
+	repo repository.APIKeyRepositoryInterface
+	now  func() time.Time
+}
+
+func NewAPIKeyUsecase(repo repository.APIKeyRepositoryInterface) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+func (u *APIKeyUsecase) CreateAPIKey(ctx context.Context, req dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	apiKey, key, err := entities.NewAPIKey(req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, entities.ErrInvalidName), errors.Is(err, entities.ErrInvalidScope), errors.Is(err, entities.ErrInvalidExpiry):
+			return nil, entities.NewValidationError("invalid API key", err)
+		default:
+			return nil, entities.NewInternalError("failed to generate API key", err)
+		}
+	}
+
+	if err := u.repo.CreateAPIKey(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &dto.CreateAPIKeyResponse{
+		APIKeyResponse: *newAPIKeyResponse(apiKey),
+		Key:            key,
+	}, nil
+}
+
+func (u *APIKeyUsecase) ListAPIKeys(ctx context.Context) (*dto.ListAPIKeysResponse, error) {
+	keys, err := u.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.APIKeyResponse, len(keys))
+	for i, key := range keys {
+		responses[i] = newAPIKeyResponse(key)
+	}
+	return &dto.ListAPIKeysResponse{
+		APIKeys: responses,
+		Total:   len(responses),
+	}, nil
+}
+
+func (u *APIKeyUsecase) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	return u.repo.RevokeAPIKey(ctx, id, u.now())
+}
+
+func (u *APIKeyUsecase) AuthenticateAPIKey(ctx context.Context, key string) (*entities.APIKey, error) {
+	apiKey, err := u.repo.GetAPIKeyByHash(ctx, entities.HashAPIKey(key))
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	if !apiKey.Active(now) {
+		return nil, entities.NewUnauthorizedError("API key expired or revoked", entities.ErrInvalidAPIKey)
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyLastUsedResolution {
+		if err := u.repo.TouchAPIKey(ctx, apiKey.ID, now); err != nil {
+			return nil, err
+		}
+		apiKey.LastUsedAt = &now
+	}
+	return apiKey, nil
+}
+
+func newAPIKeyResponse(key *entities.APIKey) *dto.APIKeyResponse {
+	return &dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyUsecase(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should create a key shown only once", func(t *testing.T) {
+		usecase := NewAPIKeyUsecase(repository.NewUserMemoryRepository())
+
+		created, err := usecase.CreateAPIKey(ctx, dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{entities.ScopeUsersRead}})
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(created.Key, created.Prefix+"_"))
+		assert.True(t, strings.HasPrefix(created.Prefix, entities.APIKeyPrefix))
+		assert.Equal(t, []string{entities.ScopeUsersRead}, created.Scopes)
+
+		listed, err := usecase.ListAPIKeys(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, listed.Total)
+		assert.Equal(t, created.APIKeyResponse, *listed.APIKeys[0])
+	})
+
+	t.Run("should validate new keys", func(t *testing.T) {
+		usecase := NewAPIKeyUsecase(repository.NewUserMemoryRepository())
+		past := time.Now().Add(-time.Minute)
+
+		tests := []struct {
+			req  dto.CreateAPIKeyRequest
+			code string
+		}{
+			{dto.CreateAPIKeyRequest{Name: " ", Scopes: []string{entities.ScopeUsersRead}}, entities.CodeInvalidName},
+			{dto.CreateAPIKeyRequest{Name: "billing"}, entities.CodeInvalidScope},
+			{dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{"users:purge"}}, entities.CodeInvalidScope},
+			{dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{entities.ScopeUsersRead}, ExpiresAt: &past}, entities.CodeInvalidExpiry},
+		}
+		for _, tt := range tests {
+			_, err := usecase.CreateAPIKey(ctx, tt.req)
+			assert.True(t, entities.IsValidationError(err))
+			assert.Equal(t, tt.code, entities.ErrorCode(err))
+		}
+	})
+
+	t.Run("should authenticate active keys and record their use", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		usecase := NewAPIKeyUsecase(repo)
+		created, err := usecase.CreateAPIKey(ctx, dto.CreateAPIKeyRequest{Name: "billing", Scopes: []string{entities.ScopeUsersRead}})
+		assert.NoError(t, err)
+
+		key, err := usecase.AuthenticateAPIKey(ctx, created.Key)
+		assert.NoError(t, err)
+		assert.Equal(t, created.ID, key.ID)
+		stored, err := repo.GetAPIKeyByHash(ctx, key.KeyHash)
+		assert.NoError(t, err)
+		firstUse := *stored.LastUsedAt
+
+		// Uses within the resolution are not written back
+		_, err = usecase.AuthenticateAPIKey(ctx, created.Key)
+		assert.NoError(t, err)
+		stored, _ = repo.GetAPIKeyByHash(ctx, key.KeyHash)
+		assert.Equal(t, firstUse, *stored.LastUsedAt)
+
+		usecase.now = func() time.Time { return time.Now().Add(2 * apiKeyLastUsedResolution) }
+		_, err = usecase.AuthenticateAPIKey(ctx, created.Key)
+		assert.NoError(t, err)
+		stored, _ = repo.GetAPIKeyByHash(ctx, key.KeyHash)
+		assert.True(t, stored.LastUsedAt.After(firstUse))
+	})
+
+	t.Run("should reject unknown, expired and revoked keys", func(t *testing.T) {
+		usecase := NewAPIKeyUsecase(repository.NewUserMemoryRepository())
+		expiresAt := time.Now().Add(time.Hour)
+		expiring, err := usecase.CreateAPIKey(ctx, dto.CreateAPIKeyRequest{Name: "expiring", Scopes: []string{entities.ScopeUsersRead}, ExpiresAt: &expiresAt})
+		assert.NoError(t, err)
+		revoked, err := usecase.CreateAPIKey(ctx, dto.CreateAPIKeyRequest{Name: "revoked", Scopes: []string{entities.ScopeUsersRead}})
+		assert.NoError(t, err)
+		assert.NoError(t, usecase.RevokeAPIKey(ctx, revoked.ID))
+
+		_, err = usecase.AuthenticateAPIKey(ctx, entities.APIKeyPrefix+"unknown")
+		assert.Equal(t, entities.CodeInvalidAPIKey, entities.ErrorCode(err))
+		_, err = usecase.AuthenticateAPIKey(ctx, revoked.Key)
+		assert.True(t, entities.IsUnauthorizedError(err))
+		assert.Equal(t, entities.CodeInvalidAPIKey, entities.ErrorCode(err))
+
+		usecase.now = func() time.Time { return expiresAt }
+		_, err = usecase.AuthenticateAPIKey(ctx, expiring.Key)
+		assert.Equal(t, entities.CodeInvalidAPIKey, entities.ErrorCode(err))
+	})
+
+	t.Run("should report revoking unknown keys", func(t *testing.T) {
+		usecase := NewAPIKeyUsecase(repository.NewUserMemoryRepository())
+
+		err := usecase.RevokeAPIKey(ctx, uuid.New())
+		assert.True(t, entities.IsNotFoundError(err))
+	})
+}
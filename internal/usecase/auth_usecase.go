@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type AuthUsecaseInterface interface {
+	// Login exchanges an email and password for an access and refresh token
+	Login(ctx context.Context, req dto.LoginRequest) (*dto.TokenResponse, error)
+	// Refresh exchanges a refresh token, which is spent, for a new pair
+	Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.TokenResponse, error)
+}
+
+// AccessTokenIssuer signs access tokens for authenticated users
+type AccessTokenIssuer interface {
+	IssueAccessToken(userID uuid.UUID) (string, time.Time, error)
+}
+
+// DefaultRefreshTokenTTL is how long a refresh token can be exchanged
+const DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+
+// dummyPasswordHash is compared against when no user matches a login, so
+// unknown emails take as long to reject as wrong passwords
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("not a real password"), bcrypt.DefaultCost)
+	return hash
+})
+
+type AuthUsecase struct {
+	userRepo   repository.UserRepositoryInterface
+	tokenRepo  repository.RefreshTokenRepositoryInterface
+	txManager  repository.TransactionManagerInterface
+	issuer     AccessTokenIssuer
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+// AuthUsecaseOption configures optional AuthUsecase behaviour
+type AuthUsecaseOption func(*AuthUsecase)
+
+// WithRefreshTokenTTL sets how long refresh tokens are valid
+func WithRefreshTokenTTL(ttl time.Duration) AuthUsecaseOption {
+	return func(u *AuthUsecase) {
+		if ttl > 0 {
+			u.refreshTTL = ttl
+		}
+	}
+}
+
+// WithAuthTransactionManager makes spending a refresh token and storing its
+// successor one unit of work
+func WithAuthTransactionManager(txManager repository.TransactionManagerInterface) AuthUsecaseOption {
+	return func(u *AuthUsecase) {
+		if txManager != nil {
+			u.txManager = txManager
+		}
+	}
+}
+
+func NewAuthUsecase(userRepo repository.UserRepositoryInterface, tokenRepo repository.RefreshTokenRepositoryInterface, issuer AccessTokenIssuer, opts ...AuthUsecaseOption) *AuthUsecase {
+	u := &AuthUsecase{
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		txManager:  noTransaction{},
+		issuer:     issuer,
+		refreshTTL: DefaultRefreshTokenTTL,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
+func (u *AuthUsecase) Login(ctx context.Context, req dto.LoginRequest) (*dto.TokenResponse, error) {
+	invalid := entities.NewUnauthorizedError("login failed", entities.ErrInvalidCredentials)
+
+	user, err := u.userRepo.GetByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil && !entities.IsNotFoundError(err) {
+		return nil, err
+	}
+	if user == nil || user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		return nil, invalid
+	}
+	if !user.CheckPassword(req.Password) {
+		return nil, invalid
+	}
+
+	refreshToken, refreshValue, err := entities.NewRefreshToken(user.ID, uuid.New(), u.refreshTTL)
+	if err != nil {
+		return nil, entities.NewInternalError("failed to generate refresh token", err)
+	}
+	if err := u.tokenRepo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, err
+	}
+
+	return u.newTokenResponse(user.ID, refreshToken, refreshValue)
+}
+
+// Refresh rotates the refresh token: the presented token is spent and a new
+// one from the same family is returned. Presenting a spent token again
+// revokes the whole family, since either the client or an attacker holds a
+// stolen copy.
+func (u *AuthUsecase) Refresh(ctx context.Context, req dto.RefreshTokenRequest) (*dto.TokenResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, entities.NewUnauthorizedError("refresh token is required", entities.ErrInvalidToken)
+	}
+
+	var (
+		next      *entities.RefreshToken
+		nextValue string
+		reused    *entities.RefreshToken
+	)
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := u.now()
+		current, err := u.tokenRepo.ConsumeRefreshToken(ctx, entities.HashRefreshToken(req.RefreshToken), now)
+		if errors.Is(err, entities.ErrRefreshTokenReused) {
+			reused = current
+		}
+		if err != nil {
+			return err
+		}
+		if current.Expired(now) {
+			return entities.NewUnauthorizedError("refresh token expired", entities.ErrInvalidToken)
+		}
+
+		// Deleted users keep their tokens until purged but cannot use them
+		if _, err := u.userRepo.GetByID(ctx, current.UserID); err != nil {
+			if entities.IsNotFoundError(err) {
+				return entities.NewUnauthorizedError("refresh token owner not found", entities.ErrInvalidToken)
+			}
+			return err
+		}
+
+		if next, nextValue, err = entities.NewRefreshToken(current.UserID, current.FamilyID, u.refreshTTL); err != nil {
+			return entities.NewInternalError("failed to generate refresh token", err)
+		}
+		return u.tokenRepo.CreateRefreshToken(ctx, next)
+	})
+	if reused != nil {
+		// Revoked outside the unit of work, which has been rolled back
+		if revokeErr := u.tokenRepo.RevokeRefreshTokenFamily(ctx, reused.FamilyID, u.now()); revokeErr != nil {
+			return nil, revokeErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return u.newTokenResponse(next.UserID, next, nextValue)
+}
+
+func (u *AuthUsecase) newTokenResponse(userID uuid.UUID, refreshToken *entities.RefreshToken, refreshValue string) (*dto.TokenResponse, error) {
+	accessToken, expiresAt, err := u.issuer.IssueAccessToken(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := u.now()
+	return &dto.TokenResponse{
+		AccessToken:      accessToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        int(expiresAt.Sub(now).Round(time.Second).Seconds()),
+		RefreshToken:     refreshValue,
+		RefreshExpiresIn: int(refreshToken.ExpiresAt.Sub(now).Round(time.Second).Seconds()),
+	}, nil
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeIssuer issues access tokens naming the user they were issued to
+type fakeIssuer struct{}
+
+func (fakeIssuer) IssueAccessToken(userID uuid.UUID) (string, time.Time, error) {
+	return "access-" + userID.String(), time.Now().Add(15 * time.Minute), nil
+}
+
+func TestUserUsecase_CreateUserWithPassword(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewUserMemoryRepository()
+	usecase := NewUserUsecase(repo)
+
+	t.Run("should store only a hash of the password", func(t *testing.T) {
+		created, err := usecase.CreateUser(ctx, dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+
+		stored, err := repo.GetByID(ctx, created.ID)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, stored.PasswordHash)
+		assert.NotContains(t, stored.PasswordHash, "correct horse")
+		assert.True(t, stored.CheckPassword("correct horse battery"))
+		assert.False(t, stored.CheckPassword("wrong horse battery"))
+	})
+
+	t.Run("should enforce the password policy", func(t *testing.T) {
+		for _, password := range []string{"short", "            ", "jane@example.com", string(make([]byte, 73))} {
+			_, err := usecase.CreateUser(ctx, dto.CreateUserRequest{Name: "Jane Doe", Email: "jane@example.com", Password: password})
+			assert.Equal(t, entities.CodeInvalidPassword, entities.ErrorCode(err), password)
+		}
+	})
+}
+
+func TestAuthUsecase(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*AuthUsecase, *repository.UserMemoryRepository, *entities.User) {
+		repo := repository.NewUserMemoryRepository()
+		user, err := entities.NewUser("John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, user.SetPassword("correct horse battery"))
+		assert.NoError(t, repo.Create(ctx, user))
+
+		authUsecase := NewAuthUsecase(repo, repo, fakeIssuer{},
+			WithRefreshTokenTTL(time.Hour),
+			WithAuthTransactionManager(repository.NewMemoryTransactionManager()),
+		)
+		return authUsecase, repo, user
+	}
+
+	t.Run("should issue tokens for valid credentials", func(t *testing.T) {
+		authUsecase, _, user := setup(t)
+
+		tokens, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+		assert.Equal(t, "access-"+user.ID.String(), tokens.AccessToken)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, 900, tokens.ExpiresIn)
+		assert.Equal(t, 3600, tokens.RefreshExpiresIn)
+		assert.NotEmpty(t, tokens.RefreshToken)
+	})
+
+	t.Run("should reject invalid credentials alike", func(t *testing.T) {
+		authUsecase, repo, _ := setup(t)
+		passwordless, err := entities.NewUser("Jane Doe", "jane@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, passwordless))
+
+		for _, req := range []dto.LoginRequest{
+			{Email: "john@example.com", Password: "wrong horse battery"},
+			{Email: "nobody@example.com", Password: "correct horse battery"},
+			{Email: "jane@example.com", Password: ""},
+		} {
+			tokens, err := authUsecase.Login(ctx, req)
+			assert.Nil(t, tokens)
+			assert.True(t, entities.IsUnauthorizedError(err))
+			assert.Equal(t, entities.CodeInvalidCredentials, entities.ErrorCode(err))
+		}
+	})
+
+	t.Run("should rotate refresh tokens", func(t *testing.T) {
+		authUsecase, _, user := setup(t)
+		login, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+
+		refreshed, err := authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+		assert.NoError(t, err)
+		assert.Equal(t, "access-"+user.ID.String(), refreshed.AccessToken)
+		assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should revoke the family when a spent token is reused", func(t *testing.T) {
+		authUsecase, _, _ := setup(t)
+		login, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+		refreshed, err := authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+		assert.NoError(t, err)
+
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+		assert.Equal(t, entities.CodeInvalidToken, entities.ErrorCode(err))
+
+		// The legitimate successor is revoked along with it
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: refreshed.RefreshToken})
+		assert.True(t, entities.IsUnauthorizedError(err))
+	})
+
+	t.Run("should reject expired and unknown tokens", func(t *testing.T) {
+		authUsecase, _, _ := setup(t)
+		login, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: "unknown"})
+		assert.Equal(t, entities.CodeInvalidToken, entities.ErrorCode(err))
+
+		authUsecase.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+		assert.Equal(t, entities.CodeInvalidToken, entities.ErrorCode(err))
+	})
+
+	t.Run("should reject tokens of deleted users", func(t *testing.T) {
+		authUsecase, repo, user := setup(t)
+		login, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Delete(ctx, user.ID, 0))
+
+		_, err = authUsecase.Refresh(ctx, dto.RefreshTokenRequest{RefreshToken: login.RefreshToken})
+		assert.Equal(t, entities.CodeInvalidToken, entities.ErrorCode(err))
+	})
+}
+
+func TestUserUsecase_ChangePassword(t *testing.T) {
+	ctx := context.Background()
+	admin := entities.ContextWithPrincipal(ctx, entities.Principal{Role: entities.RoleAdmin})
+	setup := func(t *testing.T) (*UserUsecase, *AuthUsecase, *repository.UserMemoryRepository) {
+		repo := repository.NewUserMemoryRepository()
+		return NewUserUsecase(repo), NewAuthUsecase(repo, repo, fakeIssuer{}), repo
+	}
+
+	t.Run("should let an imported user log in once given a password", func(t *testing.T) {
+		usecase, authUsecase, repo := setup(t)
+		report, err := usecase.ImportUsers(admin, dto.ImportUsersRequest{Rows: []*dto.ImportUserRow{{Line: 1, Name: "John Doe", Email: "john@example.com"}}})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		imported, err := repo.GetByEmail(ctx, "john@example.com")
+		assert.NoError(t, err)
+
+		_, err = authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.True(t, entities.IsUnauthorizedError(err))
+
+		changed, err := usecase.ChangePassword(admin, imported.ID, dto.ChangePasswordRequest{Password: "correct horse battery", ExpectedVersion: imported.Version})
+		assert.NoError(t, err)
+		assert.Equal(t, imported.Version+1, changed.Version)
+
+		tokens, err := authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.NoError(t, err)
+		assert.Equal(t, "access-"+imported.ID.String(), tokens.AccessToken)
+	})
+
+	t.Run("should make users confirm their current password", func(t *testing.T) {
+		usecase, authUsecase, repo := setup(t)
+		user, err := entities.NewUser("John Doe", "john@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, user.SetPassword("correct horse battery"))
+		assert.NoError(t, repo.Create(ctx, user))
+		self := entities.ContextWithUserID(ctx, user.ID)
+
+		_, err = usecase.ChangePassword(self, user.ID, dto.ChangePasswordRequest{Password: "staple battery horse", CurrentPassword: "wrong horse battery"})
+		assert.True(t, entities.IsForbiddenError(err))
+		assert.Equal(t, entities.CodeInvalidCredentials, entities.ErrorCode(err))
+
+		_, err = usecase.ChangePassword(self, user.ID, dto.ChangePasswordRequest{Password: "staple battery horse", CurrentPassword: "correct horse battery"})
+		assert.NoError(t, err)
+		_, err = authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "correct horse battery"})
+		assert.True(t, entities.IsUnauthorizedError(err))
+		_, err = authUsecase.Login(ctx, dto.LoginRequest{Email: "john@example.com", Password: "staple battery horse"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("should let only admins change other users' passwords", func(t *testing.T) {
+		usecase, _, repo := setup(t)
+		users := make(map[entities.Role]*entities.User)
+		for _, role := range []entities.Role{entities.RoleManager, entities.RoleMember} {
+			user, err := entities.NewUser("John Doe", string(role)+"@example.com")
+			assert.NoError(t, err)
+			user.Role = role
+			assert.NoError(t, repo.Create(ctx, user))
+			users[role] = user
+		}
+		target, err := entities.NewUser("Jane Doe", "jane@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, target))
+		req := dto.ChangePasswordRequest{Password: "correct horse battery"}
+
+		for _, caller := range []context.Context{
+			entities.ContextWithUserID(ctx, users[entities.RoleMember].ID),
+			entities.ContextWithUserID(ctx, users[entities.RoleManager].ID),
+			entities.ContextWithPrincipal(ctx, entities.APIKeyPrincipal([]string{entities.ScopeUsersWrite})),
+		} {
+			_, err := usecase.ChangePassword(caller, target.ID, req)
+			assert.True(t, entities.IsForbiddenError(err))
+			assert.Equal(t, entities.CodePermissionDenied, entities.ErrorCode(err))
+		}
+
+		_, err = usecase.ChangePassword(admin, target.ID, dto.ChangePasswordRequest{Password: "jane@example.com"})
+		assert.Equal(t, entities.CodeInvalidPassword, entities.ErrorCode(err))
+		_, err = usecase.ChangePassword(admin, target.ID, req)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// cursorToken is the JSON payload behind an opaque pagination cursor
+type cursorToken struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// encodeCursor returns the opaque cursor pointing just after user
+func encodeCursor(user *entities.User) string {
+	payload, _ := json.Marshal(cursorToken{CreatedAt: user.CreatedAt, ID: user.ID})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor
+func decodeCursor(cursor string) (*repository.Cursor, error) {
+	payload, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, entities.NewValidationError("malformed cursor", entities.ErrInvalidCursor)
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(payload, &token); err != nil || token.ID == uuid.Nil || token.CreatedAt.IsZero() {
+		return nil, entities.NewValidationError("malformed cursor", entities.ErrInvalidCursor)
+	}
+
+	return &repository.Cursor{CreatedAt: token.CreatedAt, ID: token.ID}, nil
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+)
+
+// sortFields whitelists the values accepted by the sort parameter
+var sortFields = map[string]repository.SortField{
+	"created_at": repository.SortByCreatedAt,
+	"name":       repository.SortByName,
+	"email":      repository.SortByEmail,
+}
+
+// parseUserFilter converts the filter fields of req into a repository filter
+func parseUserFilter(req dto.ListUsersRequest) (repository.UserFilter, error) {
+	filter := repository.UserFilter{
+		Email:        strings.TrimSpace(req.Email),
+		NamePrefix:   strings.TrimSpace(req.NamePrefix),
+		NameContains: strings.TrimSpace(req.Name),
+		EmailDomain:  strings.TrimPrefix(strings.TrimSpace(req.EmailDomain), "@"),
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseTimestamp("created_after", req.CreatedAfter); err != nil {
+		return repository.UserFilter{}, err
+	}
+	if filter.CreatedBefore, err = parseTimestamp("created_before", req.CreatedBefore); err != nil {
+		return repository.UserFilter{}, err
+	}
+	if filter.UpdatedSince, err = parseTimestamp("updated_since", req.UpdatedSince); err != nil {
+		return repository.UserFilter{}, err
+	}
+
+	if !filter.CreatedAfter.IsZero() && !filter.CreatedBefore.IsZero() && !filter.CreatedAfter.Before(filter.CreatedBefore) {
+		return repository.UserFilter{}, entities.NewValidationError("created_after must be before created_before", entities.ErrInvalidFilter)
+	}
+
+	return filter, nil
+}
+
+// parseTimestamp parses an optional RFC 3339 filter value
+func parseTimestamp(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, entities.NewValidationError(fmt.Sprintf("%s must be an RFC 3339 timestamp", name), entities.ErrInvalidFilter)
+	}
+	return t, nil
+}
+
+// parseUserSort converts a sort parameter such as "-created_at" into a repository sort
+func parseUserSort(value string) (repository.UserSort, error) {
+	if value == "" {
+		return repository.DefaultUserSort, nil
+	}
+
+	descending := strings.HasPrefix(value, "-")
+	field, ok := sortFields[strings.TrimPrefix(value, "-")]
+	if !ok {
+		return repository.UserSort{}, entities.NewValidationError(fmt.Sprintf("unsupported sort %q", value), entities.ErrInvalidSort)
+	}
+
+	return repository.UserSort{Field: field, Descending: descending}, nil
+}
+
+// pageBounds checks a requested page, defaulting an unset limit
+func (u *UserUsecase) pageBounds(limit, offset int) (int, int, error) {
+	switch {
+	case limit < 0:
+		return 0, 0, entities.NewValidationError("limit must not be negative", entities.ErrInvalidPagination)
+	case limit > u.maxLimit:
+		return 0, 0, entities.NewValidationError(fmt.Sprintf("limit must not exceed %d", u.maxLimit), entities.ErrInvalidPagination)
+	case offset < 0:
+		return 0, 0, entities.NewValidationError("offset must not be negative", entities.ErrInvalidPagination)
+	case limit == 0:
+		limit = DefaultListLimit
+	}
+	return limit, offset, nil
+}
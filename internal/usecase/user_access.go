@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// principal returns who ctx acts as: the principal attached to it, or the
+// authenticated user with their current role. It returns nil for requests
+// that are not authenticated at all, which only happens when authentication
+// is disabled.
+func (u *UserUsecase) principal(ctx context.Context) (*entities.Principal, error) {
+	if principal, ok := entities.PrincipalFromContext(ctx); ok {
+		return &principal, nil
+	}
+
+	userID, ok := entities.UserIDFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	user, err := u.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if entities.IsNotFoundError(err) {
+			return nil, entities.NewUnauthorizedError("authenticated user no longer exists", entities.ErrInvalidToken)
+		}
+		return nil, err
+	}
+	return &entities.Principal{UserID: user.ID, Role: user.Role}, nil
+}
+
+// authorize fails with a forbidden error unless ctx may perform permission
+// on the user target, or on users in general when target is uuid.Nil
+func (u *UserUsecase) authorize(ctx context.Context, permission entities.Permission, target uuid.UUID) error {
+	principal, err := u.principal(ctx)
+	if err != nil {
+		return err
+	}
+	if principal == nil || principal.Can(permission, target) {
+		return nil
+	}
+	return entities.NewForbiddenError("not allowed to "+string(permission), entities.ErrPermissionDenied)
+}
+
+// ChangeUserRole gives the user a new role; only admins may do so
+func (u *UserUsecase) ChangeUserRole(ctx context.Context, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.UserResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionChangeRole, id); err != nil {
+		return nil, err
+	}
+	role, err := entities.ParseRole(req.Role)
+	if err != nil {
+		return nil, entities.NewValidationError("invalid role", err)
+	}
+
+	var user *entities.User
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(user, req.ExpectedVersion); err != nil {
+			return err
+		}
+		if user.Role == role {
+			return nil
+		}
+
+		user.ChangeRole(role)
+		return u.userRepo.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserResponse(user), nil
+}
+
+// ChangePassword sets the user's password; only admins and the user
+// themselves may do so. Users replacing their own password must confirm the
+// current one.
+func (u *UserUsecase) ChangePassword(ctx context.Context, id uuid.UUID, req dto.ChangePasswordRequest) (*dto.UserResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionChangePassword, id); err != nil {
+		return nil, err
+	}
+	userID, ok := entities.UserIDFromContext(ctx)
+	self := ok && userID == id
+
+	var user *entities.User
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(user, req.ExpectedVersion); err != nil {
+			return err
+		}
+		if self && user.PasswordHash != "" && !user.CheckPassword(req.CurrentPassword) {
+			return entities.NewForbiddenError("current password does not match", entities.ErrInvalidCredentials)
+		}
+
+		if err := user.ChangePassword(req.Password); err != nil {
+			if errors.Is(err, entities.ErrInvalidPassword) {
+				return entities.NewValidationError("invalid password", err)
+			}
+			return entities.NewInternalError("failed to hash password", err)
+		}
+		return u.userRepo.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserResponse(user), nil
+}
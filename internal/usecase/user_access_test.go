@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserUsecase_Authorization(t *testing.T) {
+	ctx := context.Background()
+	setup := func(t *testing.T) (*UserUsecase, map[entities.Role]*entities.User) {
+		repo := repository.NewUserMemoryRepository()
+		users := make(map[entities.Role]*entities.User)
+		for _, role := range []entities.Role{entities.RoleAdmin, entities.RoleManager, entities.RoleMember} {
+			user, err := entities.NewUser("John Doe", string(role)+"@example.com")
+			assert.NoError(t, err)
+			user.Role = role
+			assert.NoError(t, repo.Create(ctx, user))
+			users[role] = user
+		}
+		return NewUserUsecase(repo), users
+	}
+	as := func(user *entities.User) context.Context {
+		return entities.ContextWithUserID(ctx, user.ID)
+	}
+
+	t.Run("should let members read and update only themselves", func(t *testing.T) {
+		usecase, users := setup(t)
+		member, manager := users[entities.RoleMember], users[entities.RoleManager]
+
+		_, err := usecase.GetUser(as(member), member.ID)
+		assert.NoError(t, err)
+		_, err = usecase.UpdateUser(as(member), member.ID, dto.UpdateUserRequest{Name: "John Smith"})
+		assert.NoError(t, err)
+
+		_, err = usecase.GetUser(as(member), manager.ID)
+		assert.True(t, entities.IsForbiddenError(err))
+		assert.ErrorIs(t, err, entities.ErrPermissionDenied)
+		_, err = usecase.UpdateUser(as(member), manager.ID, dto.UpdateUserRequest{Name: "John Smith"})
+		assert.True(t, entities.IsForbiddenError(err))
+		_, err = usecase.ListUsers(as(member), dto.ListUsersRequest{})
+		assert.True(t, entities.IsForbiddenError(err))
+		assert.True(t, entities.IsForbiddenError(usecase.DeleteUser(as(member), member.ID, 0)))
+	})
+
+	t.Run("should let managers read and update but not delete", func(t *testing.T) {
+		usecase, users := setup(t)
+		manager, member := users[entities.RoleManager], users[entities.RoleMember]
+
+		_, err := usecase.UpdateUser(as(manager), member.ID, dto.UpdateUserRequest{Name: "John Smith"})
+		assert.NoError(t, err)
+		list, err := usecase.ListUsers(as(manager), dto.ListUsersRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, list.Total)
+
+		assert.True(t, entities.IsForbiddenError(usecase.DeleteUser(as(manager), member.ID, 0)))
+		_, err = usecase.ChangeUserRole(as(manager), manager.ID, dto.ChangeUserRoleRequest{Role: "admin"})
+		assert.True(t, entities.IsForbiddenError(err))
+	})
+
+	t.Run("should let admins delete users and change roles", func(t *testing.T) {
+		usecase, users := setup(t)
+		admin, manager, member := users[entities.RoleAdmin], users[entities.RoleManager], users[entities.RoleMember]
+
+		promoted, err := usecase.ChangeUserRole(as(admin), member.ID, dto.ChangeUserRoleRequest{Role: "manager"})
+		assert.NoError(t, err)
+		assert.Equal(t, "manager", promoted.Role)
+		assert.Equal(t, member.Version+1, promoted.Version)
+
+		// The new role applies to the user's next request
+		_, err = usecase.GetUser(as(member), manager.ID)
+		assert.NoError(t, err)
+
+		assert.NoError(t, usecase.DeleteUser(as(admin), manager.ID, 0))
+	})
+
+	t.Run("should reject unknown roles", func(t *testing.T) {
+		usecase, users := setup(t)
+
+		_, err := usecase.ChangeUserRole(as(users[entities.RoleAdmin]), users[entities.RoleMember].ID, dto.ChangeUserRoleRequest{Role: "owner"})
+		assert.True(t, entities.IsValidationError(err))
+		assert.ErrorIs(t, err, entities.ErrInvalidRole)
+	})
+
+	t.Run("should reject users that no longer exist", func(t *testing.T) {
+		usecase, users := setup(t)
+
+		_, err := usecase.GetUser(entities.ContextWithUserID(ctx, uuid.New()), users[entities.RoleMember].ID)
+		assert.True(t, entities.IsUnauthorizedError(err))
+	})
+
+	t.Run("should act as the principal in the context", func(t *testing.T) {
+		usecase, users := setup(t)
+		apiKey := entities.ContextWithPrincipal(ctx, entities.Principal{Role: entities.RoleManager})
+
+		_, err := usecase.GetUser(apiKey, users[entities.RoleMember].ID)
+		assert.NoError(t, err)
+		assert.True(t, entities.IsForbiddenError(usecase.DeleteUser(apiKey, users[entities.RoleMember].ID, 0)))
+	})
+
+	t.Run("should let API keys do what their scopes grant", func(t *testing.T) {
+		usecase, users := setup(t)
+		member := users[entities.RoleMember]
+		writer := entities.ContextWithPrincipal(ctx, entities.APIKeyPrincipal([]string{entities.ScopeUsersWrite}))
+		deleter := entities.ContextWithPrincipal(ctx, entities.APIKeyPrincipal([]string{entities.ScopeUsersWrite, entities.ScopeUsersDelete}))
+
+		_, err := usecase.UpdateUser(writer, member.ID, dto.UpdateUserRequest{Name: "John Smith"})
+		assert.NoError(t, err)
+		_, err = usecase.GetUser(writer, member.ID)
+		assert.True(t, entities.IsForbiddenError(err))
+		assert.True(t, entities.IsForbiddenError(usecase.DeleteUser(writer, member.ID, 0)))
+		_, err = usecase.ChangeUserRole(deleter, member.ID, dto.ChangeUserRoleRequest{Role: "admin"})
+		assert.True(t, entities.IsForbiddenError(err))
+
+		assert.NoError(t, usecase.DeleteUser(deleter, member.ID, 0))
+		_, err = usecase.RestoreUser(deleter, member.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should not restrict unauthenticated requests", func(t *testing.T) {
+		usecase, users := setup(t)
+
+		assert.NoError(t, usecase.DeleteUser(ctx, users[entities.RoleMember].ID, 0))
+	})
+}
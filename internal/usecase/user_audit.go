@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func (u *UserUsecase) ListAudit(ctx context.Context, req dto.ListAuditRequest) (*dto.ListAuditResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionReadAudit, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	limit, offset, err := u.pageBounds(req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseAuditFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	records, total, err := u.userRepo.ListAudit(ctx, repository.AuditListParams{Limit: limit, Offset: offset, Filter: filter})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*dto.AuditRecordResponse, len(records))
+	for i, record := range records {
+		responses[i] = &dto.AuditRecordResponse{
+			ID:        record.ID,
+			UserID:    record.UserID,
+			Action:    string(record.Action),
+			Actor:     record.Actor,
+			RequestID: record.RequestID,
+			Before:    record.Before,
+			After:     record.After,
+			CreatedAt: record.CreatedAt,
+		}
+	}
+
+	return &dto.ListAuditResponse{
+		Records: responses,
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
+// parseAuditFilter converts the filter fields of req into a repository filter
+func parseAuditFilter(req dto.ListAuditRequest) (repository.AuditFilter, error) {
+	filter := repository.AuditFilter{
+		UserID:    req.UserID,
+		Actor:     strings.TrimSpace(req.Actor),
+		Action:    entities.AuditAction(strings.TrimSpace(req.Action)),
+		RequestID: strings.TrimSpace(req.RequestID),
+	}
+	if filter.Action != "" && !filter.Action.IsValid() {
+		return repository.AuditFilter{}, entities.NewValidationError(fmt.Sprintf("unsupported action %q", req.Action), entities.ErrInvalidFilter)
+	}
+
+	var err error
+	if filter.From, err = parseTimestamp("from", req.From); err != nil {
+		return repository.AuditFilter{}, err
+	}
+	if filter.To, err = parseTimestamp("to", req.To); err != nil {
+		return repository.AuditFilter{}, err
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return repository.AuditFilter{}, entities.NewValidationError("from must be before to", entities.ErrInvalidFilter)
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// ExportUsers passes every user matching req to emit, in the requested order,
+// without loading them all at once. It stops at the first error returned by
+// emit or the repository, including the cancellation of ctx.
+func (u *UserUsecase) ExportUsers(ctx context.Context, req dto.ExportUsersRequest, emit func(*dto.ExportedUser) error) error {
+	if err := u.authorize(ctx, entities.PermissionListUsers, uuid.Nil); err != nil {
+		return err
+	}
+
+	filter, err := parseUserFilter(dto.ListUsersRequest{
+		Email:         req.Email,
+		Name:          req.Name,
+		NamePrefix:    req.NamePrefix,
+		EmailDomain:   req.EmailDomain,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		UpdatedSince:  req.UpdatedSince,
+	})
+	if err != nil {
+		return err
+	}
+	userSort, err := parseUserSort(req.Sort)
+	if err != nil {
+		return err
+	}
+
+	users := u.userRepo.Export(ctx, filter, userSort)
+	for users.Next() {
+		if err := emit(newExportedUser(users.User())); err != nil {
+			return err
+		}
+	}
+	return users.Err()
+}
+
+func newExportedUser(user *entities.User) *dto.ExportedUser {
+	return &dto.ExportedUser{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Version:   user.Version,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
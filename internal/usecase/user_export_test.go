@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserUsecase_ExportUsers(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewUserMemoryRepository()
+	for _, input := range [][2]string{
+		{"Charlie", "charlie@example.com"},
+		{"Alice", "alice@example.com"},
+		{"Bob", "bob@example.org"},
+	} {
+		user, err := entities.NewUser(input[0], input[1])
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, user))
+	}
+	usecase := NewUserUsecase(repo)
+
+	collect := func(req dto.ExportUsersRequest) ([]string, error) {
+		var names []string
+		err := usecase.ExportUsers(ctx, req, func(user *dto.ExportedUser) error {
+			names = append(names, user.Name)
+			return nil
+		})
+		return names, err
+	}
+
+	t.Run("should emit filtered users in the requested order", func(t *testing.T) {
+		names, err := collect(dto.ExportUsersRequest{EmailDomain: "@example.com", Sort: "name"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"Alice", "Charlie"}, names)
+	})
+
+	t.Run("should reject an invalid sort before emitting", func(t *testing.T) {
+		names, err := collect(dto.ExportUsersRequest{Sort: "password"})
+		assert.Empty(t, names)
+		assert.Equal(t, entities.CodeInvalidSort, entities.ErrorCode(err))
+	})
+
+	t.Run("should reject an invalid filter before emitting", func(t *testing.T) {
+		names, err := collect(dto.ExportUsersRequest{CreatedAfter: "yesterday"})
+		assert.Empty(t, names)
+		assert.Equal(t, entities.CodeInvalidFilter, entities.ErrorCode(err))
+	})
+
+	t.Run("should stop at the first emit error", func(t *testing.T) {
+		failure := errors.New("client gone")
+		calls := 0
+		err := usecase.ExportUsers(ctx, dto.ExportUsersRequest{}, func(*dto.ExportedUser) error {
+			calls++
+			return failure
+		})
+		assert.Equal(t, failure, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should stop when the context is cancelled", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		err := usecase.ExportUsers(cancelled, dto.ExportUsersRequest{}, func(*dto.ExportedUser) error {
+			t.Fatal("no user should be emitted")
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
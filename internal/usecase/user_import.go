@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+
+	"github.com/google/uuid"
+)
+
+// Import modes
+const (
+	ImportAllOrNothing = "all_or_nothing"
+	ImportBestEffort   = "best_effort"
+)
+
+// Import row statuses
+const (
+	ImportStatusCreated  = "created"
+	ImportStatusInvalid  = "invalid"
+	ImportStatusConflict = "conflict"
+	ImportStatusSkipped  = "skipped"
+)
+
+// DefaultMaxImportRows bounds the rows accepted by a single ImportUsers call
+const DefaultMaxImportRows = 50000
+
+// ImportBatchSize is the number of users inserted per statement
+const ImportBatchSize = 500
+
+// errImportRejected rolls back an all-or-nothing import that hit a conflict
+var errImportRejected = errors.New("import rejected")
+
+// ImportUsers validates every row and creates the valid users in batches.
+// An all-or-nothing import creates nothing unless every row can be created;
+// a best-effort import creates every valid, non-conflicting row, committing
+// batch by batch.
+func (u *UserUsecase) ImportUsers(ctx context.Context, req dto.ImportUsersRequest) (*dto.ImportUsersResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionImportUsers, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = ImportAllOrNothing
+	}
+	if mode != ImportAllOrNothing && mode != ImportBestEffort {
+		return nil, entities.NewValidationError(fmt.Sprintf("mode must be %s or %s", ImportAllOrNothing, ImportBestEffort), entities.ErrInvalidImport)
+	}
+	if len(req.Rows) == 0 {
+		return nil, entities.NewValidationError("import contains no rows", entities.ErrInvalidImport)
+	}
+	if len(req.Rows) > u.maxImportRows {
+		return nil, entities.NewValidationError(fmt.Sprintf("import must not exceed %d rows", u.maxImportRows), entities.ErrInvalidImport)
+	}
+
+	results := make([]*dto.ImportRowResult, len(req.Rows))
+	var (
+		users   []*entities.User
+		pending []*dto.ImportRowResult // results of users, index for index
+	)
+	seen := make(map[string]bool, len(req.Rows))
+	for i, row := range req.Rows {
+		result := &dto.ImportRowResult{Line: row.Line, Email: row.Email}
+		results[i] = result
+
+		if row.Error != "" {
+			setImportFailure(result, ImportStatusInvalid, entities.CodeInvalidImport, row.Error)
+			continue
+		}
+		user, err := entities.NewUser(row.Name, row.Email)
+		if err != nil {
+			setImportFailure(result, ImportStatusInvalid, entities.ErrorCode(err), err.Error())
+			continue
+		}
+		if seen[user.Email] {
+			setImportFailure(result, ImportStatusConflict, entities.CodeEmailAlreadyUsed, "email appears earlier in the import")
+			continue
+		}
+		seen[user.Email] = true
+
+		users = append(users, user)
+		pending = append(pending, result)
+	}
+
+	// created stays nil when nothing was attempted
+	var created map[uuid.UUID]bool
+	committed := true
+	switch {
+	case mode == ImportBestEffort:
+		var err error
+		if created, err = u.createBatches(ctx, users); err != nil {
+			return nil, err
+		}
+	case len(users) < len(req.Rows):
+		committed = false
+	default:
+		err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			if created, err = u.createBatches(ctx, users); err != nil {
+				return err
+			}
+			if len(created) < len(users) {
+				return errImportRejected
+			}
+			return nil
+		})
+		if errors.Is(err, errImportRejected) {
+			committed = false
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	for i, user := range users {
+		result := pending[i]
+		switch {
+		case created == nil:
+			result.Status = ImportStatusSkipped
+		case !created[user.ID]:
+			setImportFailure(result, ImportStatusConflict, entities.CodeEmailAlreadyUsed, "email already in use")
+		case committed:
+			id := user.ID
+			result.Status = ImportStatusCreated
+			result.ID = &id
+		default:
+			result.Status = ImportStatusSkipped
+		}
+	}
+
+	return newImportResponse(mode, committed, results), nil
+}
+
+// createBatches creates users ImportBatchSize at a time and returns the IDs
+// of those created
+func (u *UserUsecase) createBatches(ctx context.Context, users []*entities.User) (map[uuid.UUID]bool, error) {
+	created := make(map[uuid.UUID]bool, len(users))
+	for start := 0; start < len(users); start += ImportBatchSize {
+		end := min(start+ImportBatchSize, len(users))
+		ids, err := u.userRepo.CreateBatch(ctx, users[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			created[id] = true
+		}
+	}
+	return created, nil
+}
+
+func setImportFailure(result *dto.ImportRowResult, status, code, detail string) {
+	result.Status = status
+	result.Code = code
+	result.Error = detail
+}
+
+func newImportResponse(mode string, committed bool, results []*dto.ImportRowResult) *dto.ImportUsersResponse {
+	resp := &dto.ImportUsersResponse{
+		Mode:      mode,
+		Committed: committed,
+		Total:     len(results),
+		Rows:      results,
+	}
+	for _, result := range results {
+		switch result.Status {
+		case ImportStatusCreated:
+			resp.Created++
+		case ImportStatusInvalid:
+			resp.Invalid++
+		case ImportStatusConflict:
+			resp.Conflicts++
+		case ImportStatusSkipped:
+			resp.Skipped++
+		}
+	}
+	return resp
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUserUsecase_ImportUsers(t *testing.T) {
+	ctx := context.Background()
+
+	newImport := func(mode string, rows ...*dto.ImportUserRow) dto.ImportUsersRequest {
+		return dto.ImportUsersRequest{Mode: mode, Rows: rows}
+	}
+	existing := func(t *testing.T, repo *repository.UserMemoryRepository) {
+		user, err := entities.NewUser("Taken", "taken@example.com")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Create(ctx, user))
+	}
+	statuses := func(resp *dto.ImportUsersResponse) []string {
+		var result []string
+		for _, row := range resp.Rows {
+			result = append(result, row.Status)
+		}
+		return result
+	}
+	rows := []*dto.ImportUserRow{
+		{Line: 2, Name: "John Doe", Email: "john@example.com"},
+		{Line: 3, Name: "", Email: "nameless@example.com"},
+		{Line: 4, Error: "expected 2 fields, got 1"},
+		{Line: 5, Name: "John Again", Email: "john@example.com"},
+		{Line: 6, Name: "Someone", Email: "taken@example.com"},
+		{Line: 7, Name: "Jane Doe", Email: "jane@example.com"},
+	}
+
+	t.Run("should create every valid row in best-effort mode", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		existing(t, repo)
+		usecase := NewUserUsecase(repo)
+
+		resp, err := usecase.ImportUsers(ctx, newImport(ImportBestEffort, rows...))
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Committed)
+		assert.Equal(t, []string{"created", "invalid", "invalid", "conflict", "conflict", "created"}, statuses(resp))
+		assert.Equal(t, 6, resp.Total)
+		assert.Equal(t, 2, resp.Created)
+		assert.Equal(t, 2, resp.Invalid)
+		assert.Equal(t, 2, resp.Conflicts)
+		assert.Equal(t, entities.CodeInvalidName, resp.Rows[1].Code)
+		assert.Equal(t, entities.CodeInvalidImport, resp.Rows[2].Code)
+		assert.Equal(t, entities.CodeEmailAlreadyUsed, resp.Rows[4].Code)
+
+		stored, err := repo.GetByEmail(ctx, "jane@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, *resp.Rows[5].ID, stored.ID)
+	})
+
+	t.Run("should create nothing in all-or-nothing mode when a row is invalid", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		usecase := NewUserUsecase(repo, WithTransactionManager(repository.NewMemoryTransactionManager()))
+
+		resp, err := usecase.ImportUsers(ctx, newImport("", rows[0], rows[1]))
+
+		assert.NoError(t, err)
+		assert.Equal(t, ImportAllOrNothing, resp.Mode)
+		assert.False(t, resp.Committed)
+		assert.Equal(t, []string{"skipped", "invalid"}, statuses(resp))
+		_, total, _ := repo.List(ctx, repository.ListParams{Limit: 10})
+		assert.Equal(t, 0, total)
+	})
+
+	t.Run("should roll back an all-or-nothing import that hits a conflict", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		existing(t, repo)
+		usecase := NewUserUsecase(repo, WithTransactionManager(repository.NewMemoryTransactionManager()))
+
+		resp, err := usecase.ImportUsers(ctx, newImport(ImportAllOrNothing, rows[0], rows[4], rows[5]))
+
+		assert.NoError(t, err)
+		assert.False(t, resp.Committed)
+		assert.Equal(t, []string{"skipped", "conflict", "skipped"}, statuses(resp))
+		assert.Nil(t, resp.Rows[0].ID)
+		_, total, _ := repo.List(ctx, repository.ListParams{Limit: 10})
+		assert.Equal(t, 1, total)
+		_, auditTotal, _ := repo.ListAudit(ctx, repository.AuditListParams{Limit: 10})
+		assert.Equal(t, 1, auditTotal)
+	})
+
+	t.Run("should commit an all-or-nothing import without failures", func(t *testing.T) {
+		repo := repository.NewUserMemoryRepository()
+		usecase := NewUserUsecase(repo, WithTransactionManager(repository.NewMemoryTransactionManager()))
+
+		resp, err := usecase.ImportUsers(ctx, newImport(ImportAllOrNothing, rows[0], rows[5]))
+
+		assert.NoError(t, err)
+		assert.True(t, resp.Committed)
+		assert.Equal(t, 2, resp.Created)
+	})
+
+	t.Run("should insert in batches", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		var sizes []int
+		mockRepo.On("CreateBatch", ctx, mock.Anything).
+			Run(func(args mock.Arguments) { sizes = append(sizes, len(args.Get(1).([]*entities.User))) }).
+			Return([]uuid.UUID{}, nil)
+
+		var many []*dto.ImportUserRow
+		for i := 0; i < ImportBatchSize+1; i++ {
+			many = append(many, &dto.ImportUserRow{Line: i + 2, Name: "User", Email: fmt.Sprintf("user%d@example.com", i)})
+		}
+		resp, err := usecase.ImportUsers(ctx, newImport(ImportBestEffort, many...))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{ImportBatchSize, 1}, sizes)
+		assert.Equal(t, ImportBatchSize+1, resp.Conflicts)
+	})
+
+	t.Run("should validate mode and size", func(t *testing.T) {
+		usecase := NewUserUsecase(new(MockUserRepository), WithMaxImportRows(1))
+
+		_, err := usecase.ImportUsers(ctx, newImport("sometimes", rows[0]))
+		assert.True(t, entities.IsValidationError(err))
+		assert.Equal(t, entities.CodeInvalidImport, entities.ErrorCode(err))
+
+		_, err = usecase.ImportUsers(ctx, newImport(ImportBestEffort))
+		assert.True(t, entities.IsValidationError(err))
+
+		_, err = usecase.ImportUsers(ctx, newImport(ImportBestEffort, rows[0], rows[5]))
+		assert.True(t, entities.IsValidationError(err))
+	})
+
+	t.Run("should return repository errors", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("CreateBatch", ctx, mock.Anything).Return(nil, entities.NewInternalError("failed to create users", nil))
+
+		_, err := usecase.ImportUsers(ctx, newImport(ImportAllOrNothing, rows[0]))
+		assert.True(t, entities.IsInternalError(err))
+	})
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+)
+
+// userDocument is the JSON view of a user that patches are applied to
+type userDocument map[string]interface{}
+
+// userFields lists the members of userDocument and whether a patch may change them
+var userFields = map[string]bool{
+	"id":         false,
+	"name":       true,
+	"email":      true,
+	"role":       false,
+	"version":    false,
+	"created_at": false,
+	"updated_at": false,
+}
+
+func newUserDocument(user *entities.User) userDocument {
+	return userDocument{
+		"id":         user.ID.String(),
+		"name":       user.Name,
+		"email":      user.Email,
+		"role":       string(user.Role),
+		"version":    float64(user.Version),
+		"created_at": user.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at": user.UpdatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// applyUserPatch applies req to doc in place
+func applyUserPatch(doc userDocument, req dto.PatchUserRequest) error {
+	switch {
+	case req.MergePatch != nil:
+		return applyMergePatch(doc, req.MergePatch)
+	case req.Operations != nil:
+		return applyJSONPatch(doc, req.Operations)
+	default:
+		return entities.NewValidationError("patch document is required", entities.ErrInvalidPatch)
+	}
+}
+
+// applyMergePatch applies an RFC 7396 merge patch; a null member removes the field
+func applyMergePatch(doc userDocument, patch map[string]json.RawMessage) error {
+	keys := make([]string, 0, len(patch))
+	for key := range patch {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := checkMutable(key, "/"+key); err != nil {
+			return err
+		}
+
+		var value interface{}
+		if err := json.Unmarshal(patch[key], &value); err != nil {
+			return entities.NewValidationError(fmt.Sprintf("invalid value for %q", key), entities.ErrInvalidPatch)
+		}
+		if value == nil {
+			delete(doc, key)
+			continue
+		}
+		doc[key] = value
+	}
+	return nil
+}
+
+// applyJSONPatch applies RFC 6902 operations in order. Operations only
+// affect doc, so a failing operation leaves nothing persisted.
+func applyJSONPatch(doc userDocument, operations []dto.PatchOperation) error {
+	for i, op := range operations {
+		if err := applyOperation(doc, op); err != nil {
+			var domainErr *entities.DomainError
+			if errors.As(err, &domainErr) {
+				domainErr.Message = fmt.Sprintf("operation %d (%s %s): %s", i, op.Op, op.Path, domainErr.Message)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func applyOperation(doc userDocument, op dto.PatchOperation) error {
+	path, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		if err := checkMutable(path, op.Path); err != nil {
+			return err
+		}
+		if _, exists := doc[path]; op.Op == "replace" && !exists {
+			return entities.NewValidationError("path does not exist", entities.ErrInvalidPatch)
+		}
+		value, err := operationValue(op)
+		if err != nil {
+			return err
+		}
+		doc[path] = value
+
+	case "remove":
+		if err := checkMutable(path, op.Path); err != nil {
+			return err
+		}
+		if _, exists := doc[path]; !exists {
+			return entities.NewValidationError("path does not exist", entities.ErrInvalidPatch)
+		}
+		delete(doc, path)
+
+	case "move", "copy":
+		from, err := parsePointer(op.From)
+		if err != nil {
+			return err
+		}
+		if err := checkMutable(path, op.Path); err != nil {
+			return err
+		}
+		value, exists := doc[from]
+		if !exists {
+			return entities.NewValidationError(fmt.Sprintf("from path %q does not exist", op.From), entities.ErrInvalidPatch)
+		}
+		if op.Op == "move" && from != path {
+			if err := checkMutable(from, op.From); err != nil {
+				return err
+			}
+			delete(doc, from)
+		}
+		doc[path] = value
+
+	case "test":
+		value, err := operationValue(op)
+		if err != nil {
+			return err
+		}
+		if current, exists := doc[path]; !exists || !reflect.DeepEqual(current, value) {
+			return entities.NewConflictError("value does not match", entities.ErrPatchTestFailed)
+		}
+
+	default:
+		return entities.NewValidationError(fmt.Sprintf("unsupported op %q", op.Op), entities.ErrInvalidPatch)
+	}
+	return nil
+}
+
+// parsePointer resolves a JSON Pointer to a top-level user field
+func parsePointer(pointer string) (string, error) {
+	if !strings.HasPrefix(pointer, "/") {
+		return "", entities.NewValidationError(fmt.Sprintf("path %q must be a JSON Pointer to a user field", pointer), entities.ErrInvalidPatch)
+	}
+
+	field := strings.NewReplacer("~1", "/", "~0", "~").Replace(pointer[1:])
+	if _, known := userFields[field]; !known {
+		return "", entities.NewValidationError(fmt.Sprintf("unknown path %q", pointer), entities.ErrInvalidPatch)
+	}
+	return field, nil
+}
+
+// checkMutable rejects changes to unknown or read-only fields
+func checkMutable(field, pointer string) error {
+	mutable, known := userFields[field]
+	if !known {
+		return entities.NewValidationError(fmt.Sprintf("unknown path %q", pointer), entities.ErrInvalidPatch)
+	}
+	if !mutable {
+		return entities.NewValidationError(fmt.Sprintf("path %q is immutable", pointer), entities.ErrImmutableField)
+	}
+	return nil
+}
+
+// operationValue decodes the required value member of add, replace and test
+func operationValue(op dto.PatchOperation) (interface{}, error) {
+	if op.Value == nil {
+		return nil, entities.NewValidationError("value is required", entities.ErrInvalidPatch)
+	}
+	var value interface{}
+	if err := json.Unmarshal(op.Value, &value); err != nil {
+		return nil, entities.NewValidationError("value is not valid JSON", entities.ErrInvalidPatch)
+	}
+	return value, nil
+}
+
+// stringField reads a patched name or email; a removed field reads as empty
+// so that the entity invariants reject it
+func (d userDocument) stringField(field string) (string, error) {
+	value, exists := d[field]
+	if !exists || value == nil {
+		return "", nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", entities.NewValidationError(fmt.Sprintf("%s must be a string", field), entities.ErrInvalidPatch)
+	}
+	return s, nil
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the usecase spans
+const tracerName = "go-clean-code/internal/usecase"
+
+// tracedUserUsecase wraps every UserUsecaseInterface operation in a span
+type tracedUserUsecase struct {
+	next UserUsecaseInterface
+}
+
+// NewTracedUserUsecase returns next with every operation traced in a span
+// named after it, carrying the ID of the user it acts on and the
+// entities.ErrorType of the error it fails with. Spans come from the global
+// tracer provider.
+func NewTracedUserUsecase(next UserUsecaseInterface) UserUsecaseInterface {
+	return &tracedUserUsecase{next: next}
+}
+
+func startSpan(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "UserUsecase."+operation, trace.WithAttributes(attrs...))
+}
+
+// endSpan records err, if any, and ends span
+func endSpan(span trace.Span, err error) {
+	tracing.RecordError(span, err)
+	span.End()
+}
+
+func (t *tracedUserUsecase) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "CreateUser")
+	user, err := t.next.CreateUser(ctx, req)
+	if user != nil {
+		span.SetAttributes(tracing.UserID(user.ID.String()))
+	}
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) GetUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "GetUser", tracing.UserID(id.String()))
+	user, err := t.next.GetUser(ctx, id)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) UpdateUser(ctx context.Context, id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "UpdateUser", tracing.UserID(id.String()))
+	user, err := t.next.UpdateUser(ctx, id, req)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) PatchUser(ctx context.Context, id uuid.UUID, req dto.PatchUserRequest) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "PatchUser", tracing.UserID(id.String()))
+	user, err := t.next.PatchUser(ctx, id, req)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	ctx, span := startSpan(ctx, "DeleteUser", tracing.UserID(id.String()))
+	err := t.next.DeleteUser(ctx, id, expectedVersion)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUsecase) RestoreUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "RestoreUser", tracing.UserID(id.String()))
+	user, err := t.next.RestoreUser(ctx, id)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	ctx, span := startSpan(ctx, "PurgeUser", tracing.UserID(id.String()))
+	err := t.next.PurgeUser(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+	ctx, span := startSpan(ctx, "ListUsers")
+	users, err := t.next.ListUsers(ctx, req)
+	endSpan(span, err)
+	return users, err
+}
+
+func (t *tracedUserUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.SearchUsersResponse, error) {
+	ctx, span := startSpan(ctx, "SearchUsers")
+	users, err := t.next.SearchUsers(ctx, req)
+	endSpan(span, err)
+	return users, err
+}
+
+func (t *tracedUserUsecase) ExportUsers(ctx context.Context, req dto.ExportUsersRequest, emit func(*dto.ExportedUser) error) error {
+	ctx, span := startSpan(ctx, "ExportUsers")
+	err := t.next.ExportUsers(ctx, req, emit)
+	endSpan(span, err)
+	return err
+}
+
+func (t *tracedUserUsecase) ImportUsers(ctx context.Context, req dto.ImportUsersRequest) (*dto.ImportUsersResponse, error) {
+	ctx, span := startSpan(ctx, "ImportUsers")
+	report, err := t.next.ImportUsers(ctx, req)
+	endSpan(span, err)
+	return report, err
+}
+
+func (t *tracedUserUsecase) ListAudit(ctx context.Context, req dto.ListAuditRequest) (*dto.ListAuditResponse, error) {
+	ctx, span := startSpan(ctx, "ListAudit")
+	if req.UserID != uuid.Nil {
+		span.SetAttributes(tracing.UserID(req.UserID.String()))
+	}
+	records, err := t.next.ListAudit(ctx, req)
+	endSpan(span, err)
+	return records, err
+}
+
+func (t *tracedUserUsecase) ChangeUserRole(ctx context.Context, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "ChangeUserRole", tracing.UserID(id.String()))
+	user, err := t.next.ChangeUserRole(ctx, id, req)
+	endSpan(span, err)
+	return user, err
+}
+
+func (t *tracedUserUsecase) ChangePassword(ctx context.Context, id uuid.UUID, req dto.ChangePasswordRequest) (*dto.UserResponse, error) {
+	ctx, span := startSpan(ctx, "ChangePassword", tracing.UserID(id.String()))
+	user, err := t.next.ChangePassword(ctx, id, req)
+	endSpan(span, err)
+	return user, err
+}
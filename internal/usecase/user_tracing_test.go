@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"go-clean-code/internal/dto"
+	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
+	"go-clean-code/internal/tracing/tracingtest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+func TestTracedUserUsecase(t *testing.T) {
+	exporter := tracingtest.Record(t)
+	usecase := NewTracedUserUsecase(NewUserUsecase(repository.NewUserMemoryRepository()))
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "GET /users/{id}")
+	created, err := usecase.CreateUser(ctx, dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+	assert.NoError(t, err)
+	missing := uuid.New()
+	_, err = usecase.GetUser(ctx, missing)
+	assert.True(t, entities.IsNotFoundError(err))
+	parent.End()
+
+	spans := exporter.GetSpans()
+	assert.Len(t, spans, 3)
+
+	create := tracingtest.Find(spans, "UserUsecase.CreateUser")
+	if assert.NotNil(t, create) {
+		assert.Equal(t, parent.SpanContext().SpanID(), create.Parent.SpanID())
+		assert.Equal(t, codes.Unset, create.Status.Code)
+		attrs := tracingtest.Attributes(create)
+		assert.Equal(t, created.ID.String(), attrs["user.id"])
+		assert.NotContains(t, attrs, "error.type")
+	}
+
+	get := tracingtest.Find(spans, "UserUsecase.GetUser")
+	if assert.NotNil(t, get) {
+		assert.Equal(t, parent.SpanContext().TraceID(), get.SpanContext.TraceID())
+		assert.Equal(t, codes.Error, get.Status.Code)
+		attrs := tracingtest.Attributes(get)
+		assert.Equal(t, missing.String(), attrs["user.id"])
+		assert.Equal(t, string(entities.NotFoundError), attrs["error.type"])
+	}
+}
@@ -3,6 +3,9 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"go-clean-code/internal/dto"
 	"go-clean-code/internal/entities"
@@ -23,130 +26,396 @@ type UserUsecaseInterface interface {
 	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error)
 	GetUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error)
 	UpdateUser(ctx context.Context, id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error)
-	DeleteUser(ctx context.Context, id uuid.UUID) error
-	ListUsers(ctx context.Context, limit, offset int) (*dto.ListUsersResponse, error)
+	PatchUser(ctx context.Context, id uuid.UUID, req dto.PatchUserRequest) (*dto.UserResponse, error)
+	// DeleteUser removes the user; a non-zero expectedVersion must be current
+	DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error
+	RestoreUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error)
+	// PurgeUser permanently removes a user that has already been deleted
+	PurgeUser(ctx context.Context, id uuid.UUID) error
+	ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error)
+	SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.SearchUsersResponse, error)
+	// ExportUsers streams the users matching req to emit one at a time
+	ExportUsers(ctx context.Context, req dto.ExportUsersRequest, emit func(*dto.ExportedUser) error) error
+	// ImportUsers creates many users at once and reports the outcome per row
+	ImportUsers(ctx context.Context, req dto.ImportUsersRequest) (*dto.ImportUsersResponse, error)
+	// ListAudit returns the recorded changes to users, newest first
+	ListAudit(ctx context.Context, req dto.ListAuditRequest) (*dto.ListAuditResponse, error)
+	// ChangeUserRole gives the user a new role
+	ChangeUserRole(ctx context.Context, id uuid.UUID, req dto.ChangeUserRoleRequest) (*dto.UserResponse, error)
+	// ChangePassword sets the user's password
+	ChangePassword(ctx context.Context, id uuid.UUID, req dto.ChangePasswordRequest) (*dto.UserResponse, error)
 }
 
+// Pagination defaults for ListUsers
+const (
+	DefaultListLimit = 10
+	DefaultMaxLimit  = 100
+)
+
+// MaxSearchQueryLength bounds the free-text query accepted by SearchUsers
+const MaxSearchQueryLength = 200
+
 type UserUsecase struct {
-	userRepo repository.UserRepositoryInterface
+	userRepo      repository.UserRepositoryInterface
+	txManager     repository.TransactionManagerInterface
+	maxLimit      int
+	maxImportRows int
 }
 
-func NewUserUsecase(userRepo repository.UserRepositoryInterface) *UserUsecase {
-	return &UserUsecase{
-		userRepo: userRepo,
+// UserUsecaseOption configures optional UserUsecase behaviour
+type UserUsecaseOption func(*UserUsecase)
+
+// WithMaxListLimit sets the largest page size ListUsers accepts
+func WithMaxListLimit(maxLimit int) UserUsecaseOption {
+	return func(u *UserUsecase) {
+		if maxLimit > 0 {
+			u.maxLimit = maxLimit
+		}
+	}
+}
+
+// WithMaxImportRows sets the most rows ImportUsers accepts
+func WithMaxImportRows(maxRows int) UserUsecaseOption {
+	return func(u *UserUsecase) {
+		if maxRows > 0 {
+			u.maxImportRows = maxRows
+		}
 	}
 }
 
+// WithTransactionManager makes multi-step operations such as the email
+// uniqueness check and the write that follows it, or an all-or-nothing
+// import, run as one unit of work
+func WithTransactionManager(txManager repository.TransactionManagerInterface) UserUsecaseOption {
+	return func(u *UserUsecase) {
+		if txManager != nil {
+			u.txManager = txManager
+		}
+	}
+}
+
+// noTransaction runs units of work directly against the repository
+type noTransaction struct{}
+
+func (noTransaction) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func NewUserUsecase(userRepo repository.UserRepositoryInterface, opts ...UserUsecaseOption) *UserUsecase {
+	u := &UserUsecase{
+		userRepo:      userRepo,
+		txManager:     noTransaction{},
+		maxLimit:      DefaultMaxLimit,
+		maxImportRows: DefaultMaxImportRows,
+	}
+	for _, opt := range opts {
+		opt(u)
+	}
+	return u
+}
+
 func (u *UserUsecase) CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error) {
 	// Use domain entity to create user with validation
 	user, err := entities.NewUser(req.Name, req.Email)
 	if err != nil {
 		return nil, entities.NewValidationError("invalid user input", err)
 	}
-
-	// Check if email already exists
-	existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
-	if err != nil && !entities.IsNotFoundError(err) {
-		return nil, err
-	}
-	if existingUser != nil {
-		return nil, entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+	if req.Password != "" {
+		if err := user.SetPassword(req.Password); err != nil {
+			if errors.Is(err, entities.ErrInvalidPassword) {
+				return nil, entities.NewValidationError("invalid password", err)
+			}
+			return nil, entities.NewInternalError("failed to hash password", err)
+		}
 	}
 
-	if err := u.userRepo.Create(ctx, user); err != nil {
+	err = u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// Check if email already exists
+		existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
+		if err != nil && !entities.IsNotFoundError(err) {
+			return err
+		}
+		if existingUser != nil {
+			return entities.NewConflictError("email already in use", entities.ErrEmailAlreadyUsed)
+		}
+
+		return u.userRepo.Create(ctx, user)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return newUserResponse(user), nil
 }
 
 func (u *UserUsecase) GetUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionReadUser, id); err != nil {
+		return nil, err
+	}
 	user, err := u.userRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+	return newUserResponse(user), nil
 }
 
 func (u *UserUsecase) UpdateUser(ctx context.Context, id uuid.UUID, req dto.UpdateUserRequest) (*dto.UserResponse, error) {
-	user, err := u.userRepo.GetByID(ctx, id)
-	if err != nil {
+	if err := u.authorize(ctx, entities.PermissionUpdateUser, id); err != nil {
 		return nil, err
 	}
+	var user *entities.User
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(user, req.ExpectedVersion); err != nil {
+			return err
+		}
 
-	// Use domain entity methods for validation and updates
-	if req.Name != "" {
-		if err := user.UpdateName(req.Name); err != nil {
-			return nil, entities.NewValidationError("invalid name", err)
+		// Use domain entity methods for validation and updates
+		if req.Name != "" {
+			if err := user.UpdateName(req.Name); err != nil {
+				return entities.NewValidationError("invalid name", err)
+			}
 		}
+
+		if req.Email != "" {
+			if err := u.changeEmail(ctx, user, req.Email); err != nil {
+				return err
+			}
+		}
+
+		return u.userRepo.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if req.Email != "" {
-		// Check if email already exists for another user
-		existingUser, err := u.userRepo.GetByEmail(ctx, req.Email)
-		if err != nil && !entities.IsNotFoundError(err) {
-			return nil, err
+	return newUserResponse(user), nil
+}
+
+// PatchUser applies a merge patch or JSON Patch to the user. Removing or
+// nulling name or email is treated as clearing it, which the entity rejects.
+func (u *UserUsecase) PatchUser(ctx context.Context, id uuid.UUID, req dto.PatchUserRequest) (*dto.UserResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionUpdateUser, id); err != nil {
+		return nil, err
+	}
+	var user *entities.User
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.userRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(user, req.ExpectedVersion); err != nil {
+			return err
+		}
+
+		doc := newUserDocument(user)
+		if err := applyUserPatch(doc, req); err != nil {
+			return err
+		}
+
+		name, err := doc.stringField("name")
+		if err != nil {
+			return err
+		}
+		email, err := doc.stringField("email")
+		if err != nil {
+			return err
 		}
-		if existingUser != nil && existingUser.ID != id {
-			return nil, entities.NewConflictError("email already in use by another user", entities.ErrEmailAlreadyUsed)
+
+		if name == user.Name && email == user.Email {
+			return nil
 		}
 
-		if err := user.UpdateEmail(req.Email); err != nil {
-			return nil, entities.NewValidationError("invalid email", err)
+		if name != user.Name {
+			if err := user.UpdateName(name); err != nil {
+				return entities.NewValidationError("invalid name", err)
+			}
+		}
+		if email != user.Email {
+			if err := u.changeEmail(ctx, user, email); err != nil {
+				return err
+			}
 		}
+
+		return u.userRepo.Update(ctx, user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := u.userRepo.Update(ctx, user); err != nil {
+	return newUserResponse(user), nil
+}
+
+// changeEmail updates the user's email once no other user holds it
+func (u *UserUsecase) changeEmail(ctx context.Context, user *entities.User, email string) error {
+	existingUser, err := u.userRepo.GetByEmail(ctx, email)
+	if err != nil && !entities.IsNotFoundError(err) {
+		return err
+	}
+	if existingUser != nil && existingUser.ID != user.ID {
+		return entities.NewConflictError("email already in use by another user", entities.ErrEmailAlreadyUsed)
+	}
+
+	if err := user.UpdateEmail(email); err != nil {
+		return entities.NewValidationError("invalid email", err)
+	}
+	return nil
+}
+
+func (u *UserUsecase) DeleteUser(ctx context.Context, id uuid.UUID, expectedVersion int64) error {
+	if err := u.authorize(ctx, entities.PermissionDeleteUser, id); err != nil {
+		return err
+	}
+	return u.userRepo.Delete(ctx, id, expectedVersion)
+}
+
+func (u *UserUsecase) RestoreUser(ctx context.Context, id uuid.UUID) (*dto.UserResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionDeleteUser, id); err != nil {
 		return nil, err
 	}
+	var user *entities.User
+	err := u.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := u.userRepo.Restore(ctx, id); err != nil {
+			return err
+		}
 
-	return &dto.UserResponse{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-	}, nil
+		var err error
+		user, err = u.userRepo.GetByID(ctx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (u *UserUsecase) PurgeUser(ctx context.Context, id uuid.UUID) error {
+	if err := u.authorize(ctx, entities.PermissionDeleteUser, id); err != nil {
+		return err
+	}
+	return u.userRepo.Purge(ctx, id)
+}
+
+// checkVersion fails fast when the caller edited an older version of user.
+// The repository re-checks the version atomically when writing.
+func checkVersion(user *entities.User, expectedVersion int64) error {
+	if expectedVersion != 0 && user.Version != expectedVersion {
+		return entities.NewPreconditionFailedError("user version does not match", entities.ErrVersionMismatch)
+	}
+	return nil
 }
 
-func (u *UserUsecase) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	return u.userRepo.Delete(ctx, id)
+func newUserResponse(user *entities.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID:      user.ID,
+		Name:    user.Name,
+		Email:   user.Email,
+		Role:    string(user.Role),
+		Version: user.Version,
+	}
 }
 
-func (u *UserUsecase) ListUsers(ctx context.Context, limit, offset int) (*dto.ListUsersResponse, error) {
-	if limit <= 0 {
-		limit = 10
+func (u *UserUsecase) ListUsers(ctx context.Context, req dto.ListUsersRequest) (*dto.ListUsersResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionListUsers, uuid.Nil); err != nil {
+		return nil, err
+	}
+	limit, offset, err := u.pageBounds(req.Limit, req.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseUserFilter(req)
+	if err != nil {
+		return nil, err
+	}
+	userSort, err := parseUserSort(req.Sort)
+	if err != nil {
+		return nil, err
 	}
-	if offset < 0 {
-		offset = 0
+
+	params := repository.ListParams{Limit: limit + 1, Offset: offset, Filter: filter, Sort: userSort}
+	if req.Cursor != "" {
+		if offset > 0 {
+			return nil, entities.NewValidationError("cursor and offset cannot be combined", entities.ErrInvalidPagination)
+		}
+		if userSort != repository.DefaultUserSort {
+			return nil, entities.NewValidationError("cursor pagination only supports sort=-created_at", entities.ErrInvalidPagination)
+		}
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		params.Cursor = cursor
 	}
 
-	users, err := u.userRepo.List(ctx, limit, offset)
+	// Fetch one extra row to learn whether another page follows
+	users, total, err := u.userRepo.List(ctx, params)
 	if err != nil {
 		return nil, err
 	}
 
+	// Cursors encode the default ordering, so other sorts page by offset only
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		if userSort == repository.DefaultUserSort {
+			nextCursor = encodeCursor(users[len(users)-1])
+		}
+	}
+
 	userResponses := make([]*dto.UserResponse, len(users))
 	for i, user := range users {
-		userResponses[i] = &dto.UserResponse{
-			ID:    user.ID,
-			Name:  user.Name,
-			Email: user.Email,
-		}
+		userResponses[i] = newUserResponse(user)
 	}
 
 	return &dto.ListUsersResponse{
-		Users:  userResponses,
-		Total:  len(userResponses),
-		Limit:  limit,
-		Offset: offset,
+		Users:      userResponses,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+		NextCursor: nextCursor,
 	}, nil
+}
+
+func (u *UserUsecase) SearchUsers(ctx context.Context, req dto.SearchUsersRequest) (*dto.SearchUsersResponse, error) {
+	if err := u.authorize(ctx, entities.PermissionListUsers, uuid.Nil); err != nil {
+		return nil, err
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return nil, entities.NewValidationError("search query must not be empty", entities.ErrInvalidSearch)
+	}
+	if utf8.RuneCountInString(query) > MaxSearchQueryLength {
+		return nil, entities.NewValidationError(fmt.Sprintf("search query must not exceed %d characters", MaxSearchQueryLength), entities.ErrInvalidSearch)
+	}
+
+	limit, _, err := u.pageBounds(req.Limit, 0)
+	if err != nil {
+		return nil, err
+	}
 
+	results, err := u.userRepo.Search(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*dto.UserSearchHit, len(results))
+	for i, result := range results {
+		hits[i] = &dto.UserSearchHit{
+			User:  newUserResponse(result.User),
+			Score: result.Score,
+		}
+	}
+
+	return &dto.SearchUsersResponse{
+		Query: query,
+		Hits:  hits,
+	}, nil
 }
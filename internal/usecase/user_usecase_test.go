@@ -2,11 +2,14 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
 	"go-clean-code/internal/dto"
 	"go-clean-code/internal/entities"
+	"go-clean-code/internal/repository"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -23,6 +26,19 @@ func (m *MockUserRepository) Create(ctx context.Context, user *entities.User) er
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*entities.User) ([]uuid.UUID, error) {
+	args := m.Called(ctx, users)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockUserRepository) Export(ctx context.Context, filter repository.UserFilter, sort repository.UserSort) repository.UserIterator {
+	args := m.Called(ctx, filter, sort)
+	return args.Get(0).(repository.UserIterator)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*entities.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -44,14 +60,40 @@ func (m *MockUserRepository) Update(ctx context.Context, user *entities.User) er
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockUserRepository) Delete(ctx context.Context, id uuid.UUID, version int64) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*entities.User, error) {
-	args := m.Called(ctx, limit, offset)
-	return args.Get(0).([]*entities.User), args.Error(1)
+func (m *MockUserRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, params repository.ListParams) ([]*entities.User, int, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]*entities.User), args.Int(1), args.Error(2)
+}
+
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit int) ([]*repository.UserSearchResult, error) {
+	args := m.Called(ctx, query, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*repository.UserSearchResult), args.Error(1)
+}
+
+func (m *MockUserRepository) ListAudit(ctx context.Context, params repository.AuditListParams) ([]*entities.AuditRecord, int, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Int(1), args.Error(2)
+	}
+	return args.Get(0).([]*entities.AuditRecord), args.Int(1), args.Error(2)
 }
 
 func TestUserUsecase_CreateUser(t *testing.T) {
@@ -119,6 +161,82 @@ func TestUserUsecase_CreateUser(t *testing.T) {
 	})
 }
 
+type txContextKey struct{}
+
+// fakeTxManager marks the context it hands to fn and can run fn twice to
+// simulate a retry after a serialization failure
+type fakeTxManager struct {
+	runs  int
+	retry bool
+}
+
+func (m *fakeTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	txCtx := context.WithValue(ctx, txContextKey{}, true)
+	m.runs++
+	if m.retry {
+		m.runs++
+		_ = fn(txCtx)
+	}
+	return fn(txCtx)
+}
+
+func inTx(ctx context.Context) bool {
+	return ctx.Value(txContextKey{}) != nil
+}
+
+func TestUserUsecase_Transactions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("should check email and create in one transaction", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		txManager := &fakeTxManager{}
+		usecase := NewUserUsecase(mockRepo, WithTransactionManager(txManager))
+
+		mockRepo.On("GetByEmail", mock.MatchedBy(inTx), "john@example.com").
+			Return((*entities.User)(nil), entities.NewNotFoundError("user not found by email", entities.ErrUserNotFound))
+		mockRepo.On("Create", mock.MatchedBy(inTx), mock.AnythingOfType("*entities.User")).Return(nil)
+
+		_, err := usecase.CreateUser(ctx, dto.CreateUserRequest{Name: "John Doe", Email: "john@example.com"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, txManager.runs)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reread the user when the transaction is retried", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		txManager := &fakeTxManager{retry: true}
+		usecase := NewUserUsecase(mockRepo, WithTransactionManager(txManager))
+
+		// Each attempt gets a fresh copy, as it would from the database
+		for i := 0; i < 2; i++ {
+			mockRepo.On("GetByID", mock.MatchedBy(inTx), userID).
+				Return(&entities.User{ID: userID, Name: "John Doe", Email: "john@example.com", Version: 1}, nil).Once()
+		}
+		mockRepo.On("Update", mock.MatchedBy(inTx), mock.AnythingOfType("*entities.User")).Return(nil).Twice()
+
+		result, err := usecase.UpdateUser(ctx, userID, dto.UpdateUserRequest{Name: "John Smith", ExpectedVersion: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "John Smith", result.Name)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should restore and read back in one transaction", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo, WithTransactionManager(&fakeTxManager{}))
+
+		mockRepo.On("Restore", mock.MatchedBy(inTx), userID).Return(nil)
+		mockRepo.On("GetByID", mock.MatchedBy(inTx), userID).Return(&entities.User{ID: userID, Name: "John Doe", Email: "john@example.com"}, nil)
+
+		_, err := usecase.RestoreUser(ctx, userID)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
 func TestUserUsecase_GetUser(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
@@ -253,6 +371,172 @@ func TestUserUsecase_UpdateUser(t *testing.T) {
 	})
 }
 
+func TestUserUsecase_VersionPreconditions(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	existingUser := &entities.User{ID: userID, Name: "John Doe", Email: "john@example.com", Version: 4}
+
+	t.Run("should reject update of stale version", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(existingUser, nil)
+
+		result, err := usecase.UpdateUser(ctx, userID, dto.UpdateUserRequest{Name: "John Smith", ExpectedVersion: 3})
+
+		assert.True(t, entities.IsPreconditionFailedError(err))
+		assert.ErrorIs(t, err, entities.ErrVersionMismatch)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject patch of stale version", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(existingUser, nil)
+
+		result, err := usecase.PatchUser(ctx, userID, dto.PatchUserRequest{
+			MergePatch:      map[string]json.RawMessage{"name": json.RawMessage(`"John Smith"`)},
+			ExpectedVersion: 5,
+		})
+
+		assert.True(t, entities.IsPreconditionFailedError(err))
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should pass expected version to delete", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("Delete", ctx, userID, int64(4)).Return(nil)
+
+		assert.NoError(t, usecase.DeleteUser(ctx, userID, 4))
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestUserUsecase_PatchUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	newExistingUser := func() *entities.User {
+		return &entities.User{
+			ID:        userID,
+			Name:      "John Doe",
+			Email:     "john@example.com",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+	}
+
+	t.Run("should apply merge patch", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(newExistingUser(), nil)
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+
+		result, err := usecase.PatchUser(ctx, userID, dto.PatchUserRequest{
+			MergePatch: map[string]json.RawMessage{"name": json.RawMessage(`"John Smith"`)},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "John Smith", result.Name)
+		assert.Equal(t, "john@example.com", result.Email)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should apply JSON Patch operations in order", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(newExistingUser(), nil)
+		mockRepo.On("GetByEmail", ctx, "john.smith@example.com").Return((*entities.User)(nil), entities.NewNotFoundError("user not found by email", entities.ErrUserNotFound))
+		mockRepo.On("Update", ctx, mock.AnythingOfType("*entities.User")).Return(nil)
+
+		result, err := usecase.PatchUser(ctx, userID, dto.PatchUserRequest{Operations: []dto.PatchOperation{
+			{Op: "test", Path: "/email", Value: json.RawMessage(`"john@example.com"`)},
+			{Op: "replace", Path: "/email", Value: json.RawMessage(`"john.smith@example.com"`)},
+			{Op: "replace", Path: "/name", Value: json.RawMessage(`"John Smith"`)},
+		}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "John Smith", result.Name)
+		assert.Equal(t, "john.smith@example.com", result.Email)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should not update when patch changes nothing", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(newExistingUser(), nil)
+
+		result, err := usecase.PatchUser(ctx, userID, dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "John Doe", result.Name)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should reject invalid patches", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			req      dto.PatchUserRequest
+			sentinel error
+		}{
+			{"merge patch touching id", dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"id": json.RawMessage(`"x"`)}}, entities.ErrImmutableField},
+			{"merge patch with unknown field", dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"nickname": json.RawMessage(`"johnny"`)}}, entities.ErrInvalidPatch},
+			{"merge patch changing role", dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"role": json.RawMessage(`"admin"`)}}, entities.ErrImmutableField},
+			{"merge patch clearing name", dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"name": json.RawMessage(`null`)}}, entities.ErrInvalidName},
+			{"merge patch with non-string name", dto.PatchUserRequest{MergePatch: map[string]json.RawMessage{"name": json.RawMessage(`42`)}}, entities.ErrInvalidPatch},
+			{"replace created_at", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "replace", Path: "/created_at", Value: json.RawMessage(`"2024-01-01T00:00:00Z"`)}}}, entities.ErrImmutableField},
+			{"move id into name", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "move", From: "/id", Path: "/name"}}}, entities.ErrImmutableField},
+			{"remove email", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "remove", Path: "/email"}}}, entities.ErrInvalidEmail},
+			{"nested path", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "add", Path: "/name/first", Value: json.RawMessage(`"John"`)}}}, entities.ErrInvalidPatch},
+			{"missing value", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "replace", Path: "/name"}}}, entities.ErrInvalidPatch},
+			{"unsupported op", dto.PatchUserRequest{Operations: []dto.PatchOperation{{Op: "merge", Path: "/name"}}}, entities.ErrInvalidPatch},
+			{"empty request", dto.PatchUserRequest{}, entities.ErrInvalidPatch},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockUserRepository)
+				usecase := NewUserUsecase(mockRepo)
+
+				mockRepo.On("GetByID", ctx, userID).Return(newExistingUser(), nil)
+				mockRepo.On("GetByEmail", ctx, "").Return((*entities.User)(nil), entities.NewNotFoundError("user not found by email", entities.ErrUserNotFound)).Maybe()
+
+				result, err := usecase.PatchUser(ctx, userID, tt.req)
+
+				assert.True(t, entities.IsValidationError(err))
+				assert.ErrorIs(t, err, tt.sentinel)
+				assert.Nil(t, result)
+				mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("should return conflict when test operation fails", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("GetByID", ctx, userID).Return(newExistingUser(), nil)
+
+		result, err := usecase.PatchUser(ctx, userID, dto.PatchUserRequest{Operations: []dto.PatchOperation{
+			{Op: "test", Path: "/name", Value: json.RawMessage(`"Someone Else"`)},
+			{Op: "replace", Path: "/name", Value: json.RawMessage(`"John Smith"`)},
+		}})
+
+		assert.True(t, entities.IsConflictError(err))
+		assert.ErrorIs(t, err, entities.ErrPatchTestFailed)
+		assert.Contains(t, err.Error(), "operation 0")
+		assert.Nil(t, result)
+	})
+}
+
 func TestUserUsecase_DeleteUser(t *testing.T) {
 	ctx := context.Background()
 	userID := uuid.New()
@@ -261,9 +545,9 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		usecase := NewUserUsecase(mockRepo)
 
-		mockRepo.On("Delete", ctx, userID).Return(nil)
+		mockRepo.On("Delete", ctx, userID, int64(0)).Return(nil)
 
-		err := usecase.DeleteUser(ctx, userID)
+		err := usecase.DeleteUser(ctx, userID, 0)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -273,28 +557,81 @@ func TestUserUsecase_DeleteUser(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		usecase := NewUserUsecase(mockRepo)
 
-		mockRepo.On("Delete", ctx, userID).Return(entities.ErrUserNotFound)
+		mockRepo.On("Delete", ctx, userID, int64(0)).Return(entities.ErrUserNotFound)
 
-		err := usecase.DeleteUser(ctx, userID)
+		err := usecase.DeleteUser(ctx, userID, 0)
 
 		assert.Equal(t, entities.ErrUserNotFound, err)
 		mockRepo.AssertExpectations(t)
 	})
 }
 
+func TestUserUsecase_RestoreUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("should restore and return user", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		restored := &entities.User{ID: userID, Name: "John Doe", Email: "john@example.com", Version: 3}
+		mockRepo.On("Restore", ctx, userID).Return(nil)
+		mockRepo.On("GetByID", ctx, userID).Return(restored, nil)
+
+		result, err := usecase.RestoreUser(ctx, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, userID, result.ID)
+		assert.Equal(t, int64(3), result.Version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should return conflict when email was reused", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("Restore", ctx, userID).
+			Return(entities.NewConflictError("cannot restore user: its email is now used by another user", entities.ErrEmailAlreadyUsed))
+
+		result, err := usecase.RestoreUser(ctx, userID)
+
+		assert.True(t, entities.IsConflictError(err))
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+}
+
+func TestUserUsecase_PurgeUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	mockRepo := new(MockUserRepository)
+	usecase := NewUserUsecase(mockRepo)
+
+	mockRepo.On("Purge", ctx, userID).Return(entities.NewConflictError("user must be deleted before it can be purged", entities.ErrUserNotDeleted))
+
+	err := usecase.PurgeUser(ctx, userID)
+
+	assert.ErrorIs(t, err, entities.ErrUserNotDeleted)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestUserUsecase_ListUsers(t *testing.T) {
 	ctx := context.Background()
 
+	now := time.Now()
 	users := []*entities.User{
 		{
-			ID:    uuid.New(),
-			Name:  "John Doe",
-			Email: "john@example.com",
+			ID:        uuid.New(),
+			Name:      "John Doe",
+			Email:     "john@example.com",
+			CreatedAt: now,
 		},
 		{
-			ID:    uuid.New(),
-			Name:  "Jane Smith",
-			Email: "jane@example.com",
+			ID:        uuid.New(),
+			Name:      "Jane Smith",
+			Email:     "jane@example.com",
+			CreatedAt: now.Add(-time.Minute),
 		},
 	}
 
@@ -304,9 +641,9 @@ func TestUserUsecase_ListUsers(t *testing.T) {
 
 		limit, offset := 10, 0
 
-		mockRepo.On("List", ctx, limit, offset).Return(users, nil)
+		mockRepo.On("List", ctx, repository.ListParams{Limit: limit + 1, Offset: offset, Sort: repository.DefaultUserSort}).Return(users, 2, nil)
 
-		result, err := usecase.ListUsers(ctx, limit, offset)
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Limit: limit, Offset: offset})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, result)
@@ -314,20 +651,292 @@ func TestUserUsecase_ListUsers(t *testing.T) {
 		assert.Equal(t, 2, result.Total)
 		assert.Equal(t, limit, result.Limit)
 		assert.Equal(t, offset, result.Offset)
+		assert.Empty(t, result.NextCursor)
 		mockRepo.AssertExpectations(t)
 	})
 
-	t.Run("should use default values for invalid pagination", func(t *testing.T) {
+	t.Run("should use the default limit when none is given", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
 		usecase := NewUserUsecase(mockRepo)
 
-		mockRepo.On("List", ctx, 10, 0).Return([]*entities.User{}, nil)
+		mockRepo.On("List", ctx, repository.ListParams{Limit: 11, Offset: 0, Sort: repository.DefaultUserSort}).Return([]*entities.User{}, 0, nil)
 
-		result, err := usecase.ListUsers(ctx, 0, -1)
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, 10, result.Limit)
 		assert.Equal(t, 0, result.Offset)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("should reject negative pagination", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		for _, req := range []dto.ListUsersRequest{{Limit: -5}, {Offset: -1}} {
+			result, err := usecase.ListUsers(ctx, req)
+
+			assert.Nil(t, result)
+			assert.True(t, entities.IsValidationError(err))
+			assert.ErrorIs(t, err, entities.ErrInvalidPagination)
+		}
+		mockRepo.AssertNotCalled(t, "List")
+	})
+
+	t.Run("should return total of all users rather than page size", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("List", ctx, repository.ListParams{Limit: 3, Offset: 0, Sort: repository.DefaultUserSort}).Return(users, 42, nil)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Limit: 2})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 2)
+		assert.Equal(t, 42, result.Total)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject limit above configured maximum", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo, WithMaxListLimit(50))
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Limit: 51})
+
+		assert.True(t, entities.IsValidationError(err))
+		assert.ErrorIs(t, err, entities.ErrInvalidPagination)
+		assert.Nil(t, result)
+		mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return next cursor when more rows follow", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		mockRepo.On("List", ctx, repository.ListParams{Limit: 2, Offset: 0, Sort: repository.DefaultUserSort}).Return(users, 5, nil)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Limit: 1})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 1)
+		assert.NotEmpty(t, result.NextCursor)
+
+		cursor, err := decodeCursor(result.NextCursor)
+		assert.NoError(t, err)
+		assert.Equal(t, users[0].ID, cursor.ID)
+		assert.True(t, users[0].CreatedAt.Equal(cursor.CreatedAt))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should seek from decoded cursor", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		token := encodeCursor(users[0])
+		mockRepo.On("List", ctx, mock.MatchedBy(func(params repository.ListParams) bool {
+			return params.Limit == 11 && params.Cursor != nil && params.Cursor.ID == users[0].ID
+		})).Return(users[1:], 2, nil)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Cursor: token})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 1)
+		assert.Empty(t, result.NextCursor)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject malformed cursor", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Cursor: "not-a-cursor"})
+
+		assert.True(t, entities.IsValidationError(err))
+		assert.ErrorIs(t, err, entities.ErrInvalidCursor)
+		assert.Nil(t, result)
+	})
+
+	t.Run("should reject cursor combined with offset", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{Offset: 10, Cursor: encodeCursor(users[0])})
+
+		assert.True(t, entities.IsValidationError(err))
+		assert.Nil(t, result)
+	})
+	t.Run("should pass typed filter and sort to repository", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		expected := repository.ListParams{
+			Limit: 11,
+			Filter: repository.UserFilter{
+				NameContains: "doe",
+				EmailDomain:  "example.com",
+				CreatedAfter: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			Sort: repository.UserSort{Field: repository.SortByName},
+		}
+		mockRepo.On("List", ctx, expected).Return(users, 2, nil)
+
+		result, err := usecase.ListUsers(ctx, dto.ListUsersRequest{
+			Name:         "doe",
+			EmailDomain:  "@example.com",
+			CreatedAfter: "2024-01-01T00:00:00Z",
+			Sort:         "name",
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, result.Users, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid filter and sort parameters", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			req      dto.ListUsersRequest
+			sentinel error
+		}{
+			{"malformed timestamp", dto.ListUsersRequest{CreatedAfter: "yesterday"}, entities.ErrInvalidFilter},
+			{"inverted created range", dto.ListUsersRequest{CreatedAfter: "2024-02-01T00:00:00Z", CreatedBefore: "2024-01-01T00:00:00Z"}, entities.ErrInvalidFilter},
+			{"unknown sort field", dto.ListUsersRequest{Sort: "password"}, entities.ErrInvalidSort},
+			{"cursor with non-default sort", dto.ListUsersRequest{Sort: "name", Cursor: encodeCursor(users[0])}, entities.ErrInvalidPagination},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockUserRepository)
+				usecase := NewUserUsecase(mockRepo)
+
+				result, err := usecase.ListUsers(ctx, tt.req)
+
+				assert.True(t, entities.IsValidationError(err))
+				assert.ErrorIs(t, err, tt.sentinel)
+				assert.Nil(t, result)
+			})
+		}
+	})
+}
+
+func TestUserUsecase_ListAudit(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("should pass filter and map records", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		after := &entities.User{ID: userID, Name: "John Doe", Email: "john@example.com", Version: 1}
+		records := []*entities.AuditRecord{
+			{ID: uuid.New(), UserID: userID, Action: entities.AuditActionCreate, Actor: "admin", RequestID: "req-1", After: after},
+		}
+		mockRepo.On("ListAudit", ctx, repository.AuditListParams{
+			Limit: DefaultListLimit,
+			Filter: repository.AuditFilter{
+				UserID: userID,
+				Actor:  "admin",
+				Action: entities.AuditActionCreate,
+				From:   from,
+			},
+		}).Return(records, 1, nil)
+
+		result, err := usecase.ListAudit(ctx, dto.ListAuditRequest{
+			UserID: userID,
+			Actor:  "admin",
+			Action: "create",
+			From:   "2024-01-01T00:00:00Z",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.Total)
+		assert.Len(t, result.Records, 1)
+		assert.Equal(t, "create", result.Records[0].Action)
+		assert.Equal(t, "req-1", result.Records[0].RequestID)
+		assert.Nil(t, result.Records[0].Before)
+		assert.Equal(t, after, result.Records[0].After)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid filters", func(t *testing.T) {
+		tests := []struct {
+			name string
+			req  dto.ListAuditRequest
+		}{
+			{"unknown action", dto.ListAuditRequest{Action: "rename"}},
+			{"malformed from", dto.ListAuditRequest{From: "yesterday"}},
+			{"empty range", dto.ListAuditRequest{From: "2024-02-01T00:00:00Z", To: "2024-01-01T00:00:00Z"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockUserRepository)
+				usecase := NewUserUsecase(mockRepo)
+
+				result, err := usecase.ListAudit(ctx, tt.req)
+
+				assert.Nil(t, result)
+				assert.ErrorIs(t, err, entities.ErrInvalidFilter)
+				mockRepo.AssertNotCalled(t, "ListAudit", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("should reject limit above maximum", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo, WithMaxListLimit(20))
+
+		_, err := usecase.ListAudit(ctx, dto.ListAuditRequest{Limit: 21})
+
+		assert.ErrorIs(t, err, entities.ErrInvalidPagination)
+	})
+}
+
+func TestUserUsecase_SearchUsers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should return hits with scores", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		usecase := NewUserUsecase(mockRepo)
+
+		user := &entities.User{ID: uuid.New(), Name: "John Doe", Email: "john@example.com"}
+		mockRepo.On("Search", ctx, "jonh", DefaultListLimit).
+			Return([]*repository.UserSearchResult{{User: user, Score: 0.42}}, nil)
+
+		result, err := usecase.SearchUsers(ctx, dto.SearchUsersRequest{Query: "  jonh "})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "jonh", result.Query)
+		assert.Len(t, result.Hits, 1)
+		assert.Equal(t, user.ID, result.Hits[0].User.ID)
+		assert.Equal(t, 0.42, result.Hits[0].Score)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("should reject invalid search requests", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			req      dto.SearchUsersRequest
+			sentinel error
+		}{
+			{"blank query", dto.SearchUsersRequest{Query: "   "}, entities.ErrInvalidSearch},
+			{"overlong query", dto.SearchUsersRequest{Query: strings.Repeat("a", MaxSearchQueryLength+1)}, entities.ErrInvalidSearch},
+			{"limit above max", dto.SearchUsersRequest{Query: "john", Limit: DefaultMaxLimit + 1}, entities.ErrInvalidPagination},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := new(MockUserRepository)
+				usecase := NewUserUsecase(mockRepo)
+
+				result, err := usecase.SearchUsers(ctx, tt.req)
+
+				assert.True(t, entities.IsValidationError(err))
+				assert.ErrorIs(t, err, tt.sentinel)
+				assert.Nil(t, result)
+				mockRepo.AssertNotCalled(t, "Search")
+			})
+		}
+	})
 }
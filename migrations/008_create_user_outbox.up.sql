@@ -0,0 +1,15 @@
+CREATE TABLE user_outbox (
+    id UUID PRIMARY KEY,
+    event_type VARCHAR(32) NOT NULL,
+    user_id UUID NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP WITH TIME ZONE
+);
+
+-- The relay only ever scans events that still have to be published
+CREATE INDEX idx_user_outbox_pending ON user_outbox(created_at, id) WHERE published_at IS NULL;
+CREATE INDEX idx_user_outbox_pending_user ON user_outbox(user_id, created_at) WHERE published_at IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_created_at_id;
+
+CREATE INDEX idx_users_created_at ON users(created_at);
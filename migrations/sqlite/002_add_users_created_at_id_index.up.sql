@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_created_at;
+
+CREATE INDEX idx_users_created_at_id ON users(created_at DESC, id DESC);
@@ -0,0 +1,14 @@
+-- No foreign key to users: audit history must outlive purged users
+CREATE TABLE user_audit_log (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    before TEXT,
+    after TEXT,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_audit_log_created_at_id ON user_audit_log(created_at DESC, id DESC);
+CREATE INDEX idx_user_audit_log_user_id_created_at ON user_audit_log(user_id, created_at DESC);
@@ -0,0 +1,15 @@
+CREATE TABLE user_outbox (
+    id TEXT PRIMARY KEY,
+    event_type VARCHAR(32) NOT NULL,
+    user_id TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at DATETIME
+);
+
+-- The relay only ever scans events that still have to be published
+CREATE INDEX idx_user_outbox_pending ON user_outbox(created_at, id) WHERE published_at IS NULL;
+CREATE INDEX idx_user_outbox_pending_user ON user_outbox(user_id, created_at) WHERE published_at IS NULL;
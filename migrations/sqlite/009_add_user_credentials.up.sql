@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN password_hash TEXT;
+
+CREATE TABLE refresh_tokens (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    used_at DATETIME,
+    revoked_at DATETIME
+);
+
+CREATE INDEX idx_refresh_tokens_family_id ON refresh_tokens(family_id);
@@ -0,0 +1,2 @@
+-- Lets the relay prune published events without scanning the whole outbox
+CREATE INDEX idx_user_outbox_published ON user_outbox(published_at) WHERE published_at IS NOT NULL;